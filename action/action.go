@@ -37,6 +37,17 @@ type Model interface {
 	SetArgs(*pflag.FlagSet, []string) (invalid string, onStart tea.Cmd, err error)
 }
 
+// Backgrounder is optionally implemented by Models whose runs await a long-running operation
+// (ex: a search), allowing the operation to continue while Mother holds the action in the
+// background. Backgrounded actions are not updated; Mother polls Pending to announce when the
+// operation completes, calls KeepAlive on every poll for as long as the action remains in the
+// background, and calls Resume when the action is returned to the foreground.
+type Backgrounder interface {
+	Pending() bool   // is the operation still in progress? Must not rely on Update being called
+	KeepAlive()      // keep the operation's resources (ex: a displayed search) from aging out
+	Resume() tea.Cmd // restart any commands (ex: spinner ticks) dropped while backgrounded
+}
+
 // Duple used to construct the Action Map.
 // Associates the Action command with its bolted-on Model subroutines to facillitate interactivity.
 type Pair struct {
@@ -9,6 +9,8 @@ consistent access and parameters.
 package connection
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"gwcli/clilog"
@@ -57,12 +59,19 @@ func Initialize(conn string, UseHttps, InsecureNoEnforceCerts bool, restLogPath
 		}
 	}
 
+	// record the outcome of each request (see LastRequest)
+	recordRequest(Request{})
+	rr, err := newRequestRecorder(l)
+	if err != nil {
+		return err
+	}
+
 	if Client, err = grav.NewOpts(
 		grav.Opts{
 			Server:                 conn,
 			UseHttps:               UseHttps,
 			InsecureNoEnforceCerts: InsecureNoEnforceCerts,
-			ObjLogger:              l,
+			ObjLogger:              rr,
 		}); err != nil {
 		return err
 	}
@@ -215,6 +224,31 @@ func CreateToken() error {
 	return nil
 }
 
+// TokenExpiry returns when the client's login token (a JWT) expires.
+func TokenExpiry() (time.Time, error) {
+	token, err := Client.ExportLoginToken()
+	if err != nil {
+		return time.Time{}, err
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, errors.New("malformed login token")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("malformed login token: %w", err)
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}, fmt.Errorf("malformed login token: %w", err)
+	} else if claims.Exp == 0 {
+		return time.Time{}, errors.New("login token does not expire")
+	}
+	return time.Unix(claims.Exp, 0), nil
+}
+
 // Closes the connection to the server.
 // Does not logout the user as to not invalidate existing JWTs.
 func End() error {
@@ -300,21 +334,27 @@ func invalidCronWord(word, entryNumber string, lowBound, highBound int) (invalid
 //
 // Returns a handle to executing searching.
 func StartQuery(qry string, durFromNow time.Duration) (grav.Search, error) {
-	var err error
 	if durFromNow > 0 {
 		return grav.Search{}, fmt.Errorf("duration must be negative or zero (given %v)", durFromNow)
 	}
+	end := time.Now()
+	return StartQueryRange(qry, end.Add(durFromNow), end)
+}
 
+// Validates and submits the given query to the connected server instance, searching over the
+// given time range.
+//
+// Returns a handle to executing searching.
+func StartQueryRange(qry string, start, end time.Time) (grav.Search, error) {
 	// validate search query
-	if err = Client.ParseSearch(qry); err != nil {
+	if err := Client.ParseSearch(qry); err != nil {
 		return grav.Search{}, fmt.Errorf("'%s' is not a valid query: %s", qry, err.Error())
 	}
 
 	// check for scheduling
 
-	end := time.Now()
 	sreq := types.StartSearchRequest{
-		SearchStart:  end.Add(durFromNow).Format(uniques.SearchTimeFormat),
+		SearchStart:  start.Format(uniques.SearchTimeFormat),
 		SearchEnd:    end.Format(uniques.SearchTimeFormat),
 		Background:   false,
 		SearchString: qry, // pull query from the commandline
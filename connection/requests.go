@@ -0,0 +1,133 @@
+package connection
+
+/*
+The outcome of Client's most recent request is recorded, so the health of the connection can be
+reported from the requests gwcli actually makes rather than from probes of its own.
+
+The client library offers no hook into its requests beyond its object logger, so Client's logger
+is wrapped by a requestRecorder that infers each request's outcome from the entries the library
+logs for it. The library logs the start of requests that send a body (POST, PUT, ...), so their
+latency can be measured; other requests only log their completion.
+Entries only identify their request by URL, so concurrent requests to the same URL are assumed to
+complete in the order they were sent.
+
+As this depends on the library's log entries, Test_requestRecorder_client exercises the recorder
+against the library itself, failing should a new version of the library change its entries.
+*/
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gravwell/gravwell/v3/client/objlog"
+)
+
+// Request is the outcome of a request made by Client.
+type Request struct {
+	When    time.Time     // when the request completed
+	Latency time.Duration // zero if it could not be measured
+	Err     error         // nil if the request succeeded
+}
+
+var last struct {
+	mu sync.Mutex
+	r  Request
+}
+
+// LastRequest returns the outcome of the most recent request made by Client, or the zero Request if
+// it has not made any.
+func LastRequest() Request {
+	last.mu.Lock()
+	defer last.mu.Unlock()
+	return last.r
+}
+
+func recordRequest(r Request) {
+	last.mu.Lock()
+	last.r = r
+	last.mu.Unlock()
+}
+
+// the methods that may lead a "<method> <status>" entry
+var httpMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true, http.MethodPut: true,
+	http.MethodPatch: true, http.MethodDelete: true, http.MethodOptions: true,
+}
+
+// requestRecorder wraps an object logger, recording the outcome of each request logged to it.
+type requestRecorder struct {
+	objlog.ObjLog
+
+	mu      sync.Mutex
+	started map[string][]time.Time // url -> when each of its outstanding requests was sent, oldest first
+}
+
+// Returns a recorder wrapping the given logger, which may be nil.
+func newRequestRecorder(l objlog.ObjLog) (*requestRecorder, error) {
+	if l == nil {
+		var err error
+		if l, err = objlog.NewNilLogger(); err != nil {
+			return nil, err
+		}
+	}
+	return &requestRecorder{ObjLog: l, started: make(map[string][]time.Time)}, nil
+}
+
+func (rr *requestRecorder) Log(id, url string, obj interface{}) error {
+	rr.observe(id, url, time.Now())
+	return rr.ObjLog.Log(id, url, obj)
+}
+
+// Records the request the log entry describes, if it describes the start or end of one.
+// The entries logged by the client take the forms:
+//
+//	"WEB REQ <method>", url         (request sent)
+//	"WEB RECV", url                 (success)
+//	"WEB <method>", url             (success)
+//	"WEB <method>", url+" "+status  (failure)
+//	"WEB <method> Error "+err, url  (failure)
+//	"<method> "+status, url         (success or failure, depending on the status)
+//	"<method> "+err, url            (failure)
+//
+// Other entries, such as those the websocket router logs for its subprotocols ("SUBPROTO GET"), are
+// ignored.
+func (rr *requestRecorder) observe(id, url string, now time.Time) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	if strings.HasPrefix(id, "WEB REQ") {
+		rr.started[url] = append(rr.started[url], now)
+		return
+	}
+
+	var err error
+	if rest, ok := strings.CutPrefix(id, "WEB "); ok {
+		if _, e, ok := strings.Cut(rest, " Error "); ok {
+			err = errors.New(e)
+		} else if u, status, ok := strings.Cut(url, " "); ok && rest != "RECV" {
+			url, err = u, errors.New(status)
+		}
+	} else if method, result, ok := strings.Cut(id, " "); ok && httpMethods[method] {
+		code, _, _ := strings.Cut(result, " ")
+		if c, e := strconv.Atoi(code); e != nil || c >= 400 {
+			err = errors.New(result)
+		}
+	} else {
+		return // not a request
+	}
+
+	r := Request{When: now, Err: err}
+	if starts := rr.started[url]; len(starts) > 0 {
+		r.Latency = now.Sub(starts[0])
+		if len(starts) == 1 {
+			delete(rr.started, url)
+		} else {
+			rr.started[url] = starts[1:]
+		}
+	}
+	recordRequest(r)
+}
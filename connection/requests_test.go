@@ -0,0 +1,160 @@
+package connection
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	grav "github.com/gravwell/gravwell/v3/client"
+	"github.com/gravwell/gravwell/v3/client/types"
+)
+
+func Test_requestRecorder(t *testing.T) {
+	start := time.Now()
+	tests := []struct {
+		name        string
+		entries     [][2]string // id, url
+		wantErr     string      // empty if the request succeeded
+		wantLatency bool
+	}{
+		{"push", [][2]string{{"WEB REQ POST", "/api/x"}, {"WEB RECV", "/api/x"}}, "", true},
+		{"push error", [][2]string{{"WEB REQ PUT", "/api/x"}, {"WEB PUT Error connection refused", "/api/x"}},
+			"connection refused", true},
+		{"push status", [][2]string{{"WEB REQ RAWPUT", "/api/x"}, {"WEB PUT", "/api/x 500 Internal Server Error"}},
+			"500 Internal Server Error", true},
+		{"static", [][2]string{{"WEB GET", "http://host/api/x"}}, "", false},
+		{"static status", [][2]string{{"WEB GET", "http://host/api/x 403 Forbidden"}}, "403 Forbidden", false},
+		{"method status", [][2]string{{"GET 200 OK", "/api/x"}}, "", false},
+		{"method error status", [][2]string{{"DELETE 404 Not Found", "/api/x"}}, "404 Not Found", false},
+		{"method error", [][2]string{{"GET dial tcp: timeout", "/api/x"}}, "dial tcp: timeout", false},
+	}
+	ignored := [][2]string{
+		{"SUBPROTO GET", "search"},
+		{"SUBPROTO PUT", "search"},
+		{"SUBPROTO", "search"},
+		{"NOTAMETHOD 200 OK", "/api/x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recordRequest(Request{})
+			rr, err := newRequestRecorder(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, e := range tt.entries {
+				rr.observe(e[0], e[1], start.Add(time.Duration(i)*time.Second))
+			}
+			r := LastRequest()
+			if r.When.IsZero() {
+				t.Fatal("no request was recorded")
+			}
+			if (r.Err == nil) != (tt.wantErr == "") || (r.Err != nil && r.Err.Error() != tt.wantErr) {
+				t.Errorf("error = %v, want %q", r.Err, tt.wantErr)
+			}
+			if (r.Latency == time.Second) != tt.wantLatency {
+				t.Errorf("latency = %v, want measured = %v", r.Latency, tt.wantLatency)
+			}
+			// entries that do not describe a request do not displace it
+			for _, e := range ignored {
+				rr.observe(e[0], e[1], start.Add(time.Hour))
+			}
+			if got := LastRequest(); got != r {
+				t.Errorf("after non-request entries, last request = %+v, want %+v", got, r)
+			}
+		})
+	}
+}
+
+func Test_requestRecorder_concurrent(t *testing.T) {
+	recordRequest(Request{})
+	rr, err := newRequestRecorder(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	// two requests to the same URL are outstanding at once; neither start may be lost
+	rr.observe("WEB REQ POST", "/api/x", start)
+	rr.observe("WEB REQ POST", "/api/x", start.Add(time.Second))
+	rr.observe("WEB RECV", "/api/x", start.Add(3*time.Second))
+	if got := LastRequest().Latency; got != 3*time.Second {
+		t.Errorf("first latency = %v, want %v", got, 3*time.Second)
+	}
+	rr.observe("WEB RECV", "/api/x", start.Add(4*time.Second))
+	if got := LastRequest().Latency; got != 3*time.Second {
+		t.Errorf("second latency = %v, want %v", got, 3*time.Second)
+	}
+	if len(rr.started) != 0 {
+		t.Errorf("starts remain outstanding: %v", rr.started)
+	}
+}
+
+// Test_requestRecorder_client pins the recorder to the log entries of the client library in use;
+// if an update to the library alters its entries, this fails rather than the status line silently
+// losing track of requests.
+func Test_requestRecorder_client(t *testing.T) {
+	var fail atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if r.URL.Path == "/api/parse" {
+			var req types.ParseSearchRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(types.ParseSearchResponse{RawQuery: req.SearchString})
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	rr, err := newRequestRecorder(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := grav.NewOpts(grav.Opts{Server: strings.TrimPrefix(srv.URL, "http://"), ObjLogger: rr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ImportLoginToken("token"); err != nil { // authenticates without a request
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		fail        bool
+		do          func() error
+		wantLatency bool
+	}{
+		{"static", false, c.TestLogin, false},
+		{"static status", true, c.TestLogin, false},
+		{"push", false, func() error { return c.ParseSearch("tag=x") }, true},
+		{"push status", true, func() error { return c.ParseSearch("tag=x") }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recordRequest(Request{})
+			fail.Store(tt.fail)
+			if err := tt.do(); (err != nil) != tt.fail {
+				t.Fatalf("request error = %v, want failure = %v", err, tt.fail)
+			}
+			r := LastRequest()
+			if r.When.IsZero() {
+				t.Fatal("the request was not recorded")
+			}
+			if (r.Err != nil) != tt.fail {
+				t.Errorf("recorded error = %v, want failure = %v", r.Err, tt.fail)
+			}
+			if (r.Latency > 0) != tt.wantLatency {
+				t.Errorf("latency = %v, want measured = %v", r.Latency, tt.wantLatency)
+			}
+		})
+	}
+}
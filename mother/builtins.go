@@ -5,11 +5,17 @@ Builtins are special, meta actions users can invoke from Mother's prompt, no mat
 */
 
 import (
+	"bytes"
+	"fmt"
 	"gwcli/clilog"
 	"gwcli/stylesheet"
+	"gwcli/utilities/alias"
+	"gwcli/utilities/keymap"
+	"os"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/google/shlex"
 )
 
 // invocation string -> function to be invoked
@@ -23,27 +29,94 @@ func initBuiltins() {
 	builtins = map[string](func(*Mother, []string) tea.Cmd){
 		"help":    contextHelp,
 		"history": listHistory,
+		"keys":    listKeys,
+		"alias":   setAlias,
+		"unalias": unsetAlias,
+		"source":  sourceScript,
+		"jobs":    listJobs,
+		"fg":      foregroundJob,
+		"kill":    killJob,
+		"status":  configureStatus,
 		"quit":    quit,
 		"exit":    quit}
 
 	builtinHelp = map[string]string{
-		"help": "Display context-sensitive help. Equivalent to pressing F1.\n" +
+		"help": "Display context-sensitive help. Equivalent to pressing " +
+			keymap.Keys(keys.help) + ".\n" +
 			"Calling " + stylesheet.ExampleStyle.Render("help") + " bare provides currently available navigations.\n" +
 			"Help can also be passed a path to display help on remote directories or actions.\n" +
 			"Ex: " +
 			stylesheet.ExampleStyle.Render("help ~ kits list") +
 			", " +
 			stylesheet.ExampleStyle.Render("help query"),
-		"history": "List previous commands. Navigate history via " + stylesheet.UpDown,
-		"quit":    "Kill the application",
-		"exit":    "Kill the application",
+		"history": "List previous commands. Navigate history via " +
+			keymap.Display(keymap.Keys(keys.historyOlder), keymap.Keys(keys.historyNewer)) +
+			" or search it via " + keymap.Keys(keys.reverseSearch) + ".\n" +
+			"History persists across sessions. Prefix a command with a space to keep it out of " +
+			"history.",
+		"keys": "List every key binding and the action it is bound to.\n" +
+			"Bindings can be overridden by action name in " + keymap.Path() + ".",
+		"alias": "Define or list aliases. Aliases expand to the command line they stand for and are" +
+			" resolved from root, no matter your location.\n" +
+			"Ex: " + stylesheet.ExampleStyle.Render(`alias sq="queries scheduled list --all"`) +
+			", " + stylesheet.ExampleStyle.Render("alias sq") + ", " +
+			stylesheet.ExampleStyle.Render("alias") + "\n" +
+			"Aliases are saved to " + alias.Path() + " and also work outside of interactive mode" +
+			" (ex: " + stylesheet.ExampleStyle.Render("gwcli sq") + ").",
+		"unalias": "Remove the given aliases.\n" +
+			"Ex: " + stylesheet.ExampleStyle.Render("unalias sq"),
+		"source": "Execute a script: a file of commands, one per line, run from your current " +
+			"location.\n" +
+			"Scripts support #-comments, " + stylesheet.ExampleStyle.Render("set VAR=value") +
+			" variables, and ${VAR} interpolation. Execution stops at the first failed command " +
+			"unless --continue-on-error is given.\n" +
+			"Ex: " + stylesheet.ExampleStyle.Render("source setup.gw --continue-on-error") + "\n" +
+			"Scripts can also be run outside of interactive mode via " +
+			stylesheet.ExampleStyle.Render("gwcli run") + ".",
+		"jobs": "List background jobs. Press " + keymap.Keys(keys.background) + " while an action " +
+			"is running to send it to the background.\n" +
+			"Searches continue in the background and are announced when they complete; other " +
+			"actions are suspended until resumed.",
+		"fg": "Resume a background job.\n" +
+			"Ex: " + stylesheet.ExampleStyle.Render("fg 1") + "\n" +
+			"The job number may be omitted if there is only one background job.",
+		"kill": "End a background job.\n" +
+			"Ex: " + stylesheet.ExampleStyle.Render("kill 1") + "\n" +
+			"The job number may be omitted if there is only one background job.",
+		"status": "Show, hide, or configure the status line displayed above the prompt.\n" +
+			stylesheet.ExampleStyle.Render("status on") + " and " +
+			stylesheet.ExampleStyle.Render("status off") + " show and hide it; " +
+			stylesheet.ExampleStyle.Render("status fields <f1,f2,...>") +
+			" selects the fields it displays, in order, from: " + strings.Join(statusFields, ", ") +
+			".\n" +
+			"Calling " + stylesheet.ExampleStyle.Render("status") + " bare displays the current " +
+			"configuration, which persists across sessions.",
+		"quit": "Kill the application",
+		"exit": "Kill the application",
 	}
 }
 
 // Built-in, interactive help invocation
 func contextHelp(m *Mother, args []string) tea.Cmd {
-	if len(args) == 0 {
-		return TeaCmdContextHelp(m.pwd)
+	if len(args) == 0 || (len(args) == 1 && args[0] == "") {
+		if len(m.aliases) == 0 {
+			return TeaCmdContextHelp(m.pwd)
+		}
+		return tea.Sequence(TeaCmdContextHelp(m.pwd),
+			tea.Println("Aliases: "+strings.Join(alias.Names(m.aliases), " ")))
+	}
+
+	// describe the alias, then provide help for what it expands to
+	if expansion, ok := m.aliases[args[0]]; ok {
+		expanded, _ := alias.Expand(m.aliases, strings.Join(args, " "))
+		wr := walk(m.root, strings.Split(expanded, " "))
+		note := tea.Println(fmt.Sprintf("'%v' is an alias for '%v'", args[0], expansion))
+		if wr.errString != "" {
+			return tea.Sequence(note, tea.Println(stylesheet.ErrStyle.Render(wr.errString)))
+		} else if wr.status == foundBuiltin {
+			return note
+		}
+		return tea.Sequence(note, TeaCmdContextHelp(wr.endCommand))
 	}
 
 	// walk the command tree
@@ -88,6 +161,121 @@ func listHistory(m *Mother, _ []string) tea.Cmd {
 	return tea.Println(strings.TrimSpace(toPrint.String()))
 }
 
+// Returns a print tea.Cmd to display each action, its keys, and its description.
+func listKeys(*Mother, []string) tea.Cmd {
+	actions := keymap.Actions()
+	var nameWidth, keysWidth int
+	for _, a := range actions {
+		nameWidth, keysWidth = max(nameWidth, len(a[0])), max(keysWidth, len(a[1]))
+	}
+	toPrint := strings.Builder{}
+	for _, a := range actions {
+		toPrint.WriteString(fmt.Sprintf("%-*s %s %s\n",
+			nameWidth, a[0],
+			stylesheet.ExampleStyle.Render(fmt.Sprintf("%-*s", keysWidth, a[1])),
+			stylesheet.GreyedOutStyle.Render(a[2])))
+	}
+	toPrint.WriteString("Override bindings in " + keymap.Path())
+	return tea.Println(toPrint.String())
+}
+
+// Defines an alias (`alias name=expansion`), displays an alias (`alias name`), or lists all aliases
+// (`alias`).
+func setAlias(m *Mother, args []string) tea.Cmd {
+	def := strings.TrimSpace(strings.Join(args, " "))
+	if def == "" {
+		var sb strings.Builder
+		for _, name := range alias.Names(m.aliases) {
+			sb.WriteString(fmt.Sprintf("%v=%q\n", name, m.aliases[name]))
+		}
+		return tea.Println(strings.TrimSuffix(sb.String(), "\n"))
+	}
+	if !strings.Contains(def, "=") {
+		expansion, ok := m.aliases[def]
+		if !ok {
+			return tea.Println(stylesheet.ErrStyle.Render("no alias named '" + def + "'"))
+		}
+		return tea.Println(fmt.Sprintf("%v=%q", def, expansion))
+	}
+
+	name, expansion, err := alias.Parse(def)
+	if err != nil {
+		return tea.Println(stylesheet.ErrStyle.Render(err.Error()))
+	}
+	if err := alias.Set(name, expansion); err != nil {
+		clilog.Writer.Warnf("failed to save alias %v: %v", name, err)
+		return tea.Println(stylesheet.ErrStyle.Render("failed to save alias: " + err.Error()))
+	}
+	if m.aliases == nil {
+		m.aliases = make(map[string]string)
+	}
+	m.aliases[name] = expansion
+	return m.updateSuggestions()
+}
+
+// Removes each named alias.
+func unsetAlias(m *Mother, args []string) tea.Cmd {
+	var errs []string
+	for _, name := range args {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if found, err := alias.Unset(name); err != nil {
+			clilog.Writer.Warnf("failed to remove alias %v: %v", name, err)
+			errs = append(errs, "failed to remove alias "+name+": "+err.Error())
+		} else if !found {
+			errs = append(errs, "no alias named '"+name+"'")
+		}
+		delete(m.aliases, name)
+	}
+	cmd := m.updateSuggestions()
+	if len(errs) > 0 {
+		return tea.Batch(cmd, tea.Println(stylesheet.ErrStyle.Render(strings.Join(errs, "\n"))))
+	}
+	return cmd
+}
+
+// Executes the given script (see RunScript) from Mother's current location, printing its output
+// once it completes.
+func sourceScript(m *Mother, args []string) tea.Cmd {
+	const usage = "usage: source <file> [--continue-on-error]"
+	tokens, err := shlex.Split(strings.Join(args, " "))
+	if err != nil {
+		return tea.Println(stylesheet.ErrStyle.Render(err.Error()))
+	}
+	var (
+		path string
+		opts ScriptOptions
+	)
+	for _, t := range tokens {
+		if t == "--continue-on-error" {
+			opts.ContinueOnError = true
+		} else if path == "" {
+			path = t
+		} else {
+			return tea.Println(stylesheet.ErrStyle.Render(usage))
+		}
+	}
+	if path == "" {
+		return tea.Println(stylesheet.ErrStyle.Render(usage))
+	}
+
+	pwd := m.pwd
+	return func() tea.Msg {
+		f, err := os.Open(path)
+		if err != nil {
+			return tea.Println(stylesheet.ErrStyle.Render(err.Error()))()
+		}
+		defer f.Close()
+		var out bytes.Buffer // interleave output and statuses
+		opts.Stdout, opts.Stderr = &out, &out
+		if _, err := RunScript(pwd, path, f, opts); err != nil {
+			out.WriteString(stylesheet.ErrStyle.Render("failed to read script: " + err.Error()))
+		}
+		return tea.Println(strings.TrimSuffix(out.String(), "\n"))()
+	}
+}
+
 func quit(*Mother, []string) tea.Cmd {
 	return tea.Sequence(tea.Println("Bye"), tea.Quit)
 }
@@ -0,0 +1,258 @@
+package mother
+
+/*
+Chaining sequences multiple commands on a single line, as a shell would:
+
+	macros create -n ERRORS -e "tag=syslog grep error" && macros list
+	kits list --json > kits.json ; kits
+	queries scheduled list || help queries
+
+`;` always executes the following command, `&&` executes it only if the preceding command
+succeeded, and `||` only if it failed. As in a shell, a skipped command does not alter the status
+tested by the next operator.
+
+Chained commands are executed as lines of a script (see RunScript), starting from Mother's location:
+actions run in script mode, navs move the chain (and Mother, once the chain completes), and each
+command may be an alias or be redirected. Each command's output is preceded by a delimiter naming
+it. `exit` (or `quit`) ends the chain and Mother; other builtins are not supported.
+
+An alias may expand to a chain, whose commands are spliced into the chain in its place. Every
+command of an expansion is rooted, as aliases are. Aliases leading the later commands of an
+expansion are expanded in turn, but are not themselves split into chains.
+
+Operators must be unquoted and whitespace-delimited, like redirection operators. The arguments of
+actions marked with RawArgsAnnotation (such as query text) are taken verbatim, so such an action
+consumes the remainder of the line; chain it by placing it last.
+*/
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"gwcli/clilog"
+	"gwcli/stylesheet"
+	"gwcli/utilities/alias"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/google/shlex"
+	"github.com/spf13/cobra"
+)
+
+type chainOp int
+
+const (
+	chainAlways chainOp = iota // ; (or the first command of the chain)
+	chainAnd                   // &&
+	chainOr                    // ||
+)
+
+func (op chainOp) String() string {
+	switch op {
+	case chainAnd:
+		return "&&"
+	case chainOr:
+		return "||"
+	}
+	return ";"
+}
+
+// link is a single command of a chain.
+type link struct {
+	op      chainOp // the operator preceding the command
+	command string
+}
+
+// Returns whether the command is skipped, given whether the command last executed failed.
+func (l link) skipped(failed bool) bool {
+	return (l.op == chainAnd && failed) || (l.op == chainOr && !failed)
+}
+
+// chainedMsg is returned once a chain completes.
+type chainedMsg struct {
+	pwd    *navCmd // where the chain left Mother
+	output string  // the output of every command, delimited
+	exit   bool    // the chain called exit
+}
+
+// Splits the input on its chain operators, without validating the commands between them.
+// Commands are returned as given, including surrounding whitespace.
+// raw, if given, is called with each command prior to splitting it from the next (see rawTracker);
+// if it returns true, the command consumes the remainder of the input.
+func chainLinks(input string, raw func(command string) bool) []link {
+	var (
+		links      []link
+		op         chainOp
+		start      int  // index of the current command
+		quote      rune // the quote character we are within, if any
+		tokenStart = true
+	)
+	for i, c := range input {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ' ' || c == '\t':
+			tokenStart = true
+			continue
+		case tokenStart && (c == ';' || c == '&' || c == '|'):
+			end := strings.IndexAny(input[i:], " \t")
+			if end == -1 {
+				end = len(input) - i
+			}
+			var next chainOp
+			switch input[i : i+end] {
+			case ";":
+				next = chainAlways
+			case "&&":
+				next = chainAnd
+			case "||":
+				next = chainOr
+			default:
+				tokenStart = false
+				continue
+			}
+			if raw != nil && raw(input[start:i]) {
+				return append(links, link{op, input[start:]})
+			}
+			links = append(links, link{op, input[start:i]})
+			op, start = next, i+end
+		}
+		tokenStart = false
+	}
+	return append(links, link{op, input[start:]})
+}
+
+// Splits the input into the commands of a chain, as chainLinks.
+// A single command (with any trailing semicolon dropped) is returned if the input is not chained.
+func splitChain(input string, raw func(command string) bool) ([]link, error) {
+	// catch unterminated quotes, which would otherwise swallow the remainder of the chain
+	if _, err := shlex.Split(input); err != nil {
+		return nil, err
+	}
+	links := chainLinks(input, raw)
+	for i := range links {
+		links[i].command = strings.TrimSpace(links[i].command)
+	}
+	// a trailing semicolon is permitted, as in a shell
+	if last := links[len(links)-1]; len(links) > 1 && last.op == chainAlways && last.command == "" {
+		links = links[:len(links)-1]
+	}
+	if len(links) == 1 {
+		return links, nil
+	}
+	for i, l := range links {
+		if l.command != "" {
+			continue
+		}
+		if i+1 < len(links) {
+			return nil, fmt.Errorf("expected a command before '%v'", links[i+1].op)
+		}
+		return nil, fmt.Errorf("expected a command after '%v'", l.op)
+	}
+	return links, nil
+}
+
+// IsChain returns whether the line, executed from root, chains multiple commands.
+func IsChain(root *cobra.Command, line string, aliases map[string]string) bool {
+	return len(chainLinks(line, rawTracker(root, aliases))) > 1
+}
+
+// Splits the input into the commands of a chain (as splitChain), expanding and rooting each
+// command that is an alias.
+func expandChain(dir *navCmd, input string, aliases map[string]string) ([]link, error) {
+	links, err := splitChain(input, rawTracker(dir, aliases))
+	if err != nil {
+		return nil, err
+	}
+	var expanded []link
+	for _, l := range links {
+		exp, ok := alias.Expand(aliases, l.command)
+		if !ok {
+			expanded = append(expanded, l)
+			continue
+		}
+		clilog.Writer.Debugf("expanded alias: %v -> %v", l.command, exp)
+		sub, err := splitChain(exp, rawTracker(dir.Root(), aliases))
+		if err != nil {
+			name, _, _ := strings.Cut(l.command, " ")
+			return nil, fmt.Errorf("alias %v: %w", name, err)
+		}
+		for i, s := range sub {
+			if i == 0 {
+				s.op = l.op // the expansion takes the alias's place in the chain
+			} else if e, ok := alias.Expand(aliases, s.command); ok {
+				s.command = e
+			}
+			s.command = "/ " + s.command
+			expanded = append(expanded, s)
+		}
+	}
+	return expanded, nil
+}
+
+// Returns a function reporting whether each successive command of a chain executed from dir invokes
+// an action that takes its arguments verbatim (see RawArgsAnnotation), following the chain as its
+// navs move it.
+func rawTracker(dir *navCmd, aliases map[string]string) func(command string) bool {
+	return func(command string) bool {
+		command = strings.TrimSpace(command)
+		if expanded, ok := alias.Expand(aliases, command); ok {
+			command = "/ " + expanded
+		}
+		wr := walk(dir, strings.Split(command, " "))
+		if wr.errString != "" {
+			return false
+		}
+		switch wr.status {
+		case foundNav:
+			dir = wr.endCommand
+		case foundAction:
+			return rawArgs(wr.endCommand)
+		}
+		return false
+	}
+}
+
+// Returns a command that executes the chain from pwd, returning a chainedMsg once it completes.
+func runChain(pwd *navCmd, links []link, aliases map[string]string) tea.Cmd {
+	return func() tea.Msg {
+		var out bytes.Buffer
+		s := newScript("chain", pwd, aliases, ScriptOptions{Stdout: &out, Stderr: &out})
+		var failed bool // status of the last command executed
+		for _, l := range links {
+			// actions need not end their output with a newline
+			if out.Len() > 0 && out.Bytes()[out.Len()-1] != '\n' {
+				out.WriteByte('\n')
+			}
+			if l.skipped(failed) {
+				out.WriteString(stylesheet.GreyedOutStyle.Render("── "+l.command+" (skipped) ──") + "\n")
+				continue
+			}
+			out.WriteString(stylesheet.Header2Style.Render("── "+l.command+" ──") + "\n")
+
+			_, err := s.execute(l.command)
+			if errors.Is(err, errScriptExit) {
+				return chainedMsg{pwd: s.pwd, output: strings.TrimSuffix(out.String(), "\n"), exit: true}
+			}
+			if failed = err != nil; failed {
+				clilog.Writer.Infof("chained command '%v' failed: %v", l.command, err)
+				out.WriteString(stylesheet.ErrStyle.Render(err.Error()) + "\n")
+			}
+		}
+		return chainedMsg{pwd: s.pwd, output: strings.TrimSuffix(out.String(), "\n")}
+	}
+}
+
+// Moves Mother to wherever the chain left off and prints its output.
+func (m *Mother) handleChained(msg chainedMsg) tea.Cmd {
+	m.pwd = msg.pwd
+	cmd := m.updateSuggestions()
+	if msg.exit {
+		return tea.Sequence(tea.Println(msg.output), quit(m, nil))
+	}
+	return tea.Batch(tea.Println(msg.output), cmd)
+}
@@ -0,0 +1,145 @@
+package mother
+
+import (
+	"gwcli/clilog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func Test_splitChain(t *testing.T) {
+	if err := clilog.Init(t.TempDir()+"/log.txt", "DEBUG"); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		input   string
+		raw     bool // track raw-arg actions within scriptTree
+		want    []link
+		wantErr bool
+	}{
+		{"macros list", false, []link{{chainAlways, "macros list"}}, false},
+		{"macros list ;", false, []link{{chainAlways, "macros list"}}, false},
+		{"macros ; list", false, []link{{chainAlways, "macros"}, {chainAlways, "list"}}, false},
+		{"macros; list", false, []link{{chainAlways, "macros; list"}}, false},
+		{"macros create -n X -e Y && macros list || kits", false,
+			[]link{{chainAlways, "macros create -n X -e Y"}, {chainAnd, "macros list"}, {chainOr, "kits"}}, false},
+		{`query "tag=x grep a && b ; c" && kits`, false,
+			[]link{{chainAlways, `query "tag=x grep a && b ; c"`}, {chainAnd, "kits"}}, false},
+		{"user myinfo | grep x || kits", false,
+			[]link{{chainAlways, "user myinfo | grep x"}, {chainOr, "kits"}}, false},
+		{"query tag=x a&&b", false, []link{{chainAlways, "query tag=x a&&b"}}, false},
+		{"query tag=x words a ; b && c", true, []link{{chainAlways, "query tag=x words a ; b && c"}}, false},
+		{"tools echo a ; query tag=x words a ; b", true,
+			[]link{{chainAlways, "tools echo a"}, {chainAlways, "query tag=x words a ; b"}}, false},
+		{"tools ; echo a ; query tag=x", true,
+			[]link{{chainAlways, "tools"}, {chainAlways, "echo a"}, {chainAlways, "query tag=x"}}, false},
+		{"&& macros", false, nil, true},
+		{"macros &&", false, nil, true},
+		{"macros ; ; kits", false, nil, true},
+		{`query "tag=x && kits`, false, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var raw func(string) bool
+			if tt.raw {
+				raw = rawTracker(scriptTree(), nil)
+			}
+			got, err := splitChain(tt.input, raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitChain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitChain() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_expandChain(t *testing.T) {
+	if err := clilog.Init(t.TempDir()+"/log.txt", "DEBUG"); err != nil {
+		t.Fatal(err)
+	}
+	aliases := map[string]string{
+		"e":    "tools echo",
+		"both": "tools echo a ; tools echo b",
+		"nest": "tools && e nested",
+		"bad":  "tools &&",
+	}
+	tests := []struct {
+		input   string
+		want    []link
+		wantErr bool
+	}{
+		{"tools echo a", []link{{chainAlways, "tools echo a"}}, false},
+		{"e a", []link{{chainAlways, "/ tools echo a"}}, false},
+		{"both", []link{{chainAlways, "/ tools echo a"}, {chainAlways, "/ tools echo b"}}, false},
+		{"tools || both", []link{{chainAlways, "tools"}, {chainOr, "/ tools echo a"},
+			{chainAlways, "/ tools echo b"}}, false},
+		{"nest ; e x", []link{{chainAlways, "/ tools"}, {chainAnd, "/ tools echo nested"},
+			{chainAlways, "/ tools echo x"}}, false},
+		{"bad", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := expandChain(scriptTree(), tt.input, aliases)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expandChain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expandChain() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_runChain(t *testing.T) {
+	if err := clilog.Init(t.TempDir()+"/log.txt", "DEBUG"); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		input      string
+		wantOutput []string // lines, sans styling
+		wantPwd    string
+		wantExit   bool
+	}{
+		{"tools echo a ; tools echo b",
+			[]string{"── tools echo a ──", "a", "── tools echo b ──", "b"}, "root", false},
+		{"tools fail && tools echo a || tools echo b",
+			[]string{"── tools fail ──", "something broke", "the action reported an error",
+				"── tools echo a (skipped) ──", "── tools echo b ──", "b"}, "root", false},
+		{"tools echo a || tools echo b && tools echo c",
+			[]string{"── tools echo a ──", "a", "── tools echo b (skipped) ──", "── tools echo c ──", "c"},
+			"root", false},
+		{"tools ; echo a",
+			[]string{"── tools ──", "── echo a ──", "a"}, "tools", false},
+		{"tools echo a ; exit ; tools echo b",
+			[]string{"── tools echo a ──", "a", "── exit ──"}, "root", true},
+		{"tools ; both", // every command of the alias is rooted, though the chain has moved
+			[]string{"── tools ──", "── / tools echo a ──", "a", "── / tools echo b ──", "b"}, "tools", false},
+	}
+	aliases := map[string]string{"both": "tools echo a ; tools echo b"}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			root := scriptTree()
+			links, err := expandChain(root, tt.input, aliases)
+			if err != nil {
+				t.Fatal(err)
+			}
+			msg, ok := runChain(root, links, aliases)().(chainedMsg)
+			if !ok {
+				t.Fatal("runChain did not return a chainedMsg")
+			}
+			if got := strings.Split(ansi.Strip(msg.output), "\n"); !reflect.DeepEqual(got, tt.wantOutput) {
+				t.Errorf("output = %q, want %q", got, tt.wantOutput)
+			}
+			if msg.pwd.Name() != tt.wantPwd {
+				t.Errorf("pwd = %v, want %v", msg.pwd.Name(), tt.wantPwd)
+			}
+			if msg.exit != tt.wantExit {
+				t.Errorf("exit = %v, want %v", msg.exit, tt.wantExit)
+			}
+		})
+	}
+}
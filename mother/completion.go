@@ -0,0 +1,373 @@
+package mother
+
+/*
+Completion drives the suggestions of Mother's prompt.
+
+The prompt is split into its head (every token prior to the one being typed) and the partial token
+being typed. The head is resolved by walk, from whatever it is anchored to (pwd, `..`, `~`, or `/`)
+and the suggestions are drawn from where it lands (if the head is chained, only its final command
+is considered):
+
+  - a nav offers its children (and, if the head is empty, builtins and aliases)
+  - an action offers its flags (and positional arguments, if it has a ValidArgsFunction) or, if the
+    head ends in a flag expecting a value, the flag's values
+  - `help` offers whatever its remaining tokens would, as help accepts any path
+  - `alias` and `unalias` offer existing aliases, `fg` and `kill` offer background jobs,
+    `status` offers its subcommands and fields, and `source` offers files
+
+Flag values come from the completion functions registered on the action's cobra.Command (so shell
+completion offers the same values) or, for flags marked as filenames, the local filesystem.
+Completion functions (including ValidArgsFunctions) typically query the backend, so they are called
+asynchronously: their candidates are added to the suggestions once they arrive, provided the prompt
+has not moved on in the meantime.
+Values are completed from the partial token's stem: everything up to its final comma (to complete
+lists) or slash (to complete paths).
+
+Each suggestion is the head followed by a candidate for the partial token, as the text input
+matches suggestions against the entirety of the prompt.
+Candidates are only regenerated when the head or stem changes, so typing within a token costs
+nothing beyond the text input's own prefix matching.
+*/
+
+import (
+	"gwcli/group"
+	"gwcli/utilities/alias"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// completion caches the inputs suggestions were last generated from, and the suggestions generated.
+type completion struct {
+	gen  uint    // incremented each time suggestions are regenerated
+	head string  // prompt text up to and including the final space
+	stem string  // partial token up to and including its final comma or slash
+	pwd  *navCmd // Mother's location
+
+	suggestions  []string
+	descriptions map[string]string // suggestion -> usage text displayed beneath the prompt
+
+	fetch fetcher // produces the candidates yet to arrive; nil if there are none
+}
+
+// fetcher produces candidates too costly to produce within Update (ex: those requiring a request to
+// the backend).
+type fetcher func() []candidate
+
+// completionMsg carries the candidates produced by a fetcher.
+type completionMsg struct {
+	gen        uint // generation of the suggestions the candidates belong to
+	candidates []candidate
+}
+
+// candidate is a possible completion of the partial token.
+type candidate struct {
+	text string // the entire token
+	desc string // usage text; optional
+}
+
+// Splits the given prompt into its head (everything up to and including its final space) and the
+// stem of its partial token.
+func splitPrompt(prompt string) (head, stem string) {
+	head = prompt[:strings.LastIndex(prompt, " ")+1]
+	partial := prompt[len(head):]
+	return head, partial[:strings.LastIndexAny(partial, ",/")+1]
+}
+
+// Regenerates the prompt's suggestions if its head, its stem, or Mother's location has changed
+// since they were last generated.
+// Called on every keypress.
+// Returns a command fetching the remaining candidates, if any.
+func (m *Mother) refreshSuggestions() tea.Cmd {
+	head, stem := splitPrompt(m.ti.Value())
+	if head != m.completion.head || stem != m.completion.stem || m.pwd != m.completion.pwd {
+		return m.updateSuggestions()
+	}
+	// the prompt may have been set directly (ex: from history), so have the text input rematch
+	m.ti.SetSuggestions(m.completion.suggestions)
+	return nil
+}
+
+// Regenerates the prompt's suggestions.
+// Call directly (rather than via refreshSuggestions) after moving or altering aliases.
+// Returns a command fetching the remaining candidates, if any.
+func (m *Mother) updateSuggestions() tea.Cmd {
+	head, stem := splitPrompt(m.ti.Value())
+	candidates, fetch := m.candidates(head, stem)
+	m.completion = completion{gen: m.completion.gen + 1, head: head, stem: stem, pwd: m.pwd,
+		descriptions: make(map[string]string), fetch: fetch}
+	m.addSuggestions(candidates)
+	return m.completion.fetchCmd()
+}
+
+// Returns a command that fetches the candidates yet to arrive, or nil if there are none.
+func (c completion) fetchCmd() tea.Cmd {
+	if c.fetch == nil {
+		return nil
+	}
+	gen, fetch := c.gen, c.fetch
+	return func() tea.Msg {
+		return completionMsg{gen: gen, candidates: fetch()}
+	}
+}
+
+// Adds fetched candidates to the suggestions, unless they have since been regenerated.
+func (m *Mother) handleCompletion(msg completionMsg) {
+	if msg.gen != m.completion.gen {
+		return
+	}
+	m.completion.fetch = nil
+	m.addSuggestions(msg.candidates)
+}
+
+// Appends the candidates to the suggestions, as completions of the current head.
+func (m *Mother) addSuggestions(candidates []candidate) {
+	head := m.completion.head
+	for _, c := range candidates {
+		m.completion.suggestions = append(m.completion.suggestions, head+c.text)
+		if c.desc != "" {
+			m.completion.descriptions[head+c.text] = c.desc
+		}
+	}
+	m.ti.SetSuggestions(m.completion.suggestions)
+}
+
+// Returns the usage text of the suggestion the prompt currently offers, if it has any.
+func (m *Mother) suggestionDescription() string {
+	value := strings.ToLower(m.ti.Value())
+	if value == "" {
+		return ""
+	}
+	// CurrentSuggestion panics if no suggestion matches (by case-insensitive prefix)
+	if !slices.ContainsFunc(m.completion.suggestions, func(s string) bool {
+		return strings.HasPrefix(strings.ToLower(s), value)
+	}) {
+		return ""
+	}
+	return m.completion.descriptions[m.ti.CurrentSuggestion()]
+}
+
+// Returns the candidates for the token following the given head, and a fetcher for those that are
+// costly to produce (nil if there are none).
+func (m *Mother) candidates(head, stem string) ([]candidate, fetcher) {
+	// each command of a chain is completed independently
+	links := chainLinks(head, rawTracker(m.pwd, m.aliases))
+	head = strings.TrimLeft(links[len(links)-1].command, " \t")
+
+	// aliases are rooted
+	tokens := strings.Split(head, " ")
+	if expanded, ok := alias.Expand(m.aliases, strings.TrimSpace(head)); ok {
+		tokens = append([]string{"/"}, strings.Split(expanded+" ", " ")...)
+	}
+	return m.candidatesFrom(m.pwd, tokens, stem)
+}
+
+// Walks the given tokens from dir, returning the candidates for the token following them (as
+// candidates does).
+func (m *Mother) candidatesFrom(dir *navCmd, tokens []string, stem string) ([]candidate, fetcher) {
+	wr := walk(dir, tokens)
+	switch wr.status {
+	case foundNav:
+		if strings.TrimSpace(strings.Join(tokens, "")) != "" {
+			return children(wr.endCommand), nil
+		}
+		// the start of a command; builtins and aliases are also acceptable
+		var names []string
+		for b := range builtins {
+			names = append(names, b)
+		}
+		slices.Sort(names)
+		names = append(names, alias.Names(m.aliases)...)
+		c := make([]candidate, len(names))
+		for i, n := range names {
+			c[i] = candidate{text: n}
+		}
+		return append(c, children(wr.endCommand)...), nil
+	case foundAction:
+		return actionCandidates(wr.endCommand, strings.Fields(wr.remainingString), stem)
+	case foundBuiltin:
+		switch wr.builtin {
+		case "help":
+			return m.candidatesFrom(dir, strings.Split(wr.remainingString, " "), stem)
+		case "source":
+			return paths(stem), nil
+		case "status":
+			return statusCandidates(strings.Fields(wr.remainingString), stem), nil
+		case "fg", "kill":
+			c := make([]candidate, len(m.jobs.bg))
+			for i, j := range m.jobs.bg {
+				c[i] = candidate{text: strconv.Itoa(j.id), desc: j.line}
+			}
+			return c, nil
+		case "alias", "unalias":
+			var c []candidate
+			for _, n := range alias.Names(m.aliases) {
+				c = append(c, candidate{text: n, desc: m.aliases[n]})
+			}
+			return c, nil
+		}
+	}
+	return nil, nil
+}
+
+// Returns the candidates for the status builtin's arguments: its subcommands or, following
+// `fields`, the remaining fields of the list being typed.
+func statusCandidates(args []string, stem string) []candidate {
+	switch {
+	case len(args) == 0:
+		return []candidate{{text: "on"}, {text: "off"}, {text: "fields"}}
+	case len(args) == 1 && args[0] == "fields":
+		given := strings.Split(stem, ",")
+		var c []candidate
+		for _, f := range statusFields {
+			if !slices.Contains(given, f) {
+				c = append(c, candidate{text: stem + f})
+			}
+		}
+		return c
+	}
+	return nil
+}
+
+// Returns the nav's visible children, as well as `..` if it has a parent.
+func children(nav *navCmd) []candidate {
+	var c []candidate
+	for _, child := range nav.Commands() {
+		// skip hidden commands and those cobra adds itself (ex: help)
+		if child.Hidden || (child.GroupID != group.NavID && child.GroupID != group.ActionID) {
+			continue
+		}
+		c = append(c, candidate{text: child.Name(), desc: child.Short})
+	}
+	if nav.HasParent() {
+		c = append(c, candidate{text: ".."})
+	}
+	return c
+}
+
+//#region actions
+
+// Returns the candidates for the token following the given arguments to the action: the values of
+// the final argument, if it is a flag awaiting its value, or flags and positional arguments
+// otherwise.
+// Values produced by completion functions are left to the returned fetcher.
+func actionCandidates(act *cobra.Command, args []string, stem string) ([]candidate, fetcher) {
+	if len(args) > 0 {
+		if f := valueFlag(act, args[len(args)-1]); f != nil {
+			return flagValues(act, f, positionals(act, args[:len(args)-1]), stem)
+		}
+	}
+	var c []candidate
+	act.LocalFlags().VisitAll(func(f *pflag.Flag) {
+		if !f.Hidden {
+			c = append(c, candidate{text: "--" + f.Name, desc: flagUsage(f)})
+		}
+	})
+	if act.ValidArgsFunction == nil {
+		return c, nil
+	}
+	args = positionals(act, args)
+	return c, func() []candidate {
+		return complete(act.ValidArgsFunction, act, args, stem)
+	}
+}
+
+// Returns the flag named by the token if it expects a value as the following token.
+func valueFlag(act *cobra.Command, token string) *pflag.Flag {
+	var f *pflag.Flag
+	if name, ok := strings.CutPrefix(token, "--"); ok {
+		f = act.LocalFlags().Lookup(name) // nil if the value was given via '='
+	} else if len(token) == 2 && token[0] == '-' {
+		f = act.LocalFlags().ShorthandLookup(token[1:])
+	}
+	if f == nil || f.NoOptDefVal != "" { // NoOptDefVal is set for flags that do not need a value
+		return nil
+	}
+	return f
+}
+
+// Returns the positional arguments among the given arguments, skipping flags and their values.
+func positionals(act *cobra.Command, args []string) []string {
+	var p []string
+	for i := 0; i < len(args); i++ {
+		if strings.HasPrefix(args[i], "-") {
+			if valueFlag(act, args[i]) != nil {
+				i++ // skip its value
+			}
+			continue
+		}
+		p = append(p, args[i])
+	}
+	return p
+}
+
+// Returns the values of the given flag from the local filesystem, if it was marked as a filename, or
+// a fetcher of its values from its completion function.
+func flagValues(act *cobra.Command, f *pflag.Flag, args []string, stem string) ([]candidate, fetcher) {
+	if _, ok := f.Annotations[cobra.BashCompFilenameExt]; ok {
+		return paths(stem), nil
+	}
+	fn, ok := act.GetFlagCompletionFunc(f.Name)
+	if !ok {
+		return nil, nil
+	}
+	return nil, func() []candidate { return complete(fn, act, args, stem) }
+}
+
+// Calls the cobra completion function, returning its completions as candidates.
+func complete(fn func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective),
+	act *cobra.Command, args []string, stem string) []candidate {
+	completions, directive := fn(act, args, stem)
+	if directive&cobra.ShellCompDirectiveError != 0 {
+		return nil
+	}
+	return parseCompletions(completions)
+}
+
+// Returns the first line of the flag's usage, prefixed by its shorthand.
+func flagUsage(f *pflag.Flag) string {
+	usage, _, _ := strings.Cut(f.Usage, "\n")
+	if f.Shorthand != "" {
+		return "-" + f.Shorthand + ": " + usage
+	}
+	return usage
+}
+
+// Converts completions, of the form returned by cobra completion functions ("value\tdescription"),
+// to candidates.
+func parseCompletions(completions []string) []candidate {
+	c := make([]candidate, len(completions))
+	for i, comp := range completions {
+		text, desc, _ := strings.Cut(comp, "\t")
+		c[i] = candidate{text: text, desc: desc}
+	}
+	return c
+}
+
+// Returns the entries of the directory named by the stem (or the working directory, if the stem is
+// empty). Directories are suffixed with a slash, so they can be completed into.
+func paths(stem string) []candidate {
+	dir := stem
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	c := make([]candidate, len(entries))
+	for i, e := range entries {
+		c[i] = candidate{text: stem + e.Name()}
+		if e.IsDir() {
+			c[i].text += "/"
+		}
+	}
+	return c
+}
+
+//#endregion actions
@@ -0,0 +1,213 @@
+package mother
+
+import (
+	"gwcli/clilog"
+	"gwcli/group"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/spf13/cobra"
+)
+
+// Builds a small tree:
+//
+//	root
+//	├── kits
+//	│   └── list (--all, --json, --columns, --id, --output)
+//	└── queries
+//	    └── scheduled
+//	        └── delete
+func completionTree() (root, scheduled *cobra.Command) {
+	nav := func(use string, children ...*cobra.Command) *cobra.Command {
+		c := &cobra.Command{Use: use, GroupID: group.NavID}
+		c.AddCommand(children...)
+		return c
+	}
+	list := &cobra.Command{Use: "list", GroupID: group.ActionID}
+	list.Flags().Bool("all", false, "")
+	list.Flags().Bool("json", false, "")
+	list.Flags().Bool("secret", false, "")
+	list.Flags().MarkHidden("secret")
+	list.Flags().StringSlice("columns", nil, "columns to display")
+	list.RegisterFlagCompletionFunc("columns",
+		func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return []string{toComplete + "ID", toComplete + "Name"}, cobra.ShellCompDirectiveNoSpace
+		})
+	list.Flags().StringP("id", "i", "", "id of the kit")
+	list.RegisterFlagCompletionFunc("id",
+		func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return []string{"1\tfirst kit", "2\tsecond kit"}, cobra.ShellCompDirectiveNoFileComp
+		})
+	list.Flags().StringP("output", "o", "", "file to write to")
+	list.MarkFlagFilename("output")
+
+	scheduled = nav("scheduled", &cobra.Command{Use: "delete", GroupID: group.ActionID})
+	root = nav("root",
+		nav("kits", list),
+		nav("queries", scheduled),
+		&cobra.Command{Use: "hidden", GroupID: group.ActionID, Hidden: true},
+		&cobra.Command{Use: "help"}, // added by cobra; not a nav or action
+	)
+	return root, scheduled
+}
+
+func TestCandidates(t *testing.T) {
+	if err := clilog.Init(t.TempDir()+"/log.txt", "DEBUG"); err != nil {
+		t.Fatal(err)
+	}
+	root, scheduled := completionTree()
+	aliases := map[string]string{"kl": "kits list", "k": "kits"}
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "out.json"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		pwd     *cobra.Command
+		head    string
+		stem    string
+		want    []string // must be offered
+		notWant []string // must not be offered
+	}{
+		{"empty head at root", root, "", "",
+			[]string{"help", "history", "k", "kl", "kits", "queries"}, []string{"hidden", "..", "list"}},
+		{"children of nav", root, "queries ", "",
+			[]string{"scheduled", ".."}, []string{"help", "kits", "kl"}},
+		{"upward", scheduled, ".. ", "",
+			[]string{"scheduled", ".."}, []string{"delete"}},
+		{"upward twice", scheduled, ".. .. ", "",
+			[]string{"kits", "queries"}, []string{"..", "scheduled"}},
+		{"from root", scheduled, "/ ", "",
+			[]string{"kits", "queries"}, []string{"delete", ".."}},
+		{"from home", scheduled, "~ kits ", "",
+			[]string{"list", ".."}, nil},
+		{"flags of action", root, "kits list ", "",
+			[]string{"--all", "--json"}, []string{"--secret", "list"}},
+		{"flags after arguments", root, "kits list --all ", "",
+			[]string{"--all", "--json"}, nil},
+		{"help path", scheduled, "help / kits ", "",
+			[]string{"list"}, []string{"help"}},
+		{"help of builtins", root, "help ", "",
+			[]string{"history", "kits", "kl"}, nil},
+		{"alias expansion", scheduled, "kl ", "",
+			[]string{"--all", "--json"}, nil},
+		{"alias of nav", scheduled, "k ", "",
+			[]string{"list"}, []string{"delete"}},
+		{"start of chained command", root, "kits list && ", "",
+			[]string{"help", "kits", "queries"}, []string{"--all"}},
+		{"chained command", root, "queries ; kits list ", "",
+			[]string{"--all", "--json"}, []string{"scheduled"}},
+		{"status subcommands", root, "status ", "",
+			[]string{"on", "off", "fields"}, []string{"kits"}},
+		{"status fields", root, "status fields ", "server,",
+			[]string{"server,user", "server,token"}, []string{"server,server", "user"}},
+		{"aliases to unalias", root, "unalias ", "",
+			[]string{"k", "kl"}, []string{"kits"}},
+		{"invalid path", root, "bogus ", "",
+			nil, []string{"kits", "help"}},
+		{"flag values", root, "kits list --id ", "",
+			[]string{"1", "2"}, []string{"--all"}},
+		{"shorthand flag values", root, "kits list --all -i ", "",
+			[]string{"1", "2"}, nil},
+		{"flag given value", root, "kits list --id=1 ", "",
+			[]string{"--all", "--columns"}, []string{"1"}},
+		{"flags after value", root, "kits list --id 1 ", "",
+			[]string{"--all", "--columns"}, []string{"1"}},
+		{"list values", root, "kits list --columns ", "ID,",
+			[]string{"ID,ID", "ID,Name"}, nil},
+		{"file values", root, "kits list -o ", dir + "/",
+			[]string{dir + "/sub/", dir + "/out.json"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Mother{root: root, pwd: tt.pwd, aliases: aliases}
+			c, fetch := m.candidates(tt.head, tt.stem)
+			if fetch != nil {
+				c = append(c, fetch()...)
+			}
+			var got []string
+			for _, c := range c {
+				got = append(got, c.text)
+			}
+			for _, w := range tt.want {
+				if !slices.Contains(got, w) {
+					t.Errorf("candidates(%q) = %v, missing %q", tt.head, got, w)
+				}
+			}
+			for _, nw := range tt.notWant {
+				if slices.Contains(got, nw) {
+					t.Errorf("candidates(%q) = %v, unexpectedly contains %q", tt.head, got, nw)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchedSuggestions(t *testing.T) {
+	if err := clilog.Init(t.TempDir()+"/log.txt", "DEBUG"); err != nil {
+		t.Fatal(err)
+	}
+	root, _ := completionTree()
+	m := Mother{root: root, pwd: root, ti: textinput.New()}
+	m.ti.SetValue("kits list --id ")
+	cmd := m.updateSuggestions()
+	if cmd == nil {
+		t.Fatal("expected the flag's values to be fetched")
+	}
+	if slices.Contains(m.completion.suggestions, "kits list --id 1") {
+		t.Fatal("flag values were fetched within Update")
+	}
+	msg, ok := cmd().(completionMsg)
+	if !ok {
+		t.Fatal("fetch did not return a completionMsg")
+	}
+
+	// values arriving after the prompt has moved on are dropped
+	m.ti.SetValue("kits list --all ")
+	m.updateSuggestions()
+	m.handleCompletion(msg)
+	if slices.Contains(m.completion.suggestions, "kits list --id 1") {
+		t.Errorf("stale values were added: %v", m.completion.suggestions)
+	}
+
+	m.ti.SetValue("kits list --id ")
+	msg = m.updateSuggestions()().(completionMsg)
+	m.handleCompletion(msg)
+	for _, want := range []string{"kits list --id 1", "kits list --id 2"} {
+		if !slices.Contains(m.completion.suggestions, want) {
+			t.Errorf("suggestions = %v, missing %q", m.completion.suggestions, want)
+		}
+	}
+	if d := m.completion.descriptions["kits list --id 1"]; d != "first kit" {
+		t.Errorf("description = %q, want %q", d, "first kit")
+	}
+}
+
+func Test_splitPrompt(t *testing.T) {
+	tests := []struct {
+		prompt   string
+		wantHead string
+		wantStem string
+	}{
+		{"", "", ""},
+		{"kits", "", ""},
+		{"kits ", "kits ", ""},
+		{"kits li", "kits ", ""},
+		{".. .. que", ".. .. ", ""},
+		{"kits list --columns ID,Na", "kits list --columns ", "ID,"},
+		{"query -o out/dir/fi", "query -o ", "out/dir/"},
+	}
+	for _, tt := range tests {
+		if head, stem := splitPrompt(tt.prompt); head != tt.wantHead || stem != tt.wantStem {
+			t.Errorf("splitPrompt(%q) = (%q, %q), want (%q, %q)",
+				tt.prompt, head, stem, tt.wantHead, tt.wantStem)
+		}
+	}
+}
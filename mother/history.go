@@ -9,42 +9,180 @@ NOTE: The array is self-destructive; once the cap is reached, the array will beg
 oldest commands.
 
 Newer commands have higher indices.
+
+If the history is given a path, each record is also appended to the file at that path so it can be
+reloaded in later sessions (see loadHistory). Once the file holds twice as many records as the
+history retains, it is rewritten to just the retained records, bounding its size.
+Records prefixed with a space are neither stored nor persisted, allowing users to keep sensitive
+commands out of their history.
 */
 
 import (
+	"bufio"
+	"errors"
+	"gwcli/clilog"
+	"io"
 	"math"
+	"os"
 	"strings"
 )
 
 const ( // readability "macros"
 	unset            = math.MaxUint16
-	arrayEnd  uint16 = 999          // last valid index
-	arraySize uint16 = arrayEnd + 1 // actual array size
+	arrayEnd  uint16 = 999          // last valid index of a default-sized history
+	arraySize uint16 = arrayEnd + 1 // actual array size of a default-sized history
 )
 
+const historyFilePerm = 0600 // history may contain sensitive data; keep it private
+
 type history struct {
 	commands       []string // previous commands; lower indices are newer
+	end            uint16   // last valid index
 	fetchedIndex   uint16   // last index used to retrieve a record
 	insertionIndex uint16   // index to insert next history record at
+
+	path      string // file to persist records to; no persistence if empty
+	persisted int    // number of records in the file at path
 }
 
+// Returns a new, in-memory history of the default size.
 func newHistory() *history {
+	return newHistoryOfSize(arraySize)
+}
+
+// Returns a new, in-memory history that retains up to size records.
+// Sizes outside of [1, unset) are replaced with the default size.
+func newHistoryOfSize(size uint16) *history {
+	if size == 0 || size >= unset {
+		size = arraySize
+	}
 	h := history{}
-	h.commands = make([]string, arraySize)
+	h.commands = make([]string, size)
+	h.end = size - 1
 	h.fetchedIndex = unset
 	h.insertionIndex = 0
 
 	return &h
 }
 
-// Inserts a new record at the current end of the list
+// Returns a new history of the given size, populated from (and persisting to) the file at path.
+// If the file contains more records than size, it is truncated to just the records retained.
+// A size of 0 disables the file; the history is in-memory (of the default size) and path is neither
+// read nor written.
+// A missing file is not an error.
+// On error, a usable, in-memory history is still returned.
+func loadHistory(path string, size uint16) (*history, error) {
+	if size == 0 {
+		return newHistory(), nil
+	}
+	h := newHistoryOfSize(size)
+	if path == "" {
+		return h, errors.New("no history path given")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			h.path = path
+			return h, nil
+		}
+		return h, err
+	}
+	// read line by line, without a limit on line length (unlike bufio.Scanner)
+	var (
+		lines []string
+		rdr   = bufio.NewReader(f)
+	)
+	for {
+		l, err := rdr.ReadString('\n')
+		if l = strings.TrimSuffix(l, "\n"); l != "" {
+			lines = append(lines, l)
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			f.Close()
+			return h, err
+		}
+	}
+	f.Close()
+
+	for _, l := range lines {
+		h.insert(l)
+	}
+	h.persisted = len(lines)
+
+	// rewrite the file if it contains more records than we can hold
+	if len(lines) > len(h.commands) {
+		if err := h.rewrite(path); err != nil {
+			return h, err
+		}
+	}
+	h.path = path
+
+	return h, nil
+}
+
+// Truncates the file at path and writes all current records to it, oldest first.
+func (h *history) rewrite(path string) error {
+	h.persisted = 0
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, historyFilePerm)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	rs := h.getAllRecords()
+	w := bufio.NewWriter(f)
+	for i := len(rs) - 1; i >= 0; i-- {
+		if _, err := w.WriteString(rs[i] + "\n"); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	h.persisted = len(rs)
+	return nil
+}
+
+// Inserts a new record at the current end of the list.
+// Empty records, records prefixed with a space, and records matching the newest record are ignored.
 func (h *history) insert(record string) {
+	if strings.HasPrefix(record, " ") { // sensitive record; do not store
+		return
+	}
 	record = strings.TrimSpace(record)
 	if record == "" { // do not insert empty records
 		return
 	}
+	if h.commands[h.decrement(h.insertionIndex)] == record { // consecutive duplicate
+		return
+	}
 	h.commands[h.insertionIndex] = record
-	h.insertionIndex = increment(h.insertionIndex)
+	h.insertionIndex = h.increment(h.insertionIndex)
+
+	if h.path != "" {
+		if err := appendToFile(h.path, record); err != nil {
+			clilog.Writer.Warnf("failed to persist history record: %v", err)
+		} else if h.persisted++; h.persisted >= 2*len(h.commands) {
+			// compact the file, so it does not grow without bound over a long session
+			if err := h.rewrite(h.path); err != nil {
+				clilog.Writer.Warnf("failed to compact history file: %v", err)
+			}
+		}
+	}
+}
+
+// helper function for insert.
+// Appends the record to the file at path, creating it if necessary.
+func appendToFile(path, record string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, historyFilePerm)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(record + "\n")
+	return err
 }
 
 // Starting at the newest record, returns progressively older records for each successive call.
@@ -52,17 +190,17 @@ func (h *history) insert(record string) {
 // Call `.unsetFetch` to restart at the newest record.
 func (h *history) getOlderRecord() string {
 	if h.fetchedIndex == unset {
-		h.fetchedIndex = decrement(h.insertionIndex)
+		h.fetchedIndex = h.decrement(h.insertionIndex)
 		return h.commands[h.fetchedIndex]
 	}
 
 	// do not move past boundary empty record
-	if h.commands[h.fetchedIndex] == "" && h.commands[decrement(h.fetchedIndex)] == "" {
+	if h.commands[h.fetchedIndex] == "" && h.commands[h.decrement(h.fetchedIndex)] == "" {
 		// do nothing
 		return ""
 	}
 
-	h.fetchedIndex = decrement(h.fetchedIndex)
+	h.fetchedIndex = h.decrement(h.fetchedIndex)
 
 	return h.commands[h.fetchedIndex]
 }
@@ -70,17 +208,17 @@ func (h *history) getOlderRecord() string {
 // Flip side to primary command GetOlderRecord.
 func (h *history) getNewerRecord() string {
 	if h.fetchedIndex == unset {
-		h.fetchedIndex = increment(h.insertionIndex)
+		h.fetchedIndex = h.increment(h.insertionIndex)
 		return h.commands[h.fetchedIndex]
 	}
 
 	// do not move past boundary empty record
-	if h.commands[h.fetchedIndex] == "" && h.commands[increment(h.fetchedIndex)] == "" {
+	if h.commands[h.fetchedIndex] == "" && h.commands[h.increment(h.fetchedIndex)] == "" {
 		// do nothing
 		return ""
 	}
 
-	h.fetchedIndex = increment(h.fetchedIndex)
+	h.fetchedIndex = h.increment(h.fetchedIndex)
 
 	return h.commands[h.fetchedIndex]
 
@@ -94,10 +232,10 @@ func (h *history) unsetFetch() {
 // Returns all history records, ordered from [0]newest to [len-1]oldest.
 // NOTE: this is a destructive call: it will reset unset the fetch index.
 func (h *history) getAllRecords() (records []string) {
-	records = make([]string, arraySize)
-	var i uint16
+	records = make([]string, len(h.commands))
+	var i int
 	h.fetchedIndex = unset
-	for i = 0; i < arraySize; i++ {
+	for i = 0; i < len(h.commands); i++ {
 		r := h.getOlderRecord()
 		if r == "" { // all records given
 			break
@@ -110,19 +248,34 @@ func (h *history) getAllRecords() (records []string) {
 	return records[:i] // clip length
 }
 
-// Decrements the given number, underflows around arraysize
-func decrement(i uint16) uint16 {
+// Searches backwards through history, returning the newest record containing substr that is
+// older than the record at index (where 0 is the newest record; see getAllRecords).
+// Pass -1 to search from the newest record.
+// Returns the empty string and -1 if no match was found.
+// NOTE: this is a destructive call: it will reset unset the fetch index.
+func (h *history) searchOlder(substr string, index int) (record string, recordIndex int) {
+	rs := h.getAllRecords()
+	for i := index + 1; i < len(rs); i++ {
+		if strings.Contains(rs[i], substr) {
+			return rs[i], i
+		}
+	}
+	return "", -1
+}
+
+// Decrements the given number, underflows around the history's size
+func (h *history) decrement(i uint16) uint16 {
 	if i == 0 {
-		i = arrayEnd
+		i = h.end
 	} else {
 		i -= 1
 	}
 	return i
 }
 
-// Sister function to decrement; overflows around arraysize
-func increment(i uint16) uint16 {
-	if i == arrayEnd {
+// Sister function to decrement; overflows around the history's size
+func (h *history) increment(i uint16) uint16 {
+	if i == h.end {
 		i = 0
 	} else {
 		i += 1
@@ -2,8 +2,12 @@
 package mother
 
 import (
+	"errors"
 	"fmt"
 	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -258,8 +262,8 @@ func Test_history_GetAllRecords(t *testing.T) {
 		h := newHistory()
 		want := make([]string, cap)
 		for i := 0; i < cap; i++ {
-			h.insert("command")
-			want[i] = "command"
+			h.insert(fmt.Sprintf("command%d", i))
+			want[i] = fmt.Sprintf("command%d", i)
 		}
 		rs := h.getAllRecords()
 		if len(rs) != cap {
@@ -295,3 +299,150 @@ func Test_history_GetAllRecords(t *testing.T) {
 	})
 
 }
+
+func Test_history_InsertFiltering(t *testing.T) {
+	h := newHistory()
+	h.insert("A")
+	h.insert("A")   // consecutive duplicate
+	h.insert(" B")  // sensitive
+	h.insert("A  ") // duplicate after trimming
+	h.insert("C")
+	h.insert("A") // not consecutive
+
+	want := []string{"A", "C", "A"}
+	rs := h.getAllRecords()
+	if len(rs) != len(want) {
+		t.Fatalf("records mismatch: expected %v, got %v", want, rs)
+	}
+	for i := range want {
+		if rs[i] != want[len(want)-1-i] {
+			t.Errorf("records mismatch: expected (newest first) %v, got %v", want, rs)
+		}
+	}
+}
+
+func Test_history_Sized(t *testing.T) {
+	h := newHistoryOfSize(3)
+	for _, r := range []string{"A", "B", "C", "D"} {
+		h.insert(r)
+	}
+	rs := h.getAllRecords()
+	if len(rs) != 3 || rs[0] != "D" || rs[2] != "B" {
+		t.Errorf("expected [D C B], got %v", rs)
+	}
+}
+
+func Test_history_searchOlder(t *testing.T) {
+	h := newHistory()
+	for _, r := range []string{"query tag=a", "macros list", "query tag=b"} {
+		h.insert(r)
+	}
+	rec, idx := h.searchOlder("query", -1)
+	if rec != "query tag=b" || idx != 0 {
+		t.Errorf("first search: expected (query tag=b, 0), got (%v, %v)", rec, idx)
+	}
+	rec, idx = h.searchOlder("query", idx)
+	if rec != "query tag=a" || idx != 2 {
+		t.Errorf("second search: expected (query tag=a, 2), got (%v, %v)", rec, idx)
+	}
+	if rec, idx = h.searchOlder("query", idx); rec != "" || idx != -1 {
+		t.Errorf("exhausted search: expected ('', -1), got (%v, %v)", rec, idx)
+	}
+}
+
+func Test_loadHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+
+	t.Run("missing file", func(t *testing.T) {
+		h, err := loadHistory(path, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h.insert("A")
+		h.insert("B")
+		h.insert(" secret")
+	})
+	t.Run("persisted records", func(t *testing.T) {
+		if b, err := os.ReadFile(path); err != nil {
+			t.Fatal(err)
+		} else if string(b) != "A\nB\n" {
+			t.Fatalf("unexpected history file contents: %q", b)
+		}
+		h, err := loadHistory(path, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rs := h.getAllRecords(); len(rs) != 2 || rs[0] != "B" || rs[1] != "A" {
+			t.Errorf("expected [B A], got %v", rs)
+		}
+		h.insert("C")
+		h.insert("D")
+	})
+	t.Run("truncated to size", func(t *testing.T) {
+		h, err := loadHistory(path, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rs := h.getAllRecords(); len(rs) != 3 || rs[0] != "D" || rs[2] != "B" {
+			t.Errorf("expected [D C B], got %v", rs)
+		}
+		if b, err := os.ReadFile(path); err != nil {
+			t.Fatal(err)
+		} else if string(b) != "B\nC\nD\n" {
+			t.Errorf("history file was not truncated: %q", b)
+		}
+	})
+}
+
+func Test_loadHistory_disabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	h, err := loadHistory(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.insert("A")
+	if rs := h.getAllRecords(); len(rs) != 1 || rs[0] != "A" {
+		t.Errorf("expected [A], got %v", rs)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected no history file to be created, stat returned %v", err)
+	}
+}
+
+func Test_loadHistory_compaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	h, err := loadHistory(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []string{"A", "B", "C", "D", "E"} {
+		h.insert(r)
+	}
+	if b, err := os.ReadFile(path); err != nil {
+		t.Fatal(err)
+	} else if string(b) != "A\nB\nC\nD\nE\n" {
+		t.Fatalf("unexpected history file contents: %q", b)
+	}
+	// the sixth record reaches twice the size and triggers a rewrite
+	h.insert("F")
+	if b, err := os.ReadFile(path); err != nil {
+		t.Fatal(err)
+	} else if string(b) != "D\nE\nF\n" {
+		t.Errorf("history file was not compacted: %q", b)
+	}
+}
+
+func Test_loadHistory_longLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	long := strings.Repeat("x", 128*1024)
+	if err := os.WriteFile(path, []byte("A\n"+long+"\nB\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	h, err := loadHistory(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs := h.getAllRecords(); len(rs) != 3 || rs[0] != "B" || rs[1] != long || rs[2] != "A" {
+		t.Errorf("long line was not loaded (got %d records)", len(rs))
+	}
+}
@@ -0,0 +1,240 @@
+package mother
+
+/*
+Jobs are the actions Mother has handed off to.
+
+At most one job, the foreground job, is in control at a time (Mother is in handoff mode). The
+background key moves it into the background, returning control to the prompt; `fg <n>` returns it
+to the foreground and `kill <n>` ends it. `jobs` lists the background jobs.
+
+Background jobs are not updated; they are suspended until returned to the foreground. Actions that
+implement action.Backgrounder, however, continue their long-running operation (ex: a query's
+search) in the background. Mother polls them for as long as they are in the background, keeping
+them alive (as Update otherwise would) and announcing each once its operation completes.
+
+Actions are instantiated once, so each action can only be a single job at a time.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"gwcli/action"
+	"gwcli/clilog"
+	"gwcli/stylesheet"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// how often background jobs are checked for completion
+const jobPollInterval = time.Second
+
+// job is an action Mother has handed off to.
+type job struct {
+	id      int
+	command *actionCmd   // command user called
+	model   action.Model // Elm Arch associated to command
+	line    string       // the action and its arguments, as invoked
+	ended   bool         // the job's operation completed in the background and has been announced
+}
+
+// Returns whether the job's operation is running in the background, done, or suspended.
+func (j *job) status() string {
+	if b, ok := j.model.(action.Backgrounder); ok {
+		if b.Pending() {
+			return "running"
+		}
+		return "done"
+	}
+	return "suspended"
+}
+
+// Returns the job as displayed by `jobs` and its announcements.
+func (j *job) String() string {
+	return fmt.Sprintf("[%d] %-9s %v", j.id, j.status(), j.line)
+}
+
+// jobTable holds Mother's foreground job and her background jobs.
+type jobTable struct {
+	fg      *job   // nil if Mother is not in handoff mode
+	bg      []*job // ordered by id
+	polling bool   // a jobPollMsg is pending
+}
+
+// jobPollMsg prompts Mother to check her background jobs for completion.
+type jobPollMsg struct{}
+
+// Returns the lowest id not in use, as a shell would.
+func (t *jobTable) nextID() int {
+	id := 1
+	for _, j := range t.bg {
+		if j.id != id {
+			break
+		}
+		id++
+	}
+	return id
+}
+
+// Returns the first background job satisfying match and its index, or (-1, nil) if none do.
+func (t *jobTable) find(match func(*job) bool) (int, *job) {
+	i := slices.IndexFunc(t.bg, match)
+	if i == -1 {
+		return -1, nil
+	}
+	return i, t.bg[i]
+}
+
+// Parses the id argument of fg and kill, returning the background job it refers to.
+// The id may be omitted if there is only a single background job.
+func (t *jobTable) parseID(args []string) (int, *job, error) {
+	var tokens []string
+	for _, a := range args {
+		if a = strings.TrimSpace(a); a != "" {
+			tokens = append(tokens, a)
+		}
+	}
+	switch {
+	case len(t.bg) == 0:
+		return -1, nil, errors.New("no background jobs")
+	case len(tokens) == 0 && len(t.bg) == 1:
+		return 0, t.bg[0], nil
+	case len(tokens) != 1:
+		return -1, nil, errors.New("expected a job number (see " +
+			stylesheet.ExampleStyle.Render("jobs") + ")")
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(tokens[0], "%"))
+	if err != nil {
+		return -1, nil, fmt.Errorf("'%v' is not a job number", tokens[0])
+	}
+	i, j := t.find(func(j *job) bool { return j.id == id })
+	if j == nil {
+		return -1, nil, fmt.Errorf("no job %d", id)
+	}
+	return i, j, nil
+}
+
+// Returns a command that delivers a jobPollMsg after the poll interval, unless one is already
+// pending.
+func (t *jobTable) poll() tea.Cmd {
+	if t.polling {
+		return nil
+	}
+	t.polling = true
+	return tea.Tick(jobPollInterval, func(time.Time) tea.Msg { return jobPollMsg{} })
+}
+
+// Moves the foreground job into the background, returning control to the prompt.
+func (m *Mother) backgroundJob() tea.Cmd {
+	j := m.jobs.fg
+	j.id = m.jobs.nextID()
+	m.jobs.bg = append(m.jobs.bg, j)
+	slices.SortFunc(m.jobs.bg, func(a, b *job) int { return a.id - b.id })
+	m.jobs.fg = nil
+	m.mode = prompting
+	clilog.Writer.Infof("Backgrounded %v as job %d. Reasserting...", j.command.Name(), j.id)
+
+	cmds := []tea.Cmd{tea.ExitAltScreen, tea.Println(j.String()), textinput.Blink}
+	if _, ok := j.model.(action.Backgrounder); ok {
+		// an operation completed prior to backgrounding needs no announcement
+		j.ended = j.status() == "done"
+		cmds = append(cmds, m.jobs.poll())
+	}
+	return tea.Sequence(cmds...)
+}
+
+// Keeps each background job that implements action.Backgrounder alive and announces each whose
+// operation has completed since the last poll, continuing to poll while any remain.
+func (m *Mother) pollJobs() tea.Cmd {
+	m.jobs.polling = false
+	var (
+		cmds  []tea.Cmd
+		alive bool // a job still needs polling
+	)
+	for _, j := range m.jobs.bg {
+		b, ok := j.model.(action.Backgrounder)
+		if !ok {
+			continue
+		}
+		b.KeepAlive()
+		alive = true
+		if j.status() == "done" && !j.ended {
+			j.ended = true
+			cmds = append(cmds, tea.Println(j.String()+
+				stylesheet.GreyedOutStyle.Render(fmt.Sprintf(" (fg %d to view)", j.id))))
+		}
+	}
+	if alive {
+		cmds = append(cmds, m.jobs.poll())
+	}
+	return tea.Sequence(cmds...)
+}
+
+// Returns the prompt's indicator of background jobs, if there are any.
+func (m *Mother) jobsIndicator() string {
+	switch n := len(m.jobs.bg); n {
+	case 0:
+		return ""
+	case 1:
+		return stylesheet.IndexStyle.Render("[1 job]") + " "
+	default:
+		return stylesheet.IndexStyle.Render(fmt.Sprintf("[%d jobs]", n)) + " "
+	}
+}
+
+//#region builtins
+
+// Lists the background jobs.
+func listJobs(m *Mother, _ []string) tea.Cmd {
+	if len(m.jobs.bg) == 0 {
+		return tea.Println("no background jobs")
+	}
+	var sb strings.Builder
+	for _, j := range m.jobs.bg {
+		sb.WriteString(j.String() + "\n")
+	}
+	return tea.Println(strings.TrimSuffix(sb.String(), "\n"))
+}
+
+// Returns the given background job to the foreground, handing off to it.
+func foregroundJob(m *Mother, args []string) tea.Cmd {
+	i, j, err := m.jobs.parseID(args)
+	if err != nil {
+		return tea.Println(stylesheet.ErrStyle.Render(err.Error()))
+	}
+	m.jobs.bg = slices.Delete(m.jobs.bg, i, i+1)
+	j.ended = false
+	m.jobs.fg = j
+	m.mode = handoff
+	clilog.Writer.Infof("Handing off control to job %d (%s)", j.id, j.command.Name())
+
+	var cmds []tea.Cmd
+	// redraw the job at the current size; the job may have been backgrounded before a resize
+	if m.winSize.Width > 0 {
+		cmds = append(cmds, j.model.Update(m.winSize))
+	}
+	if b, ok := j.model.(action.Backgrounder); ok {
+		cmds = append(cmds, b.Resume())
+	}
+	return tea.Batch(cmds...)
+}
+
+// Ends the given background job.
+func killJob(m *Mother, args []string) tea.Cmd {
+	i, j, err := m.jobs.parseID(args)
+	if err != nil {
+		return tea.Println(stylesheet.ErrStyle.Render(err.Error()))
+	}
+	m.jobs.bg = slices.Delete(m.jobs.bg, i, i+1)
+	clilog.Writer.Infof("Killing job %d (%s)", j.id, j.command.Name())
+	if err := j.model.Reset(); err != nil {
+		clilog.Writer.Warnf("failed to reset %v: %v", j.command.Name(), err)
+	}
+	return tea.Println(fmt.Sprintf("[%d] %-9s %v", j.id, "killed", j.line))
+}
+
+//#endregion builtins
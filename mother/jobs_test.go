@@ -0,0 +1,153 @@
+package mother
+
+import (
+	"gwcli/clilog"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// fakeModel is an action.Model that never completes.
+type fakeModel struct {
+	reset bool
+}
+
+func (f *fakeModel) Update(tea.Msg) tea.Cmd { return nil }
+func (f *fakeModel) View() string           { return "" }
+func (f *fakeModel) Done() bool             { return false }
+func (f *fakeModel) Reset() error           { f.reset = true; return nil }
+func (f *fakeModel) SetArgs(*pflag.FlagSet, []string) (string, tea.Cmd, error) {
+	return "", nil, nil
+}
+
+// fakeBackgrounder is an action.Backgrounder whose operation is pending until told otherwise.
+type fakeBackgrounder struct {
+	fakeModel
+	pending    bool
+	resumed    bool
+	keepAlives int
+}
+
+func (f *fakeBackgrounder) Pending() bool   { return f.pending }
+func (f *fakeBackgrounder) KeepAlive()      { f.keepAlives++ }
+func (f *fakeBackgrounder) Resume() tea.Cmd { f.resumed = true; return nil }
+
+func Test_jobTable_parseID(t *testing.T) {
+	one := jobTable{bg: []*job{{id: 1}}}
+	two := jobTable{bg: []*job{{id: 1}, {id: 3}}}
+	tests := []struct {
+		name    string
+		table   jobTable
+		args    []string
+		wantID  int
+		wantErr bool
+	}{
+		{"no jobs", jobTable{}, []string{"1"}, 0, true},
+		{"implicit sole job", one, nil, 1, false},
+		{"implicit among many", two, []string{""}, 0, true},
+		{"explicit", two, []string{"3"}, 3, false},
+		{"percent prefix", two, []string{"%1"}, 1, false},
+		{"unknown", two, []string{"2"}, 0, true},
+		{"not a number", two, []string{"query"}, 0, true},
+		{"too many", two, []string{"1", "3"}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, j, err := tt.table.parseID(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && j.id != tt.wantID {
+				t.Errorf("parseID() = job %d, want %d", j.id, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestJobControl(t *testing.T) {
+	if err := clilog.Init(t.TempDir()+"/log.txt", "DEBUG"); err != nil {
+		t.Fatal(err)
+	}
+	root := &cobra.Command{Use: "root"}
+	search, edit := &cobra.Command{Use: "search"}, &cobra.Command{Use: "edit"}
+	searchModel, editModel := &fakeBackgrounder{pending: true}, &fakeModel{}
+	m := Mother{root: root, pwd: root}
+
+	// background a pending operation and a suspendable action
+	m.mode, m.jobs.fg = handoff, &job{command: search, model: searchModel, line: "search tag=x"}
+	if m.backgroundJob(); m.mode != prompting || m.jobs.fg != nil || !m.jobs.polling {
+		t.Fatalf("after backgrounding: mode %v, fg %v, polling %v", m.mode, m.jobs.fg, m.jobs.polling)
+	}
+	m.mode, m.jobs.fg = handoff, &job{command: edit, model: editModel, line: "edit"}
+	m.backgroundJob()
+	if len(m.jobs.bg) != 2 || m.jobs.bg[0].status() != "running" || m.jobs.bg[1].status() != "suspended" {
+		t.Fatalf("unexpected background jobs: %v", m.jobs.bg)
+	}
+
+	// an action cannot be started while it is a job
+	if processActionHandoff(&m, search, ""); m.mode != prompting || m.jobs.fg != nil {
+		t.Fatal("a backgrounded action was handed off to")
+	}
+
+	// completion is announced once
+	m.pollJobs()
+	if m.jobs.bg[0].ended || !m.jobs.polling {
+		t.Fatal("a pending job was announced or polling stopped")
+	}
+	searchModel.pending = false
+	m.pollJobs()
+	if !m.jobs.bg[0].ended || !m.jobs.polling {
+		t.Fatal("a completed job was not announced or polling stopped")
+	}
+
+	// the lowest free id is reused
+	foregroundJob(&m, []string{"1"})
+	if m.mode != handoff || m.jobs.fg.command != search || !searchModel.resumed || len(m.jobs.bg) != 1 {
+		t.Fatalf("job 1 was not resumed: %+v", m.jobs)
+	}
+	m.backgroundJob()
+	if m.jobs.bg[0].id != 1 || m.jobs.bg[0].command != search {
+		t.Fatalf("job ids were not reused: %v", m.jobs.bg)
+	}
+
+	killJob(&m, []string{"2"})
+	if !editModel.reset || len(m.jobs.bg) != 1 {
+		t.Fatal("job 2 was not killed")
+	}
+}
+
+func TestJobKeepAlive(t *testing.T) {
+	if err := clilog.Init(t.TempDir()+"/log.txt", "DEBUG"); err != nil {
+		t.Fatal(err)
+	}
+	root := &cobra.Command{Use: "root"}
+	// a query displaying its results: its search has completed, but must be kept alive
+	display := &fakeBackgrounder{}
+	m := Mother{root: root, pwd: root}
+	m.mode, m.jobs.fg = handoff, &job{command: &cobra.Command{Use: "query"}, model: display,
+		line: "query tag=x"}
+	m.backgroundJob()
+	if !m.jobs.bg[0].ended || !m.jobs.polling {
+		t.Fatalf("backgrounded display: ended %v, polling %v", m.jobs.bg[0].ended, m.jobs.polling)
+	}
+
+	// a long stay in the background; far longer than a search may go without a heartbeat
+	polls := int(time.Hour / jobPollInterval)
+	for i := 0; i < polls; i++ {
+		if m.pollJobs(); !m.jobs.polling {
+			t.Fatalf("polling stopped after %d polls", i+1)
+		}
+	}
+	if display.keepAlives != polls {
+		t.Errorf("job was kept alive %d times over %d polls", display.keepAlives, polls)
+	}
+
+	// once the job leaves the background, polling stops
+	killJob(&m, nil)
+	if m.pollJobs(); m.jobs.polling {
+		t.Error("polling continued without background jobs")
+	}
+}
@@ -0,0 +1,54 @@
+package mother
+
+import (
+	"gwcli/utilities/keymap"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// keymap scopes of Mother's bindings (see keymap.Scope).
+// The prompt's and reverse search's bindings are never active at the same time.
+var (
+	motherScope  = keymap.NewScope("mother", keymap.Global)
+	promptScope  = keymap.NewScope("prompt", motherScope)
+	rsearchScope = keymap.NewScope("reverse-search", motherScope)
+)
+
+var keys = struct {
+	// handoff
+	background key.Binding // move the foreground job into the background
+
+	// prompt
+	submit        key.Binding
+	help          key.Binding
+	historyOlder  key.Binding
+	historyNewer  key.Binding
+	reverseSearch key.Binding // begin a reverse search
+
+	// reverse search
+	nextMatch    key.Binding // step to the next, older match
+	cancelSearch key.Binding // restore the prompt as it was prior to searching
+	acceptMatch  key.Binding // submit the match
+}{
+	background: keymap.Bind(keymap.Global, "background", "send the current action to the background",
+		tea.KeyCtrlZ.String()),
+
+	submit: keymap.Bind(promptScope, "submit", "submit",
+		tea.KeyEnter.String()),
+	help: keymap.Bind(promptScope, "help", "context-sensitive help",
+		tea.KeyF1.String()),
+	historyOlder: keymap.Bind(promptScope, "history-older", "previous command",
+		tea.KeyUp.String()),
+	historyNewer: keymap.Bind(promptScope, "history-newer", "next command",
+		tea.KeyDown.String()),
+	reverseSearch: keymap.Bind(promptScope, "reverse-search", "search history",
+		tea.KeyCtrlR.String()),
+
+	nextMatch: keymap.Bind(rsearchScope, "next-match", "next, older match",
+		tea.KeyCtrlR.String()),
+	cancelSearch: keymap.Bind(rsearchScope, "cancel", "cancel search",
+		tea.KeyCtrlG.String()),
+	acceptMatch: keymap.Bind(rsearchScope, "submit", "submit match",
+		tea.KeyEnter.String()),
+}
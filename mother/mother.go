@@ -20,10 +20,15 @@ import (
 	"gwcli/stylesheet"
 	"gwcli/stylesheet/colorizer"
 	ft "gwcli/stylesheet/flagtext"
+	"gwcli/utilities/alias"
+	"gwcli/utilities/cfgdir"
+	"gwcli/utilities/keymap"
 	"gwcli/utilities/killer"
 	"gwcli/utilities/uniques"
+	"maps"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/google/shlex"
 	"github.com/gravwell/gravwell/v3/ingest/log"
@@ -53,20 +58,30 @@ type Mother struct {
 
 	ti textinput.Model
 
-	active struct {
-		command *actionCmd   // command user called
-		model   action.Model // Elm Arch associated to command
-	}
+	jobs    jobTable          // actions handed off to; the foreground job, if any, is in control
+	winSize tea.WindowSizeMsg // the most recent size of the terminal, for resumed jobs
 
 	processOnStartup bool // mother should immediately consume and process her prompt on spawn
 
 	history *history
+	rsearch reverseSearch // ctrl+r history search
+
+	aliases map[string]string // user-defined aliases; name -> expansion
+
+	completion completion // inputs the prompt's suggestions were generated from
+
+	status statusLine // session context displayed above the prompt
 }
 
 // Spawn spins up a new instance of Mother in a fresh tea program, runs the
 // program, and returns on Mother's exit.
 // The caller is expected to exit on Spawn's return.
+// Returns an error without spawning if the user's keymap is invalid.
 func Spawn(root, cur *cobra.Command, trailingTokens []string) error {
+	// all bindings have been declared by now, so the user's keymap can be checked against them
+	if err := keymap.Validate(); err != nil {
+		return err
+	}
 	// spin up mother
 	interactive := tea.NewProgram(new(root, cur, trailingTokens, nil))
 	if _, err := interactive.Run(); err != nil {
@@ -106,7 +121,9 @@ func new(root *navCmd, cur *cobra.Command, trailingTokens []string, _ *lipgloss.
 		pwd:     cur,
 		mode:    prompting,
 		ti:      ti,
-		history: newHistory()}
+		history: initHistory(root),
+		aliases: initAliases(),
+		status:  statusLine{cfg: initStatusConfig()}}
 	// set mother's starting position
 	if cur == nil {
 		m.pwd = root // place mother at root
@@ -126,7 +143,7 @@ func new(root *navCmd, cur *cobra.Command, trailingTokens []string, _ *lipgloss.
 		// have mother immediate act on the data we placed on her prompt
 		m.processOnStartup = true
 	}
-	m.updateSuggestions()
+	m.updateSuggestions() // Init fetches any remaining candidates
 
 	clilog.Writer.Debugf("Spawning mother rooted @ %v, located @ %v, with trailing tokens %v",
 		m.root.Name(), m.pwd.Name(), trailingTokens)
@@ -151,12 +168,47 @@ func new(root *navCmd, cur *cobra.Command, trailingTokens []string, _ *lipgloss.
 	return m
 }
 
+// helper function for new.
+// Loads the persistent history, sized according to root's --history-size flag.
+func initHistory(root *navCmd) *history {
+	size, err := root.PersistentFlags().GetUint16("history-size")
+	if err != nil {
+		clilog.Writer.Warnf("failed to fetch history size: %v", err)
+		size = arraySize
+	}
+	h, err := loadHistory(cfgdir.DefaultHistoryPath, size)
+	if err != nil {
+		clilog.Writer.Warnf("failed to load history from %v: %v", cfgdir.DefaultHistoryPath, err)
+	}
+	return h
+}
+
+// helper function for new.
+// Loads the user's aliases.
+func initAliases() map[string]string {
+	aliases, err := alias.Load()
+	if err != nil {
+		clilog.Writer.Warnf("failed to load aliases from %v: %v", alias.Path(), err)
+	}
+	return aliases
+}
+
+// helper function for new.
+// Loads the user's status line configuration.
+func initStatusConfig() statusConfig {
+	cfg, err := loadStatusConfig()
+	if err != nil {
+		clilog.Writer.Warnf("failed to load status line configuration: %v", err)
+	}
+	return cfg
+}
+
 //#region tea.Model implementation
 
 var _ tea.Model = Mother{}
 
 func (m Mother) Init() tea.Cmd {
-	return uniques.FetchWindowSize
+	return tea.Batch(uniques.FetchWindowSize, statusTick(), m.completion.fetchCmd())
 }
 
 // Mother's Update is always the entrypoint for BubbleTea to drive.
@@ -167,11 +219,16 @@ func (m Mother) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.processOnStartup = false
 		return m, processInput(&m)
 	}
+	// kill keys cancel a reverse search, rather than killing anything
+	if m.rsearch.active && killer.CheckKillKeys(msg) != killer.None {
+		m.cancelReverseSearch()
+		return m, tea.Batch(textinput.Blink, m.refreshSuggestions())
+	}
 	switch killer.CheckKillKeys(msg) { // handle kill keys above all else
 	case killer.Global:
 		// if in handoff mode, just kill the child
 		if m.mode == handoff {
-			clilog.Writer.Infof("Global killing %v. Reasserting...", m.active.command.Name())
+			clilog.Writer.Infof("Global killing %v. Reasserting...", m.jobs.fg.command.Name())
 			m.unsetAction()
 			// if we are killing from mother, we must manually exit alt screen
 			// (harmless if not in use)
@@ -181,20 +238,52 @@ func (m Mother) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, tea.Batch(tea.Println("Bye"), tea.Quit)
 	case killer.Child: // ineffectual if not in handoff mode
 		if m.mode == handoff { // to prevent segfault, as active is nil
-			clilog.Writer.Infof("Child killing %v. Reasserting...", m.active.command.Name())
+			clilog.Writer.Infof("Child killing %v. Reasserting...", m.jobs.fg.command.Name())
 		}
 		m.unsetAction()
 		return m, tea.Batch(tea.ExitAltScreen, textinput.Blink)
 	}
+	if msg, ok := msg.(tea.KeyMsg); ok && m.mode == handoff && key.Matches(msg, keys.background) {
+		cmd := m.backgroundJob()
+		return m, cmd
+	}
+	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		m.winSize = msg
+	}
+
+	// redirected and chained actions run outside of handoff, so their completion is Mother's to handle
+	if msg, ok := msg.(redirectedMsg); ok {
+		return m, m.handleRedirected(msg)
+	}
+	if msg, ok := msg.(chainedMsg); ok {
+		cmd := m.handleChained(msg)
+		return m, cmd
+	}
+	// background jobs are polled, the status line is kept current, and fetched candidates are
+	// recorded no matter who is in control
+	switch msg := msg.(type) {
+	case jobPollMsg:
+		cmd := m.pollJobs()
+		return m, cmd
+	case statusTickMsg:
+		cmd := m.tickStatus()
+		return m, cmd
+	case statusHealthMsg:
+		m.handleHealth(msg)
+		return m, nil
+	case completionMsg:
+		m.handleCompletion(msg)
+		return m, nil
+	}
 
 	if m.mode == handoff { // a child is running
 		activeChildSanityCheck(m)
 		// test for child state
-		if !m.active.model.Done() { // child still processing
-			return m, m.active.model.Update(msg)
+		if !m.jobs.fg.model.Done() { // child still processing
+			return m, m.jobs.fg.model.Update(msg)
 		} else {
 			// child has finished processing, regain control and return to normal processing
-			clilog.Writer.Infof("%v done. Reasserting...", m.active.command.Name())
+			clilog.Writer.Infof("%v done. Reasserting...", m.jobs.fg.command.Name())
 			m.unsetAction()
 			return m, textinput.Blink
 		}
@@ -209,20 +298,31 @@ func (m Mother) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			3 // include a padding
 	case tea.KeyMsg:
 		// NOTE kill keys are handled above
-		if msg.Type == tea.KeyF1 { // help
+		if m.rsearch.active {
+			if cmd, consumed := m.updateReverseSearch(msg); consumed {
+				// the search may have placed a record on the prompt
+				return m, tea.Batch(cmd, m.refreshSuggestions())
+			}
+			// the key ended the search; handle it against the accepted match
+		}
+		if key.Matches(msg, keys.reverseSearch) {
+			m.startReverseSearch()
+			return m, nil
+		}
+		if key.Matches(msg, keys.help) {
 			return m, contextHelp(&m, strings.Split(strings.TrimSpace(m.ti.Value()), " "))
 		}
-		if msg.Type == tea.KeyUp { // history
+		if key.Matches(msg, keys.historyOlder) {
 			m.ti.SetValue(m.history.getOlderRecord())
 			// update cursor position
 			m.ti.CursorEnd()
 		}
-		if msg.Type == tea.KeyDown { // history
+		if key.Matches(msg, keys.historyNewer) {
 			m.ti.SetValue(m.history.getNewerRecord())
 			// update cursor position
 			m.ti.CursorEnd()
 		}
-		if msg.Type == tea.KeyEnter { // submit
+		if key.Matches(msg, keys.submit) {
 			m.history.unsetFetch()
 			cmd := processInput(&m)
 			return m, cmd
@@ -232,24 +332,24 @@ func (m Mother) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	m.ti, cmd = m.ti.Update(msg)
 
-	return m, cmd
+	return m, tea.Batch(cmd, m.refreshSuggestions())
 }
 
 // helper function for m.Update.
 // Validates that mother's active states have not become corrupted by a bug elsewhere in the code.
 // Panics if it detects an error
 func activeChildSanityCheck(m Mother) {
-	if m.active.model == nil || m.active.command == nil {
+	if m.jobs.fg == nil || m.jobs.fg.model == nil || m.jobs.fg.command == nil {
 		clilog.Writer.Warnf(
-			"Mother is in handoff mode but has inconsistent actives %#v",
-			m.active)
-		if m.active.command == nil {
+			"Mother is in handoff mode but has an inconsistent foreground job %#v",
+			m.jobs.fg)
+		if m.jobs.fg == nil || m.jobs.fg.command == nil {
 			clilog.Writer.Warnf("nil command, unable to recover. Dying...")
 			panic("inconsistent handoff mode. Please submit a bug report.")
 		}
-		// m.active.model == nil, !m.active.command
+		// m.jobs.fg.model == nil, !m.jobs.fg.command
 		var err error
-		m.active.model, err = action.GetModel(m.active.command)
+		m.jobs.fg.model, err = action.GetModel(m.jobs.fg.command)
 		if err != nil {
 			clilog.Writer.Errorf("failed to recover model from command: %v", err)
 			panic("inconsistent handoff mode. Please submit a bug report. ")
@@ -259,11 +359,21 @@ func activeChildSanityCheck(m Mother) {
 
 func (m Mother) View() string {
 	// allow child command to retain control if it exists
-	if m.active.model != nil {
-		return m.active.model.View()
+	if m.jobs.fg != nil {
+		return m.jobs.fg.model.View()
 	}
-	return fmt.Sprintf("%s%v\n",
-		CommandPath(&m), m.ti.View())
+	var view string
+	if status := m.statusLineView(); status != "" {
+		view = status + "\n"
+	}
+	if m.rsearch.active {
+		return view + m.jobsIndicator() + CommandPath(&m) + " " + m.viewReverseSearch()
+	}
+	view += fmt.Sprintf("%s%s%v\n", m.jobsIndicator(), CommandPath(&m), m.ti.View())
+	if desc := m.suggestionDescription(); desc != "" {
+		view += stylesheet.GreyedOutStyle.Render(desc) + "\n"
+	}
+	return view
 }
 
 //#endregion
@@ -288,6 +398,21 @@ func processInput(m *Mother) tea.Cmd {
 		return nil
 	}
 
+	// expand aliases, which are rooted, and run chained commands as a script
+	links, err := expandChain(m.pwd, strings.TrimSpace(input), m.aliases)
+	if err != nil {
+		return tea.Sequence(historyCmd, tea.Println(stylesheet.ErrStyle.Render(err.Error())))
+	} else if len(links) > 1 {
+		return tea.Sequence(historyCmd, runChain(m.pwd, links, maps.Clone(m.aliases)))
+	}
+	input = links[0].command
+
+	// split off any redirection of the output
+	input, redir, err := splitRedirectFrom(m.pwd, input)
+	if err != nil {
+		return tea.Sequence(historyCmd, tea.Println(stylesheet.ErrStyle.Render(err.Error())))
+	}
+
 	// tokenize input
 	given := strings.Split(strings.TrimSpace(input), " ")
 
@@ -299,15 +424,24 @@ func processInput(m *Mother) tea.Cmd {
 		)
 	}
 
+	if redir.kind != noRedirect && wr.status != foundAction {
+		return tea.Sequence(historyCmd, tea.Println(stylesheet.ErrStyle.Render(
+			"only the output of actions can be redirected")))
+	}
+
 	// split on action or nav
 	switch wr.status {
 	case foundBuiltin:
-		return tea.Sequence(historyCmd, wr.builtinFunc(m, given[1:]))
+		// the builtin may not lead the input (ex: if it was reached via an alias)
+		var args []string
+		if wr.remainingString != "" {
+			args = strings.Split(wr.remainingString, " ")
+		}
+		return tea.Sequence(historyCmd, wr.builtinFunc(m, args))
 	case foundNav:
 		m.pwd = wr.endCommand // move mother to target directory
 		// update her suggestions
-		m.updateSuggestions()
-		return historyCmd
+		return tea.Batch(historyCmd, m.updateSuggestions())
 	case foundAction:
 		// check for -h and confirm -h is not nested with a different, long flag (ex: -history)
 		if _, after, found := strings.Cut(wr.remainingString, "-h"); found &&
@@ -316,6 +450,10 @@ func processInput(m *Mother) tea.Cmd {
 			return tea.Sequence(historyCmd, builtins["help"](m, given))
 		}
 
+		if redir.kind != noRedirect {
+			return tea.Sequence(historyCmd, runRedirected(wr.endCommand, wr.remainingString, redir))
+		}
+
 		// reconstitute remaining tokens to re-split them via shlex
 		cmd := processActionHandoff(m, wr.endCommand, wr.remainingString)
 		return tea.Sequence(historyCmd, cmd)
@@ -355,6 +493,13 @@ func (m *Mother) promptString() string {
 // These commands are either commands the action wants run to setup or an error print if an error
 // occurred
 func processActionHandoff(m *Mother, actionCmd *cobra.Command, remString string) tea.Cmd {
+	// actions are singletons, so an action cannot be run while it is a background job
+	if _, j := m.jobs.find(func(j *job) bool { return j.command == actionCmd }); j != nil {
+		return tea.Println(stylesheet.ErrStyle.Render(fmt.Sprintf(
+			"%v is already job %d; resume it with 'fg %d' or end it with 'kill %d'",
+			actionCmd.Name(), j.id, j.id, j.id)))
+	}
+
 	m.mode = handoff
 
 	// split remaining tokens
@@ -364,25 +509,26 @@ func processActionHandoff(m *Mother, actionCmd *cobra.Command, remString string)
 	}
 
 	// look up the subroutines to load
-	m.active.model, _ = action.GetModel(actionCmd) // save add-on subroutines
-	if m.active.model == nil {                     // undo and return
+	m.jobs.fg = &job{command: actionCmd,
+		line: strings.TrimSpace(actionCmd.Name() + " " + remString)}
+	m.jobs.fg.model, _ = action.GetModel(actionCmd) // save add-on subroutines
+	if m.jobs.fg.model == nil {                     // undo and return
 		m.unsetAction()
 		str := fmt.Sprintf("Did not find actor associated to '%s'.", actionCmd.Name())
 		clilog.Writer.Warnf(str+" %#v", actionCmd)
 		return tea.Printf("Developer error: %v. Please submit a bug report.\n", str)
 	}
-	m.active.command = actionCmd
 
 	// don't bother visiting if it won't be printed
 	if clilog.Writer.GetLevel() == log.DEBUG {
 		var fStr strings.Builder
-		m.active.command.InheritedFlags().Visit(func(f *pflag.Flag) {
+		m.jobs.fg.command.InheritedFlags().Visit(func(f *pflag.Flag) {
 			fStr.WriteString(fmt.Sprintf("%s - %s", f.Name, f.Value))
 		})
 		clilog.Writer.Debugf("Passing args (%v) and inherited flags (%#v) into %s\n",
 			remString,
 			fStr.String(),
-			m.active.command.Name())
+			m.jobs.fg.command.Name())
 	}
 
 	// NOTE: the inherited flags here may have a combination of parsed and !parsed flags
@@ -392,21 +538,21 @@ func processActionHandoff(m *Mother, actionCmd *cobra.Command, remString string)
 		invalid string
 		cmd     tea.Cmd
 	)
-	if invalid, cmd, err = m.active.model.SetArgs(
-		m.active.command.InheritedFlags(), args,
+	if invalid, cmd, err = m.jobs.fg.model.SetArgs(
+		m.jobs.fg.command.InheritedFlags(), args,
 	); err != nil || invalid != "" { // undo and return
 		m.unsetAction()
 
 		if err != nil {
 			errString := fmt.Sprintf("Failed to set args %v: %v", remString, err)
 			clilog.Writer.Errorf("%v\nactive model %v\nactive command%v",
-				errString, m.active.model, remString)
+				errString, m.jobs.fg.model, remString)
 			return tea.Println(errString)
 		}
 		return tea.Println("invalid arguments: " + invalid + "\n" +
 			"See " + stylesheet.ExampleStyle.Render("help") + " (or append -h) for assistance.")
 	}
-	clilog.Writer.Debugf("Handing off control to %s", m.active.command.Name())
+	clilog.Writer.Debugf("Handing off control to %s", m.jobs.fg.command.Name())
 	if cmd != nil {
 		return cmd
 	}
@@ -455,64 +601,15 @@ func quoteSplitTokens(oldTokens []string) (strippedTokens []string) {
 	return
 }
 
-// Call *after* moving to update the current command suggestions
-func (m *Mother) updateSuggestions() {
-	var suggest []string = make([]string, len(builtins))
-	// add builtins
-	var i int = 0
-	for k := range builtins {
-		suggest[i] = k
-		i++
-	}
-
-	// recursively add children of current command
-	children := m.pwd.Commands()
-	for _, c := range children {
-		// dive into navs
-		if c.GroupID == group.NavID {
-			suggest = append(suggest, plumbCommand(c)...)
-		} else {
-			suggest = append(suggest, c.Name())
-		}
-	}
-
-	m.ti.SetSuggestions(suggest)
-}
-
-// helper subroutine for updateSuggestions().
-// Recursively searches down the given nav, returning all actions (at any depth), rooted at the
-// given nav.
-//
-// Drives the suggestions of mother's prompt.
-//
-// Very similar to the tree action at root.
-func plumbCommand(nav *navCmd) []string {
-	self := nav.Name()
-	var suggests []string = []string{self}
-	for _, child := range nav.Commands() {
-		switch child.GroupID {
-		case group.NavID:
-			subchildren := plumbCommand(child)
-			for _, sc := range subchildren {
-				suggests = append(suggests, self+" "+sc)
-			}
-		default: // actions end here
-			suggests = append(suggests, self+" "+child.Name())
-		}
-	}
-	return suggests
-}
-
 // unsetAction resets the current active command/action, clears actives, and returns control to
 // Mother.
 func (m *Mother) unsetAction() {
-	if m.active.model != nil {
-		m.active.model.Reset()
+	if m.jobs.fg != nil && m.jobs.fg.model != nil {
+		m.jobs.fg.model.Reset()
 	}
 
 	m.mode = prompting
-	m.active.model = nil
-	m.active.command = nil
+	m.jobs.fg = nil
 }
 
 //#region static helper functions
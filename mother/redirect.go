@@ -0,0 +1,219 @@
+package mother
+
+/*
+Redirection sends the output of an action to a file (`>`, `>>`) or a shell command (`|`) instead of
+Mother's terminal, as a shell would:
+
+	macros list --json > macros.json
+	kits list --csv >> kits.csv
+	user myinfo | grep -i email
+
+Redirected actions are run in script mode via their cobra.Command (see runScripted), so their output
+can be captured. Only their standard output is redirected; their errors are printed as usual.
+Styling is stripped from the redirected output.
+
+Operators must be whitespace-delimited and unquoted; quote any argument containing a lone `|` or `>`.
+The arguments of actions marked with RawArgsAnnotation (such as query, whose text has its own
+pipeline and comparisons) are never redirected; such actions provide their own output flags.
+*/
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"gwcli/clilog"
+	"gwcli/stylesheet"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/google/shlex"
+	"github.com/spf13/cobra"
+)
+
+const redirectFilePerm = 0644
+
+// RawArgsAnnotation marks actions whose arguments are taken verbatim (ex: query text, whose
+// pipeline and comparisons use `|` and `>`), so Mother does not interpret redirection or chaining
+// operators among them.
+// Set it as a key of the action's cobra.Command.Annotations.
+const RawArgsAnnotation = "gwcli_rawargs"
+
+type redirectKind int
+
+const (
+	noRedirect redirectKind = iota
+	toFile                  // >
+	appendFile              // >>
+	toPipe                  // |
+)
+
+// redirect is the destination of an action's output.
+type redirect struct {
+	kind   redirectKind
+	target string // file path or shell command
+}
+
+// redirectedMsg is returned once a redirected action (or the command it was piped to) completes.
+type redirectedMsg struct {
+	stderr string    // the action's error output, printed as usual
+	pipe   *exec.Cmd // shell command to feed the output to; nil if not piped
+	err    error
+}
+
+// Splits the redirection operator, if there is one, and its target off of the given input.
+// A pipe's target is the remainder of the input, which is left to the shell to interpret.
+func splitRedirect(input string) (command string, r redirect, err error) {
+	var (
+		quote      rune // the quote character we are within, if any
+		tokenStart = true
+	)
+	for i, c := range input {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ' ' || c == '\t':
+			tokenStart = true
+			continue
+		case tokenStart:
+			end := strings.IndexAny(input[i:], " \t")
+			if end == -1 {
+				end = len(input) - i
+			}
+			op := input[i : i+end]
+			var kind redirectKind
+			switch op {
+			case "|":
+				kind = toPipe
+			case ">":
+				kind = toFile
+			case ">>":
+				kind = appendFile
+			}
+			if kind == noRedirect {
+				break
+			}
+			command, r = strings.TrimSpace(input[:i]), redirect{kind: kind}
+			target := strings.TrimSpace(input[i+end:])
+			if kind == toPipe {
+				if target == "" {
+					return command, r, errors.New("expected a command after '|'")
+				}
+				r.target = target
+				return command, r, nil
+			}
+			paths, err := shlex.Split(target)
+			if err != nil {
+				return command, r, err
+			} else if len(paths) != 1 {
+				return command, r, fmt.Errorf("expected a single file after '%v'", op)
+			}
+			r.target = paths[0]
+			return command, r, nil
+		}
+		tokenStart = false
+	}
+	return input, redirect{}, nil
+}
+
+// Returns whether the action takes its arguments verbatim (see RawArgsAnnotation).
+func rawArgs(act *cobra.Command) bool {
+	_, ok := act.Annotations[RawArgsAnnotation]
+	return ok
+}
+
+// Splits off the input's redirection as splitRedirect does, unless the input (walked from dir)
+// invokes an action that takes its arguments verbatim.
+func splitRedirectFrom(dir *navCmd, input string) (command string, r redirect, err error) {
+	wr := walk(dir, strings.Split(strings.TrimSpace(input), " "))
+	if wr.status == foundAction && rawArgs(wr.endCommand) {
+		return input, redirect{}, nil
+	}
+	return splitRedirect(input)
+}
+
+// Returns a command that runs the action in script mode, sending its output to the redirect.
+func runRedirected(act *cobra.Command, remString string, r redirect) tea.Cmd {
+	return func() tea.Msg {
+		args, err := shlex.Split(remString)
+		if err != nil {
+			return redirectedMsg{err: err}
+		}
+		var stdout, stderr bytes.Buffer
+		if err := runScripted(act, args, &stdout, &stderr); err != nil {
+			return redirectedMsg{stderr: stderr.String(), err: err}
+		}
+		out := ansi.Strip(stdout.String())
+
+		if r.kind == toPipe {
+			c := shellCommand(r.target)
+			c.Stdin = strings.NewReader(out)
+			return redirectedMsg{stderr: stderr.String(), pipe: c}
+		}
+		return redirectedMsg{stderr: stderr.String(), err: writeRedirect(r, out)}
+	}
+}
+
+// Writes the output to the redirect's file, truncating or appending per its kind.
+func writeRedirect(r redirect, out string) error {
+	path := r.target
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+		path = filepath.Join(home, rest)
+	}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if r.kind == appendFile {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	f, err := os.OpenFile(path, flags, redirectFilePerm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(out); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Returns the given command line, to be run by the system shell.
+func shellCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
+
+// Prints the redirected action's errors and, if its output was piped, hands the terminal to the
+// shell command until it exits.
+func (m *Mother) handleRedirected(msg redirectedMsg) tea.Cmd {
+	var cmds []tea.Cmd
+	if stderr := strings.TrimSpace(msg.stderr); stderr != "" {
+		cmds = append(cmds, tea.Println(stderr))
+	}
+	if msg.err != nil {
+		clilog.Writer.Infof("redirection failed: %v", msg.err)
+		cmds = append(cmds, tea.Println(stylesheet.ErrStyle.Render(msg.err.Error())))
+	}
+	if msg.pipe != nil {
+		command := strings.Join(msg.pipe.Args[2:], " ")
+		cmds = append(cmds, tea.ExecProcess(msg.pipe, func(err error) tea.Msg {
+			if err != nil {
+				return redirectedMsg{err: fmt.Errorf("%v: %w", command, err)}
+			}
+			return nil
+		}))
+	}
+	return tea.Sequence(cmds...)
+}
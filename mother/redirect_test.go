@@ -0,0 +1,141 @@
+package mother
+
+import (
+	"bytes"
+	"fmt"
+	"gwcli/clilog"
+	"gwcli/group"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func Test_splitRedirect(t *testing.T) {
+	tests := []struct {
+		input       string
+		wantCommand string
+		wantRedir   redirect
+		wantErr     bool
+	}{
+		{"macros list --json", "macros list --json", redirect{}, false},
+		{"macros list --json > macros.json", "macros list --json", redirect{toFile, "macros.json"}, false},
+		{"kits list >> 'my kits.csv'", "kits list", redirect{appendFile, "my kits.csv"}, false},
+		{"user myinfo | grep -i email | wc -l", "user myinfo", redirect{toPipe, "grep -i email | wc -l"}, false},
+		{"user myinfo | grep x > out", "user myinfo", redirect{toPipe, "grep x > out"}, false},
+		{`query "tag=gravwell | table" > out.txt`, `query "tag=gravwell | table"`, redirect{toFile, "out.txt"}, false},
+		{"query tag=x ax count>5", "query tag=x ax count>5", redirect{}, false},
+		{"macros list >", "macros list", redirect{kind: toFile}, true},
+		{"macros list > a b", "macros list", redirect{kind: toFile}, true},
+		{"macros list |", "macros list", redirect{kind: toPipe}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			command, r, err := splitRedirect(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitRedirect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if command != tt.wantCommand || r != tt.wantRedir {
+				t.Errorf("splitRedirect() = (%q, %+v), want (%q, %+v)",
+					command, r, tt.wantCommand, tt.wantRedir)
+			}
+		})
+	}
+}
+
+func Test_splitRedirectFrom(t *testing.T) {
+	root := scriptTree()
+	tests := []struct {
+		input       string
+		wantCommand string
+		wantRedir   redirect
+	}{
+		{"query tag=syslog syslog Host | count by Host | table",
+			"query tag=syslog syslog Host | count by Host | table", redirect{}},
+		{"query tag=x eval v > 5 | table", "query tag=x eval v > 5 | table", redirect{}},
+		{"query tag=x >> out.txt", "query tag=x >> out.txt", redirect{}},
+		{"tools echo a | wc -l", "tools echo a", redirect{toPipe, "wc -l"}},
+		{"tools echo a > out.txt", "tools echo a", redirect{toFile, "out.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			command, r, err := splitRedirectFrom(root, tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if command != tt.wantCommand || r != tt.wantRedir {
+				t.Errorf("splitRedirectFrom() = (%q, %+v), want (%q, %+v)",
+					command, r, tt.wantCommand, tt.wantRedir)
+			}
+		})
+	}
+}
+
+func Test_runScripted(t *testing.T) {
+	if err := clilog.Init(t.TempDir()+"/log.txt", "DEBUG"); err != nil {
+		t.Fatal(err)
+	}
+	root := &cobra.Command{Use: "root", GroupID: group.NavID}
+	root.PersistentFlags().Bool("script", false, "")
+	act := &cobra.Command{Use: "echo", GroupID: group.ActionID,
+		Run: func(c *cobra.Command, args []string) {
+			script, _ := c.Flags().GetBool("script")
+			upper, _ := c.Flags().GetBool("upper")
+			cols, _ := c.Flags().GetStringSlice("columns")
+			fmt.Fprintf(c.OutOrStdout(), "%v %v %v %v", script, upper, cols, args)
+			fmt.Fprint(c.ErrOrStderr(), "warning")
+		}}
+	act.Flags().Bool("upper", false, "")
+	act.Flags().StringSlice("columns", nil, "")
+	root.AddCommand(act)
+
+	var stdout, stderr bytes.Buffer
+	if err := runScripted(act, []string{"--upper", "--columns", "a,b", "x"}, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	if want := "true true [a b] [x]"; stdout.String() != want {
+		t.Errorf("stdout = %q, want %q", stdout.String(), want)
+	}
+	if stderr.String() != "warning" {
+		t.Errorf("stderr = %q, want %q", stderr.String(), "warning")
+	}
+
+	// flags must not bleed into the next run
+	stdout.Reset()
+	if err := runScripted(act, nil, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	if want := "true false [] []"; stdout.String() != want {
+		t.Errorf("second run stdout = %q, want %q", stdout.String(), want)
+	}
+	if script, _ := root.PersistentFlags().GetBool("script"); script {
+		t.Error("--script of the shared command was altered")
+	}
+	if upper, _ := act.Flags().GetBool("upper"); upper || act.Flags().Changed("upper") {
+		t.Error("--upper of the shared command was altered")
+	}
+
+	if err := runScripted(act, []string{"--bogus"}, &stdout, &stderr); err == nil {
+		t.Error("expected an error for an unknown flag")
+	}
+}
+
+func Test_writeRedirect(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.txt")
+	if err := writeRedirect(redirect{toFile, path}, "first\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeRedirect(redirect{appendFile, path}, "second\n"); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(path); string(b) != "first\nsecond\n" {
+		t.Errorf("after append, file = %q", b)
+	}
+	if err := writeRedirect(redirect{toFile, path}, "third\n"); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(path); string(b) != "third\n" {
+		t.Errorf("after truncate, file = %q", b)
+	}
+}
@@ -0,0 +1,114 @@
+package mother
+
+/*
+Reverse search provides bash-style, reverse incremental search (ctrl+r, by default) over Mother's
+history.
+
+While searching, typed characters refine the query and each ctrl+r steps to the next-oldest match.
+Enter submits the match (restoring the original prompt if nothing matched), the kill keys and
+ctrl+g restore the original prompt, and, as in readline,
+any other key accepts the match onto the prompt (leaving the prompt be if nothing matched) and then
+acts upon the prompt as it normally would (ex: ctrl+a moves to the start of the accepted match).
+(All of these keys can be rebound; see keys.go.)
+*/
+
+import (
+	"fmt"
+	"gwcli/stylesheet"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type reverseSearch struct {
+	active     bool
+	query      string
+	match      string // current match; empty if none
+	matchIndex int    // index of the current match in the history (0 = newest); -1 if none
+	original   string // prompt text prior to the search, restored on cancel
+}
+
+// Enters reverse search mode, saving the current prompt.
+func (m *Mother) startReverseSearch() {
+	m.rsearch = reverseSearch{active: true, matchIndex: -1, original: m.ti.Value()}
+}
+
+// Leaves reverse search mode, placing the given string on the prompt.
+func (m *Mother) endReverseSearch(prompt string) {
+	m.rsearch.active = false
+	m.ti.SetValue(prompt)
+	m.ti.CursorEnd()
+	m.history.unsetFetch()
+}
+
+// Leaves reverse search mode, restoring the prompt as it was prior to searching.
+func (m *Mother) cancelReverseSearch() {
+	m.endReverseSearch(m.rsearch.original)
+}
+
+// Handles key input while reverse searching.
+// Returns false if the key ended the search without being consumed, in which case the caller
+// should handle the key as normal prompt input.
+func (m *Mother) updateReverseSearch(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch {
+	case key.Matches(msg, keys.nextMatch):
+		if m.rsearch.query == "" {
+			return nil, true
+		}
+		if rec, idx := m.history.searchOlder(m.rsearch.query, m.rsearch.matchIndex); idx != -1 {
+			m.rsearch.match, m.rsearch.matchIndex = rec, idx
+		}
+		return nil, true
+	case key.Matches(msg, keys.cancelSearch):
+		m.cancelReverseSearch()
+		return textinput.Blink, true
+	case key.Matches(msg, keys.acceptMatch):
+		if m.rsearch.matchIndex == -1 { // nothing to submit
+			m.cancelReverseSearch()
+			return textinput.Blink, true
+		}
+		m.endReverseSearch(m.rsearch.match)
+		return processInput(m), true
+	case msg.Type == tea.KeyBackspace:
+		if r := []rune(m.rsearch.query); len(r) > 0 {
+			m.rsearch.query = string(r[:len(r)-1])
+			m.refreshReverseSearch()
+		}
+		return nil, true
+	case msg.Type == tea.KeyRunes:
+		m.rsearch.query += string(msg.Runes)
+		m.refreshReverseSearch()
+		return nil, true
+	case msg.Type == tea.KeySpace:
+		m.rsearch.query += " "
+		m.refreshReverseSearch()
+		return nil, true
+	}
+	// any other key accepts the match for editing and is then handled as usual
+	if m.rsearch.matchIndex == -1 {
+		m.cancelReverseSearch()
+	} else {
+		m.endReverseSearch(m.rsearch.match)
+	}
+	return nil, false
+}
+
+// Re-runs the search from the newest record, as the query has changed.
+func (m *Mother) refreshReverseSearch() {
+	if m.rsearch.query == "" {
+		m.rsearch.match, m.rsearch.matchIndex = "", -1
+		return
+	}
+	m.rsearch.match, m.rsearch.matchIndex = m.history.searchOlder(m.rsearch.query, -1)
+}
+
+// Displays the search in place of Mother's prompt.
+func (m *Mother) viewReverseSearch() string {
+	head := "(reverse-i-search)"
+	if m.rsearch.query != "" && m.rsearch.matchIndex == -1 {
+		head = "(failed reverse-i-search)"
+	}
+	return fmt.Sprintf("%s`%s': %s\n",
+		stylesheet.PromptStyle.Render(head), m.rsearch.query, m.rsearch.match)
+}
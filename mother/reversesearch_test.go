@@ -0,0 +1,61 @@
+package mother
+
+import (
+	"gwcli/clilog"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+func TestReverseSearchEndingKey(t *testing.T) {
+	if err := clilog.Init(t.TempDir()+"/log.txt", "DEBUG"); err != nil {
+		t.Fatal(err)
+	}
+	root := &cobra.Command{Use: "root"}
+	newMother := func() Mother {
+		m := Mother{mode: prompting, root: root, pwd: root, history: newHistory(), ti: textinput.New()}
+		m.ti.Focus()
+		m.history.insert("kits list")
+		m.history.insert("macros list")
+		return m
+	}
+	search := func(m Mother, query string) Mother {
+		m.ti.SetValue("draft")
+		m.startReverseSearch()
+		for _, r := range query {
+			tm, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+			m = tm.(Mother)
+		}
+		return m
+	}
+	tests := []struct {
+		name       string
+		query      string
+		key        tea.KeyMsg
+		wantValue  string
+		wantCursor int
+	}{
+		{"ctrl+a moves to the start of the match", "kit", tea.KeyMsg{Type: tea.KeyCtrlA}, "kits list", 0},
+		{"left moves within the match", "mac", tea.KeyMsg{Type: tea.KeyLeft}, "macros list", 10},
+		{"no match leaves the prompt be", "bogus", tea.KeyMsg{Type: tea.KeyCtrlA}, "draft", 0},
+		{"enter without a match keeps the prompt", "bogus", tea.KeyMsg{Type: tea.KeyEnter}, "draft", 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := search(newMother(), tt.query)
+			tm, _ := m.Update(tt.key)
+			m = tm.(Mother)
+			if m.rsearch.active {
+				t.Fatal("the key did not end the search")
+			}
+			if got := m.ti.Value(); got != tt.wantValue {
+				t.Errorf("prompt = %q, want %q", got, tt.wantValue)
+			}
+			if got := m.ti.Position(); got != tt.wantCursor {
+				t.Errorf("cursor = %d, want %d (the key was not handled)", got, tt.wantCursor)
+			}
+		})
+	}
+}
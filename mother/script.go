@@ -0,0 +1,266 @@
+package mother
+
+/*
+Scripts are files of Mother commands, executed line by line by `gwcli run <file>` and Mother's
+`source` builtin, to automate multi-step workflows:
+
+	# create a macro and confirm it exists
+	set NAME=ERRORS
+	macros create -n ${NAME} -e "tag=syslog grep error" -d "syslog errors"
+	macros
+	list --json > macros.json
+
+Blank lines and lines beginning with # are ignored.
+`set VAR=value` defines a variable (surrounding quotes are stripped from the value) and `${VAR}`
+interpolates one into any subsequent line. Script variables shadow environment variables;
+referencing a variable that is neither is an error.
+
+Lines are walked from the script's current location, starting where the script was invoked, so a
+line consisting only of a nav moves the script, just as it moves Mother. Aliases, redirection, and
+chaining (see chain.go) are supported; a chain's status is that of the last command it executed.
+Actions are run in script mode via their cobra.Command (see runScripted). An action with a RunE
+fails if it returns an error; a Run-only action, which cannot return one, fails if it writes to
+stderr.
+`exit` (or `quit`) ends the script early; other builtins are not supported.
+
+The status of each command is reported on the script's stderr as it completes. Execution stops at
+the first failure unless ContinueOnError is set.
+*/
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"gwcli/clilog"
+	"gwcli/utilities/alias"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/google/shlex"
+	"github.com/spf13/cobra"
+)
+
+// NonScriptableAnnotation marks actions that cannot be run by a script or redirected, such as those
+// that run scripts themselves (a script-running action, such as run, would recurse off of Mother's
+// Update loop if run within a chain or redirect).
+// Set it as a key of the action's cobra.Command.Annotations.
+const NonScriptableAnnotation = "gwcli_nonscriptable"
+
+// ScriptOptions alters how RunScript executes a script.
+type ScriptOptions struct {
+	ContinueOnError bool      // execute the remaining lines after a line fails
+	Stdout          io.Writer // receives the output of each action
+	Stderr          io.Writer // receives the errors of each action and the status of each line
+}
+
+var (
+	varRefRgx  = regexp.MustCompile(`\$\{([^}]*)\}`)
+	varNameRgx = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+	errScriptExit = errors.New("exit") // the script called exit; not a failure
+)
+
+// script is the state of an executing script.
+type script struct {
+	name    string // identifies the script in status reports
+	pwd     *navCmd
+	vars    map[string]string
+	aliases map[string]string
+	opts    ScriptOptions
+}
+
+// RunScript executes the script read from r, starting from the given nav.
+// The name identifies the script in status reports (ex: its path).
+// Returns the number of lines that failed and any error reading the script.
+func RunScript(pwd *cobra.Command, name string, r io.Reader, opts ScriptOptions,
+) (failed int, err error) {
+	aliases, err := alias.Load()
+	if err != nil {
+		clilog.Writer.Warnf("failed to load aliases: %v", err)
+	}
+	s := newScript(name, pwd, aliases, opts)
+
+	var commands int
+	sc := bufio.NewScanner(r)
+	for lineNum := 1; sc.Scan(); lineNum++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		isCommand, err := s.execute(line)
+		if errors.Is(err, errScriptExit) {
+			break
+		}
+		if isCommand {
+			commands++
+		}
+		if err == nil {
+			if isCommand {
+				fmt.Fprintf(opts.Stderr, "%v:%d: ok: %v\n", name, lineNum, line)
+			}
+			continue
+		}
+		failed++
+		clilog.Writer.Infof("%v:%d: '%v' failed: %v", name, lineNum, line, err)
+		fmt.Fprintf(opts.Stderr, "%v:%d: failed: %v: %v\n", name, lineNum, line, err)
+		if !opts.ContinueOnError {
+			fmt.Fprintf(opts.Stderr,
+				"%v: stopped at line %d (--continue-on-error executes the remaining lines)\n",
+				name, lineNum)
+			return failed, nil
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return failed, err
+	}
+	fmt.Fprintf(opts.Stderr, "%v: %d commands, %d failed\n", name, commands, failed)
+	return failed, nil
+}
+
+func newScript(name string, pwd *navCmd, aliases map[string]string, opts ScriptOptions) *script {
+	return &script{name: name, pwd: pwd, vars: make(map[string]string), aliases: aliases, opts: opts}
+}
+
+// Executes a single, non-comment line.
+// Returns whether the line was a command (rather than a variable assignment) and, if it failed,
+// why.
+func (s *script) execute(line string) (isCommand bool, err error) {
+	if line, err = s.interpolate(line); err != nil {
+		return true, err
+	}
+	if def, ok := strings.CutPrefix(line, "set "); ok {
+		return false, s.set(def)
+	}
+
+	links, err := expandChain(s.pwd, line, s.aliases)
+	if err != nil {
+		return true, err
+	}
+	for _, l := range links {
+		if l.skipped(err != nil) {
+			continue
+		}
+		if err = s.executeCommand(l.command); errors.Is(err, errScriptExit) {
+			break
+		}
+	}
+	return true, err
+}
+
+// Executes a single command of a line, its aliases already expanded.
+func (s *script) executeCommand(command string) error {
+	command, redir, err := splitRedirectFrom(s.pwd, command)
+	if err != nil {
+		return err
+	}
+
+	wr := walk(s.pwd, strings.Split(command, " "))
+	if wr.errString != "" {
+		return errors.New(wr.errString)
+	}
+	switch wr.status {
+	case foundNav:
+		if redir.kind != noRedirect {
+			return errors.New("only the output of actions can be redirected")
+		}
+		s.pwd = wr.endCommand
+		return nil
+	case foundBuiltin:
+		if wr.builtin == "exit" || wr.builtin == "quit" {
+			return errScriptExit
+		}
+		return fmt.Errorf("'%v' is not supported in scripts", wr.builtin)
+	case foundAction:
+		return s.runAction(wr.endCommand, wr.remainingString, redir)
+	}
+	return fmt.Errorf("failed to resolve '%v'", command)
+}
+
+// Replaces each ${VAR} in the line with the value of the variable.
+func (s *script) interpolate(line string) (string, error) {
+	var err error
+	line = varRefRgx.ReplaceAllStringFunc(line, func(ref string) string {
+		name := ref[2 : len(ref)-1]
+		if v, ok := s.vars[name]; ok {
+			return v
+		}
+		if v, ok := os.LookupEnv(name); ok {
+			return v
+		}
+		if err == nil {
+			err = fmt.Errorf("undefined variable '%v'", name)
+		}
+		return ref
+	})
+	return line, err
+}
+
+// Defines the variable given in the form VAR=value.
+func (s *script) set(def string) error {
+	name, value, found := strings.Cut(def, "=")
+	name = strings.TrimSpace(name)
+	if !found || !varNameRgx.MatchString(name) {
+		return fmt.Errorf("expected 'set VAR=value', where VAR is a letter or underscore followed "+
+			"by letters, digits, or underscores; got 'set %v'", def)
+	}
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	}
+	s.vars[name] = value
+	return nil
+}
+
+// Runs the action in script mode, sending its output to the redirect (or the script's stdout).
+// Actions with a RunE succeed unless it returns an error; they may still write warnings or
+// supplementary output (such as query --stats) to stderr. Actions with only a Run have no other way
+// to report failure, so any write to stderr is treated as one.
+func (s *script) runAction(act *cobra.Command, remString string, r redirect) error {
+	args, err := shlex.Split(remString)
+	if err != nil {
+		return err
+	}
+	var (
+		stdout io.Writer = s.opts.Stdout
+		buf    bytes.Buffer
+		stderr = &trackingWriter{w: s.opts.Stderr}
+	)
+	if r.kind != noRedirect {
+		stdout = &buf
+	}
+	if err := runScripted(act, args, stdout, stderr); err != nil {
+		return err
+	} else if act.RunE == nil && stderr.written {
+		return errors.New("the action reported an error")
+	}
+
+	switch r.kind {
+	case toFile, appendFile:
+		return writeRedirect(r, ansi.Strip(buf.String()))
+	case toPipe:
+		c := shellCommand(r.target)
+		c.Stdin = strings.NewReader(ansi.Strip(buf.String()))
+		c.Stdout, c.Stderr = s.opts.Stdout, s.opts.Stderr
+		if err := c.Run(); err != nil {
+			return fmt.Errorf("%v: %w", r.target, err)
+		}
+	}
+	return nil
+}
+
+// trackingWriter records whether anything was written through it.
+type trackingWriter struct {
+	w       io.Writer
+	written bool
+}
+
+func (t *trackingWriter) Write(p []byte) (int, error) {
+	if len(p) > 0 {
+		t.written = true
+	}
+	return t.w.Write(p)
+}
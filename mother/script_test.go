@@ -0,0 +1,104 @@
+package mother
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"gwcli/clilog"
+	"gwcli/group"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// Builds a tree with a nav containing an action that echoes its arguments, one that fails, one that
+// warns but succeeds, and one that returns an error, and a query action that echoes its (verbatim)
+// arguments.
+func scriptTree() *cobra.Command {
+	root := &cobra.Command{Use: "root", GroupID: group.NavID}
+	root.PersistentFlags().Bool("script", false, "")
+	nav := &cobra.Command{Use: "tools", GroupID: group.NavID}
+	echo := &cobra.Command{Use: "echo", GroupID: group.ActionID,
+		Run: func(c *cobra.Command, args []string) {
+			fmt.Fprintln(c.OutOrStdout(), strings.Join(args, " "))
+		}}
+	fail := &cobra.Command{Use: "fail", GroupID: group.ActionID,
+		Run: func(c *cobra.Command, _ []string) {
+			fmt.Fprintln(c.ErrOrStderr(), "something broke")
+		}}
+	warn := &cobra.Command{Use: "warn", GroupID: group.ActionID,
+		RunE: func(c *cobra.Command, _ []string) error {
+			fmt.Fprintln(c.ErrOrStderr(), "a warning")
+			return nil
+		}}
+	errs := &cobra.Command{Use: "errs", GroupID: group.ActionID,
+		RunE: func(c *cobra.Command, _ []string) error {
+			return errors.New("returned an error")
+		}}
+	nav.AddCommand(echo, fail, warn, errs)
+	query := &cobra.Command{Use: "query", GroupID: group.ActionID,
+		Annotations: map[string]string{RawArgsAnnotation: ""},
+		Run: func(c *cobra.Command, args []string) {
+			fmt.Fprintln(c.OutOrStdout(), strings.Join(args, " "))
+		}}
+	root.AddCommand(nav, query)
+	return root
+}
+
+func TestRunScript(t *testing.T) {
+	if err := clilog.Init(t.TempDir()+"/log.txt", "DEBUG"); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GWCLI_TEST_ENV", "from-env")
+	dir := t.TempDir()
+
+	tests := []struct {
+		name            string
+		script          string
+		continueOnError bool
+		wantStdout      string
+		wantFailed      int
+	}{
+		{"comments and blanks", "# a comment\n\ntools echo hello\n", false, "hello\n", 0},
+		{"variables", "set WHO=\"the world\"\nset GREETING=hello ${WHO}\ntools echo ${GREETING}\n",
+			false, "hello the world\n", 0},
+		{"environment variables", "tools echo ${GWCLI_TEST_ENV}\n", false, "from-env\n", 0},
+		{"undefined variable", "tools echo ${NOPE}\ntools echo after\n", false, "", 1},
+		{"invalid set", "set 1X=y\n", false, "", 1},
+		{"navigation", "tools\necho relative\n..\ntools echo rooted\n", false, "relative\nrooted\n", 0},
+		{"stops on failure", "tools fail\ntools echo after\n", false, "", 1},
+		{"stderr of RunE is not failure", "tools warn\ntools echo after\n", false, "after\n", 0},
+		{"RunE error", "tools errs\ntools echo after\n", false, "", 1},
+		{"continue on error", "tools fail\nbogus\ntools echo after\n", true, "after\n", 2},
+		{"exit", "tools echo before\nexit\ntools echo after\n", false, "before\n", 0},
+		{"unsupported builtin", "history\n", false, "", 1},
+		{"redirect", "tools echo to file > " + filepath.Join(dir, "out.txt") + "\n", false, "", 0},
+		{"chain", "tools echo a && tools fail || tools echo c ; tools fail && tools echo d\n",
+			false, "a\nc\n", 1},
+		{"query pipeline", "query tag=syslog syslog Host | count by Host | eval count > 5 | table\n",
+			false, "tag=syslog syslog Host | count by Host | eval count > 5 | table\n", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			failed, err := RunScript(scriptTree(), "test.gw", strings.NewReader(tt.script),
+				ScriptOptions{ContinueOnError: tt.continueOnError, Stdout: &stdout, Stderr: &stderr})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if failed != tt.wantFailed {
+				t.Errorf("failed = %d, want %d (stderr: %q)", failed, tt.wantFailed, stderr.String())
+			}
+			if stdout.String() != tt.wantStdout {
+				t.Errorf("stdout = %q, want %q", stdout.String(), tt.wantStdout)
+			}
+		})
+	}
+
+	if b, err := os.ReadFile(filepath.Join(dir, "out.txt")); err != nil || string(b) != "to file\n" {
+		t.Errorf("redirected output = (%q, %v), want \"to file\\n\"", b, err)
+	}
+}
@@ -0,0 +1,143 @@
+package mother
+
+/*
+Scripted runs actions from within Mother as Cobra would run them from the shell: in script mode,
+with their output written to the given writers rather than printed to Mother's terminal.
+This allows Mother to capture an action's output (such as for redirection) without requiring
+anything of the action's interactive model.
+
+Actions are singletons that Mother may be using concurrently (for completion, an interactive
+handoff, or a background job), so scripted runs operate on a copy of the action with its own flags.
+Each run starts from the values the action's flags held prior to the run and leaves the original's
+untouched; values cannot bleed into the next invocation.
+*/
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// runScripted runs the action's Run/RunE in script mode with the given arguments, writing its
+// output to stdout and stderr.
+// Returns an error if the arguments are invalid or RunE failed.
+func runScripted(act *cobra.Command, args []string, stdout, stderr io.Writer) error {
+	if _, ok := act.Annotations[NonScriptableAnnotation]; ok {
+		return fmt.Errorf("%v cannot be run from a script or redirected", act.Name())
+	}
+	run, err := copyAction(act)
+	if err != nil {
+		return err
+	}
+	run.SetOut(stdout)
+	run.SetErr(stderr)
+
+	if err := run.ParseFlags(append([]string{"--script"}, args...)); err != nil {
+		return err
+	}
+	positional := run.Flags().Args()
+	if err := run.ValidateArgs(positional); err != nil {
+		return err
+	}
+	if err := run.ValidateRequiredFlags(); err != nil {
+		return err
+	}
+	if err := run.ValidateFlagGroups(); err != nil {
+		return err
+	}
+
+	if run.RunE != nil {
+		return run.RunE(run, positional)
+	}
+	run.Run(run, positional)
+	return nil
+}
+
+// Returns a shallow copy of the action (retaining its place in the tree) whose flags, including
+// those it inherits, are copies of the action's.
+// The action itself is only read.
+func copyAction(act *cobra.Command) (*cobra.Command, error) {
+	cp := *act
+	cp.ResetFlags()
+	if err := copyFlags(cp.Flags(), act.Flags()); err != nil {
+		return nil, err
+	}
+	if err := copyFlags(cp.Flags(), act.PersistentFlags()); err != nil {
+		return nil, err
+	}
+	var err error
+	act.VisitParents(func(p *cobra.Command) {
+		if err == nil {
+			err = copyFlags(cp.Flags(), p.PersistentFlags())
+		}
+	})
+	return &cp, err
+}
+
+// Adds a copy of each flag in src that dst does not already define, retaining its current value.
+func copyFlags(dst, src *pflag.FlagSet) error {
+	var err error
+	src.VisitAll(func(f *pflag.Flag) {
+		if err != nil || dst.Lookup(f.Name) != nil {
+			return
+		}
+		var v pflag.Value
+		if v, err = copyValue(f); err != nil {
+			return
+		}
+		cp := *f
+		cp.Value = v
+		dst.AddFlag(&cp)
+	})
+	return err
+}
+
+// Returns a new Value of the same type as the flag's, holding the flag's current value.
+func copyValue(f *pflag.Flag) (pflag.Value, error) {
+	scratch := pflag.NewFlagSet("", pflag.ContinueOnError)
+	switch f.Value.Type() {
+	case "bool":
+		scratch.Bool(f.Name, false, "")
+	case "string":
+		scratch.String(f.Name, "", "")
+	case "int":
+		scratch.Int(f.Name, 0, "")
+	case "int32":
+		scratch.Int32(f.Name, 0, "")
+	case "int64":
+		scratch.Int64(f.Name, 0, "")
+	case "uint":
+		scratch.Uint(f.Name, 0, "")
+	case "uint16":
+		scratch.Uint16(f.Name, 0, "")
+	case "uint32":
+		scratch.Uint32(f.Name, 0, "")
+	case "uint64":
+		scratch.Uint64(f.Name, 0, "")
+	case "float64":
+		scratch.Float64(f.Name, 0, "")
+	case "duration":
+		scratch.Duration(f.Name, 0, "")
+	case "stringSlice":
+		scratch.StringSlice(f.Name, nil, "")
+	case "stringArray":
+		scratch.StringArray(f.Name, nil, "")
+	case "intSlice":
+		scratch.IntSlice(f.Name, nil, "")
+	default:
+		return nil, fmt.Errorf("flag --%v (of type %v) cannot be copied for a scripted run",
+			f.Name, f.Value.Type())
+	}
+	v := scratch.Lookup(f.Name).Value
+	if sv, ok := f.Value.(pflag.SliceValue); ok {
+		// slice flags append rather than replace on Set
+		if err := v.(pflag.SliceValue).Replace(sv.GetSlice()); err != nil {
+			return nil, err
+		}
+	} else if err := v.Set(f.Value.String()); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
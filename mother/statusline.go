@@ -0,0 +1,317 @@
+package mother
+
+/*
+The status line is displayed above Mother's prompt, so the user always knows which instance they
+are acting upon (and as whom) before they act:
+
+	gravwell.example.com │ admin │ admin mode │ token 3h12m │ 42ms │ 1 search
+
+Its fields are, in their default order:
+
+  - server: the instance connected to
+  - user: the username logged in as
+  - admin: whether admin mode is enabled (displayed only if it is)
+  - token: the time remaining until the login token expires
+  - health: the latency of the most recent request to the instance or, if it failed, its error
+  - searches: the number of searches running in the background (displayed only if there are any)
+
+The `status` builtin hides the line or selects (and orders) its fields; the user's choices are saved
+to the status line file in the config directory (see cfgdir.DefaultStatusPath).
+
+The line is redrawn on every tick, so the token's countdown stays current. Health is taken from the
+requests gwcli makes anyway (see connection.LastRequest); only if none have been made is the
+instance probed. The token's expiry (which changes if the token is refreshed) is checked less often.
+*/
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gwcli/clilog"
+	"gwcli/connection"
+	"gwcli/stylesheet"
+	"gwcli/utilities/cfgdir"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	statusTickInterval = time.Second
+	healthInterval     = 30 * time.Second // how often the token's expiry (and, if need be, health) is checked
+	tokenWarning       = 5 * time.Minute  // token expiries nearer than this are highlighted
+	statusFilePerm     = 0600
+	statusSeparator    = " │ "
+)
+
+// statusFields are the fields the status line can display, in their default order.
+var statusFields = []string{"server", "user", "admin", "token", "health", "searches"}
+
+// file the status line's configuration is persisted to; a variable so tests can redirect it
+var statusPath = cfgdir.DefaultStatusPath
+
+// statusConfig is the user's configuration of the status line, as persisted.
+type statusConfig struct {
+	Hidden bool     `json:"hidden"`
+	Fields []string `json:"fields"` // in display order
+}
+
+// statusLine holds the status line's configuration and the data it displays that is costly to
+// gather.
+type statusLine struct {
+	cfg statusConfig
+
+	checking    bool      // a health check is in flight
+	checked     time.Time // when the last health check completed
+	tokenExpiry time.Time // zero if unknown
+
+	// the most recent request
+	requested time.Time     // when it completed; zero if no request has been made
+	latency   time.Duration // of the most recent request whose latency was measured
+	healthErr error
+}
+
+// statusTickMsg prompts Mother to redraw the status line and check the connection's health, if it
+// is due.
+type statusTickMsg struct{}
+
+// statusHealthMsg is returned once a health check completes.
+type statusHealthMsg struct {
+	tokenExpiry time.Time
+	probe       *connection.Request // nil if the instance was not probed
+}
+
+// Returns the status line's configuration from the status line file, or the default configuration
+// if the file does not exist.
+func loadStatusConfig() (statusConfig, error) {
+	cfg := statusConfig{Fields: slices.Clone(statusFields)}
+	b, err := os.ReadFile(statusPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return statusConfig{Fields: slices.Clone(statusFields)},
+			fmt.Errorf("failed to parse %v: %w", statusPath, err)
+	}
+	// drop fields that no longer exist
+	cfg.Fields = slices.DeleteFunc(cfg.Fields, func(f string) bool {
+		return !slices.Contains(statusFields, f)
+	})
+	return cfg, nil
+}
+
+// Writes the configuration to the status line file.
+func (cfg statusConfig) save() error {
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(statusPath, b, statusFilePerm)
+}
+
+// Parses a comma-separated list of fields.
+func parseStatusFields(list string) ([]string, error) {
+	var fields []string
+	for _, f := range strings.Split(list, ",") {
+		if f = strings.TrimSpace(f); f == "" {
+			continue
+		}
+		if !slices.Contains(statusFields, f) {
+			return nil, fmt.Errorf("unknown field '%v' (fields: %v)", f, strings.Join(statusFields, ", "))
+		}
+		if !slices.Contains(fields, f) {
+			fields = append(fields, f)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("expected a comma-separated list of fields (fields: " +
+			strings.Join(statusFields, ", ") + ")")
+	}
+	return fields, nil
+}
+
+// Returns a command that delivers a statusTickMsg after the tick interval.
+func statusTick() tea.Cmd {
+	return tea.Tick(statusTickInterval, func(time.Time) tea.Msg { return statusTickMsg{} })
+}
+
+// Handles a tick: records the most recent request, continues ticking and, if the status line is
+// displayed and its health is stale, checks the connection's health.
+func (m *Mother) tickStatus() tea.Cmd {
+	s := &m.status
+	if r := connection.LastRequest(); r.When.After(s.requested) {
+		s.record(r)
+	}
+	if s.cfg.Hidden || s.checking || time.Since(s.checked) < healthInterval {
+		return statusTick()
+	}
+	s.checking = true
+	return tea.Batch(statusTick(), checkHealth)
+}
+
+// Records the outcome of a request as the connection's health.
+func (s *statusLine) record(r connection.Request) {
+	s.requested, s.healthErr = r.When, r.Err
+	if r.Latency > 0 {
+		s.latency = r.Latency
+	}
+}
+
+// Fetches the token's expiry and, if the client has yet to make a request, tests its login, timing
+// the request.
+func checkHealth() tea.Msg {
+	if connection.Client == nil {
+		return statusHealthMsg{probe: &connection.Request{When: time.Now(), Err: errors.New("not connected")}}
+	}
+	var msg statusHealthMsg
+	if connection.LastRequest().When.IsZero() {
+		start := time.Now()
+		err := connection.Client.TestLogin()
+		msg.probe = &connection.Request{When: time.Now(), Latency: time.Since(start), Err: err}
+	}
+	if exp, err := connection.TokenExpiry(); err != nil {
+		clilog.Writer.Debugf("status line: failed to determine token expiry: %v", err)
+	} else {
+		msg.tokenExpiry = exp
+	}
+	return msg
+}
+
+// Records the result of a health check.
+func (m *Mother) handleHealth(msg statusHealthMsg) {
+	m.status.checking = false
+	m.status.checked = time.Now()
+	m.status.tokenExpiry = msg.tokenExpiry
+	if msg.probe != nil && msg.probe.When.After(m.status.requested) {
+		m.status.record(*msg.probe)
+	}
+}
+
+// Returns the status line, truncated to the width of the terminal, or the empty string if it is
+// hidden.
+func (m *Mother) statusLineView() string {
+	if m.status.cfg.Hidden {
+		return ""
+	}
+	var parts []string
+	for _, f := range m.status.cfg.Fields {
+		if v := m.statusField(f); v != "" {
+			parts = append(parts, v)
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	line := strings.Join(parts, stylesheet.GreyedOutStyle.Render(statusSeparator))
+	if m.winSize.Width > 0 {
+		line = ansi.Truncate(line, m.winSize.Width, "…")
+	}
+	return line
+}
+
+// Returns the rendered value of the given field, or the empty string if it has nothing to display.
+func (m *Mother) statusField(field string) string {
+	grey := stylesheet.GreyedOutStyle.Render
+	switch field {
+	case "server":
+		if connection.Client != nil {
+			return grey(connection.Client.Server())
+		}
+	case "user":
+		if connection.MyInfo.User != "" {
+			return grey(connection.MyInfo.User)
+		}
+	case "admin":
+		if connection.Client != nil && connection.Client.AdminMode() {
+			return stylesheet.ErrStyle.Render("admin mode")
+		}
+	case "token":
+		if m.status.tokenExpiry.IsZero() {
+			return ""
+		}
+		remaining := time.Until(m.status.tokenExpiry)
+		if remaining <= 0 {
+			return stylesheet.ErrStyle.Render("token expired")
+		} else if remaining < tokenWarning {
+			return stylesheet.ErrStyle.Render("token " + formatCountdown(remaining))
+		}
+		return grey("token " + formatCountdown(remaining))
+	case "health":
+		if m.status.requested.IsZero() {
+			return ""
+		} else if m.status.healthErr != nil {
+			return stylesheet.ErrStyle.Render(m.status.healthErr.Error())
+		} else if m.status.latency == 0 { // no request's latency has been measured
+			return grey("ok")
+		}
+		return grey(fmt.Sprintf("%dms", m.status.latency.Milliseconds()))
+	case "searches":
+		var n int
+		for _, j := range m.jobs.bg {
+			if j.status() == "running" {
+				n++
+			}
+		}
+		switch n {
+		case 0:
+			return ""
+		case 1:
+			return grey("1 search")
+		default:
+			return grey(fmt.Sprintf("%d searches", n))
+		}
+	}
+	return ""
+}
+
+// Formats the duration as a countdown, to the minute if it exceeds an hour, otherwise to the
+// second.
+func formatCountdown(d time.Duration) string {
+	if d >= time.Hour {
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+// Shows, hides, or configures the status line (`status on|off`, `status fields <f1,f2,...>`) or
+// displays its configuration (`status`).
+func configureStatus(m *Mother, args []string) tea.Cmd {
+	tokens := strings.Fields(strings.Join(args, " "))
+	cfg := m.status.cfg
+	switch {
+	case len(tokens) == 0:
+		shown := "shown"
+		if cfg.Hidden {
+			shown = "hidden"
+		}
+		return tea.Println(fmt.Sprintf("status line: %v\nfields: %v\navailable fields: %v",
+			shown, strings.Join(cfg.Fields, ","), strings.Join(statusFields, ",")))
+	case len(tokens) == 1 && tokens[0] == "on":
+		cfg.Hidden = false
+	case len(tokens) == 1 && tokens[0] == "off":
+		cfg.Hidden = true
+	case len(tokens) == 2 && tokens[0] == "fields":
+		fields, err := parseStatusFields(tokens[1])
+		if err != nil {
+			return tea.Println(stylesheet.ErrStyle.Render(err.Error()))
+		}
+		cfg.Fields = fields
+	default:
+		return tea.Println(stylesheet.ErrStyle.Render("usage: status [on | off | fields <f1,f2,...>]"))
+	}
+
+	m.status.cfg = cfg
+	if err := cfg.save(); err != nil {
+		clilog.Writer.Warnf("failed to save status line configuration: %v", err)
+		return tea.Println(stylesheet.ErrStyle.Render("failed to save status line configuration: " +
+			err.Error()))
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package mother
+
+import (
+	"errors"
+	"gwcli/connection"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestStatusConfig(t *testing.T) {
+	orig := statusPath
+	statusPath = filepath.Join(t.TempDir(), "statusline.json")
+	t.Cleanup(func() { statusPath = orig })
+
+	cfg, err := loadStatusConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Hidden || !reflect.DeepEqual(cfg.Fields, statusFields) {
+		t.Fatalf("missing file did not produce the default configuration: %+v", cfg)
+	}
+
+	want := statusConfig{Hidden: true, Fields: []string{"token", "server"}}
+	if err := want.save(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err = loadStatusConfig(); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("loaded %+v, want %+v", cfg, want)
+	}
+
+	// fields that no longer exist are dropped
+	if err := os.WriteFile(statusPath, []byte(`{"fields":["user","bogus"]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err = loadStatusConfig(); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(cfg.Fields, []string{"user"}) {
+		t.Errorf("loaded fields %v, want [user]", cfg.Fields)
+	}
+}
+
+func Test_parseStatusFields(t *testing.T) {
+	tests := []struct {
+		list    string
+		want    []string
+		wantErr bool
+	}{
+		{"server,user", []string{"server", "user"}, false},
+		{"token, server,token,", []string{"token", "server"}, false},
+		{"server,bogus", nil, true},
+		{",", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.list, func(t *testing.T) {
+			got, err := parseStatusFields(tt.list)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseStatusFields() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseStatusFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_formatCountdown(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{3*time.Hour + 12*time.Minute + 30*time.Second, "3h12m"},
+		{time.Hour, "1h00m"},
+		{59*time.Minute + 5*time.Second, "59m05s"},
+		{4 * time.Second, "0m04s"},
+	}
+	for _, tt := range tests {
+		if got := formatCountdown(tt.d); got != tt.want {
+			t.Errorf("formatCountdown(%v) = %v, want %v", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestStatusLineView(t *testing.T) {
+	m := Mother{status: statusLine{cfg: statusConfig{Fields: statusFields}}}
+	m.status.record(connection.Request{When: time.Now(), Latency: 42 * time.Millisecond})
+	m.status.tokenExpiry = time.Now().Add(2*time.Hour + 30*time.Second)
+	m.jobs.bg = []*job{
+		{id: 1, model: &fakeBackgrounder{pending: true}},
+		{id: 2, model: &fakeBackgrounder{pending: true}},
+		{id: 3, model: &fakeModel{}},
+	}
+	if got, want := ansi.Strip(m.statusLineView()), "token 2h00m │ 42ms │ 2 searches"; got != want {
+		t.Errorf("status line = %q, want %q", got, want)
+	}
+
+	m.status.record(connection.Request{When: time.Now(), Err: errors.New("connection refused")})
+	m.status.cfg.Fields = []string{"searches", "health"}
+	if got, want := ansi.Strip(m.statusLineView()), "2 searches │ connection refused"; got != want {
+		t.Errorf("status line = %q, want %q", got, want)
+	}
+
+	// requests whose latency was not measured retain the last latency measured
+	m.status.record(connection.Request{When: time.Now()})
+	if got, want := ansi.Strip(m.statusLineView()), "2 searches │ 42ms"; got != want {
+		t.Errorf("status line = %q, want %q", got, want)
+	}
+
+	m.winSize.Width = 8
+	if got := ansi.Strip(m.statusLineView()); got != "2 searc…" {
+		t.Errorf("truncated status line = %q", got)
+	}
+
+	m.status.cfg.Hidden = true
+	if got := m.statusLineView(); got != "" {
+		t.Errorf("hidden status line = %q", got)
+	}
+}
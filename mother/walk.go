@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"gwcli/action"
 	"gwcli/clilog"
+	"gwcli/utilities/keymap"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -33,6 +34,7 @@ type walkResult struct {
 	status     walkStatus     // ending state
 	errString  string
 
+	builtin     string                          // name of the built-in to invoke
 	builtinFunc func(*Mother, []string) tea.Cmd // built-in func to invoke
 
 	// contains args for actions
@@ -68,6 +70,7 @@ func walk(dir *cobra.Command, tokens []string) walkResult {
 		return walkResult{
 			endCommand:      nil,
 			status:          foundBuiltin,
+			builtin:         curToken,
 			builtinFunc:     bif,
 			remainingString: strings.Join(tokens[1:], " "),
 		}
@@ -109,7 +112,7 @@ func walk(dir *cobra.Command, tokens []string) walkResult {
 		return walkResult{
 			endCommand:      nil,
 			status:          invalidCommand,
-			errString:       fmt.Sprintf("unknown command '%s'. Press F1 or type 'help' for relevant commands.", curToken),
+			errString:       fmt.Sprintf("unknown command '%s'. Press %v or type 'help' for relevant commands.", curToken, keymap.Keys(keys.help)),
 			remainingString: strings.Join(tokens[1:], " "),
 		}
 	}
@@ -1,26 +1,43 @@
-// Colors provides constants used to provide uniform, readable colors to the styles
+// Colors provides the palette used to provide uniform, readable colors to the styles.
+// The palette is derived from the active theme (see Apply); do not capture these colors (or styles
+// built from them) in package-level variables, as they change when a theme is applied.
 package stylesheet
 
 import "github.com/charmbracelet/lipgloss"
 
-// The Primary+Accents are based on a triadic scheme with #9c7af7 at the head
-// The secondary, tertiary colors are analogous to the primary
+var ( // set by Apply
+	PrimaryColor   lipgloss.Color
+	SecondaryColor lipgloss.Color
+	TertiaryColor  lipgloss.Color
+	AccentColor1   lipgloss.Color
+	AccentColor2   lipgloss.Color
+	ErrorColor     lipgloss.Color
+	NavColor       lipgloss.Color
+	ActionColor    lipgloss.Color
+	FocusedColor   lipgloss.Color // an element currently in focus
+	UnfocusedColor lipgloss.Color // complimentary elements to the focused element
+)
 
-const (
-	PrimaryColor   = lipgloss.Color("#9c7af7")
-	SecondaryColor = lipgloss.Color("#bb7af7")
-	TertiaryColor  = lipgloss.Color("#f77af4")
-	AccentColor1   = lipgloss.Color("#f79c7a")
-	AccentColor2   = lipgloss.Color("#7af79c")
-	ErrorColor     = lipgloss.Color("#f77a96")
-	NavColor       = SecondaryColor
-	ActionColor    = AccentColor1
-	FocusedColor   = AccentColor2   // an element currently in focus
-	UnfocusedColor = SecondaryColor // complimentary elements to the focused element
+var ( // table colors
+	borderColor lipgloss.Color
+	row1Color   lipgloss.Color
+	row2Color   lipgloss.Color
 )
 
-const ( // table colors
+// Sets the palette from the given theme.
+func setColors(t Theme) {
+	PrimaryColor = t.Primary
+	SecondaryColor = t.Secondary
+	TertiaryColor = t.Tertiary
+	AccentColor1 = t.Accent1
+	AccentColor2 = t.Accent2
+	ErrorColor = t.Error
+	NavColor = SecondaryColor
+	ActionColor = AccentColor1
+	FocusedColor = AccentColor2
+	UnfocusedColor = SecondaryColor
+
 	borderColor = PrimaryColor
-	row1Color   = SecondaryColor
-	row2Color   = TertiaryColor
-)
+	row1Color = SecondaryColor
+	row2Color = TertiaryColor
+}
@@ -24,10 +24,21 @@ var Name = struct {
 
 	// output manipulation
 
-	Output string // file output
-	Append string // append to file output
-	CSV    string
-	JSON   string
+	Output  string // file output
+	Append  string // append to file output
+	CSV     string
+	JSON    string
+	Format  string // local output format
+	Fields  string // field projection
+	Exclude string // field exclusion
+
+	// query
+
+	PageSize    string // interactive page size
+	Stats       string // search statistics
+	StatsFormat string // script mode stats format
+	Assert      string // result assertion
+	ExpectEmpty string // empty-result assertion
 }{
 	Dryrun:    "dryrun",
 	Name:      "name",
@@ -41,10 +52,21 @@ var Name = struct {
 
 	// output manipulation
 
-	Output: "output",
-	Append: "append",
-	CSV:    "csv",
-	JSON:   "json",
+	Output:  "output",
+	Append:  "append",
+	CSV:     "csv",
+	JSON:    "json",
+	Format:  "format",
+	Fields:  "fields",
+	Exclude: "exclude-fields",
+
+	// query
+
+	PageSize:    "page-size",
+	Stats:       "stats",
+	StatsFormat: "stats-format",
+	Assert:      "assert",
+	ExpectEmpty: "expect-empty",
 }
 
 // Common flag usage description used across a variety of actions
@@ -61,10 +83,20 @@ var Usage = struct {
 
 	// output manipulation
 
-	Output string // file output
-	Append string // append to file output
-	CSV    string
-	JSON   string
+	Output  string // file output
+	Append  string // append to file output
+	CSV     string
+	JSON    string
+	Fields  string // field projection
+	Exclude string // field exclusion
+
+	// query
+
+	PageSize    string // interactive page size
+	Stats       string // search statistics
+	StatsFormat string // script mode stats format
+	Assert      string // result assertion
+	ExpectEmpty string // empty-result assertion
 }{
 	Dryrun: "feigns, describing actions that " +
 		lipgloss.NewStyle().Italic(true).Render("would") +
@@ -87,4 +119,11 @@ var Usage = struct {
 	Append: "append to the given output file instead of truncating it.",
 	CSV:    "display results as CSV.\nMutually exclusive with --json.",
 	JSON:   "display results as JSON.\nMutually exclusive with --csv.",
+	Fields: "comma-separated list of fields (table columns or JSON keys) to output.\n" +
+		"All other fields are dropped.\n" +
+		"Projected results are rendered locally by gwcli, so JSON, CSV, and raw output take " +
+		"gwcli's layout rather than the backend's (ex: JSON text entries become " +
+		"{TS,Tag,Source,Data} objects).",
+	Exclude: "comma-separated list of fields (table columns or JSON keys) to drop from output.\n" +
+		"As with --fields, projected results are rendered locally by gwcli.",
 }
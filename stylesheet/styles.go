@@ -4,30 +4,43 @@ package stylesheet
 
 import "github.com/charmbracelet/lipgloss"
 
-var (
-	NavStyle    = lipgloss.NewStyle().Foreground(NavColor)
-	ActionStyle = lipgloss.NewStyle().Foreground(ActionColor)
-	ErrStyle    = lipgloss.NewStyle().Foreground(ErrorColor)
+var ( // set by Apply
+	NavStyle    lipgloss.Style
+	ActionStyle lipgloss.Style
+	ErrStyle    lipgloss.Style
 
 	// styles useful when displaying multiple, composed models
-	Composable = struct {
+	Composable struct {
 		Unfocused lipgloss.Style
 		Focused   lipgloss.Style
-	}{
-		Unfocused: lipgloss.NewStyle().
-			Align(lipgloss.Left, lipgloss.Center).
-			BorderStyle(lipgloss.HiddenBorder()),
-		Focused: lipgloss.NewStyle().
-			Align(lipgloss.Left, lipgloss.Center).
-			BorderStyle(lipgloss.NormalBorder()).
-			BorderForeground(AccentColor1),
 	}
-	Header1Style   = lipgloss.NewStyle().Foreground(PrimaryColor).Bold(true)
-	Header2Style   = lipgloss.NewStyle().Foreground(SecondaryColor)
-	GreyedOutStyle = lipgloss.NewStyle().Faint(true)
+	Header1Style   lipgloss.Style
+	Header2Style   lipgloss.Style
+	GreyedOutStyle lipgloss.Style
 	// Mother's prompt (text prefixed to user input)
-	PromptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(PrimaryColor))
+	PromptStyle lipgloss.Style
 	// used for displaying indices
-	IndexStyle   = lipgloss.NewStyle().Foreground(AccentColor1)
-	ExampleStyle = lipgloss.NewStyle().Foreground(AccentColor2)
+	IndexStyle   lipgloss.Style
+	ExampleStyle lipgloss.Style
 )
+
+// Rebuilds the styles from the palette.
+func setStyles() {
+	NavStyle = lipgloss.NewStyle().Foreground(NavColor)
+	ActionStyle = lipgloss.NewStyle().Foreground(ActionColor)
+	ErrStyle = lipgloss.NewStyle().Foreground(ErrorColor)
+
+	Composable.Unfocused = lipgloss.NewStyle().
+		Align(lipgloss.Left, lipgloss.Center).
+		BorderStyle(lipgloss.HiddenBorder())
+	Composable.Focused = lipgloss.NewStyle().
+		Align(lipgloss.Left, lipgloss.Center).
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(AccentColor1)
+	Header1Style = lipgloss.NewStyle().Foreground(PrimaryColor).Bold(true)
+	Header2Style = lipgloss.NewStyle().Foreground(SecondaryColor)
+	GreyedOutStyle = lipgloss.NewStyle().Faint(true)
+	PromptStyle = lipgloss.NewStyle().Foreground(PrimaryColor)
+	IndexStyle = lipgloss.NewStyle().Foreground(AccentColor1)
+	ExampleStyle = lipgloss.NewStyle().Foreground(AccentColor2)
+}
@@ -5,34 +5,33 @@ import (
 	"github.com/charmbracelet/lipgloss/table"
 )
 
-var (
-	baseCell = lipgloss.NewStyle().Padding(0, 1).Width(30)
-
-	Tbl = struct {
-		// cells
-		HeaderCells lipgloss.Style
-		evenCells   lipgloss.Style
-		oddCells    lipgloss.Style
-
-		// borders
-		BorderType  lipgloss.Border
-		BorderStyle lipgloss.Style
-	}{
-		//cells
-		HeaderCells: lipgloss.NewStyle().
-			Foreground(PrimaryColor).
-			AlignHorizontal(lipgloss.Center).
-			AlignVertical(lipgloss.Center).Bold(true),
-		evenCells: baseCell.
-			Foreground(row1Color),
-		oddCells: baseCell.
-			Foreground(row2Color),
-
-		// borders
-		BorderType:  lipgloss.NormalBorder(),
-		BorderStyle: lipgloss.NewStyle().Foreground(borderColor),
-	}
-)
+var Tbl struct { // set by Apply
+	// cells
+	HeaderCells lipgloss.Style
+	evenCells   lipgloss.Style
+	oddCells    lipgloss.Style
+
+	// borders
+	BorderType  lipgloss.Border
+	BorderStyle lipgloss.Style
+}
+
+// Rebuilds the table styles from the palette.
+func setTableStyles() {
+	baseCell := lipgloss.NewStyle().Padding(0, 1).Width(30)
+
+	//cells
+	Tbl.HeaderCells = lipgloss.NewStyle().
+		Foreground(PrimaryColor).
+		AlignHorizontal(lipgloss.Center).
+		AlignVertical(lipgloss.Center).Bold(true)
+	Tbl.evenCells = baseCell.Foreground(row1Color)
+	Tbl.oddCells = baseCell.Foreground(row2Color)
+
+	// borders
+	Tbl.BorderType = lipgloss.NormalBorder()
+	Tbl.BorderStyle = lipgloss.NewStyle().Foreground(borderColor)
+}
 
 // Generate a styled table skeleton
 func Table() *table.Table {
@@ -0,0 +1,145 @@
+package stylesheet
+
+/**
+ * Themes are the palettes from which every color and style in the stylesheet is derived.
+ * gwcli ships a dark theme (the default), a light theme for light terminal backgrounds, and a
+ * high-contrast theme drawn from the Okabe-Ito palette, whose colors remain distinguishable under
+ * the common forms of colorblindness.
+ *
+ * Custom themes are JSON files in the themes directory of the config directory, named for the
+ * theme (ex: themes/solarized.json is selected by --theme solarized):
+ *
+ *	{"primary": "#268bd2", "error": "#dc322f"}
+ *
+ * Colors are hex codes or ANSI color numbers; any the theme omits are taken from the dark theme.
+ *
+ * All output is rendered by lipgloss' default renderer, so disabling color there (via --no-color or
+ * the NO_COLOR environment variable) disables it everywhere, including in the bubbles and tables
+ * that do not draw from the stylesheet.
+ */
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gwcli/utilities/cfgdir"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Theme is a palette of the colors the stylesheet is built from.
+type Theme struct {
+	Primary   lipgloss.Color `json:"primary"`
+	Secondary lipgloss.Color `json:"secondary"`
+	Tertiary  lipgloss.Color `json:"tertiary"`
+	Accent1   lipgloss.Color `json:"accent1"`
+	Accent2   lipgloss.Color `json:"accent2"`
+	Error     lipgloss.Color `json:"error"`
+}
+
+// DefaultTheme is the theme in use if the user does not select one.
+const DefaultTheme = "dark"
+
+// Themes are the built-in themes, by name.
+var Themes = map[string]Theme{
+	// The Primary+Accents are based on a triadic scheme with #9c7af7 at the head
+	// The secondary, tertiary colors are analogous to the primary
+	"dark": {
+		Primary:   "#9c7af7",
+		Secondary: "#bb7af7",
+		Tertiary:  "#f77af4",
+		Accent1:   "#f79c7a",
+		Accent2:   "#7af79c",
+		Error:     "#f77a96",
+	},
+	// the dark scheme, darkened to remain legible on light backgrounds
+	"light": {
+		Primary:   "#5a32c8",
+		Secondary: "#7c2fbf",
+		Tertiary:  "#b0279f",
+		Accent1:   "#b44d1f",
+		Accent2:   "#1b7f43",
+		Error:     "#c0223f",
+	},
+	// Okabe-Ito
+	"high-contrast": {
+		Primary:   "#56b4e9", // sky blue
+		Secondary: "#f0e442", // yellow
+		Tertiary:  "#cc79a7", // reddish purple
+		Accent1:   "#e69f00", // orange
+		Accent2:   "#009e73", // bluish green
+		Error:     "#d55e00", // vermillion
+	},
+}
+
+// directory custom themes are read from; a variable so tests can redirect it
+var themesDir = cfgdir.DefaultThemesPath
+
+// whether color has been disabled
+var noColor bool
+
+func init() {
+	Apply(Themes[DefaultTheme])
+	if termenv.EnvNoColor() {
+		DisableColor()
+	}
+}
+
+// Apply derives the palette and every style from the given theme.
+// Styles are not retroactively updated; themes should be applied prior to drawing anything.
+func Apply(t Theme) {
+	setColors(t)
+	setStyles()
+	setTableStyles()
+}
+
+// LoadTheme returns the built-in or custom theme of the given name.
+func LoadTheme(name string) (Theme, error) {
+	if t, ok := Themes[name]; ok {
+		return t, nil
+	}
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return Theme{}, fmt.Errorf("invalid theme name '%v'", name)
+	}
+	p := path.Join(themesDir, name+".json")
+	b, err := os.ReadFile(p)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Theme{}, fmt.Errorf("unknown theme '%v' (built-in themes: %v; custom themes are"+
+				" read from %v)", name, strings.Join(ThemeNames(), ", "), themesDir)
+		}
+		return Theme{}, err
+	}
+	t := Themes[DefaultTheme] // omitted colors fall back to the default theme
+	if err := json.Unmarshal(b, &t); err != nil {
+		return Theme{}, fmt.Errorf("failed to parse theme %v: %w", p, err)
+	}
+	return t, nil
+}
+
+// Returns the names of the built-in themes, sorted.
+func ThemeNames() []string {
+	names := make([]string, 0, len(Themes))
+	for name := range Themes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// DisableColor strips color (and all other styling) from everything rendered hereafter.
+func DisableColor() {
+	noColor = true
+	lipgloss.SetColorProfile(termenv.Ascii)
+}
+
+// NoColor returns whether color has been disabled, by DisableColor or the NO_COLOR environment
+// variable.
+func NoColor() bool {
+	return noColor
+}
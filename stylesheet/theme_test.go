@@ -0,0 +1,59 @@
+package stylesheet
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+func TestLoadTheme(t *testing.T) {
+	themesDir = t.TempDir()
+	custom := `{"primary": "#268bd2", "error": "9"}`
+	if err := os.WriteFile(path.Join(themesDir, "custom.json"), []byte(custom), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path.Join(themesDir, "broken.json"), []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	withCustom := Themes[DefaultTheme]
+	withCustom.Primary, withCustom.Error = "#268bd2", "9"
+
+	tests := []struct {
+		name    string
+		want    Theme
+		wantErr bool
+	}{
+		{"light", Themes["light"], false},
+		{"high-contrast", Themes["high-contrast"], false},
+		{"custom", withCustom, false},
+		{"broken", Theme{}, true},
+		{"nonexistent", Theme{}, true},
+		{"../custom", Theme{}, true},
+		{"", Theme{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadTheme(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadTheme() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("LoadTheme() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	t.Cleanup(func() { Apply(Themes[DefaultTheme]) })
+
+	hc := Themes["high-contrast"]
+	Apply(hc)
+	if PrimaryColor != hc.Primary || FocusedColor != hc.Accent2 || NavColor != hc.Secondary {
+		t.Errorf("palette was not derived from the theme")
+	}
+	if Header1Style.GetForeground() != hc.Primary || ErrStyle.GetForeground() != hc.Error {
+		t.Errorf("styles were not rebuilt from the palette")
+	}
+}
@@ -84,6 +84,7 @@ func NewMacroEditAction() action.Pair {
 			}
 			return data.Name, nil
 		},
+		GetIDSub: func(item types.SearchMacro) uint64 { return item.ID },
 	}
 
 	return scaffoldedit.NewEditAction(singular, "macros", cfg, funcs)
@@ -99,6 +99,7 @@ func NewQueriesScheduledEditAction() action.Pair {
 		UpdateSub: func(data *types.ScheduledSearch) (identifier string, err error) {
 			return data.Name, connection.Client.UpdateScheduledSearch(*data)
 		},
+		GetIDSub: func(item types.ScheduledSearch) int32 { return item.ID },
 	}
 
 	return scaffoldedit.NewEditAction(singular, "scheduled searches", cfg, funcs)
@@ -13,7 +13,9 @@ import (
 	"gwcli/clilog"
 	"gwcli/connection"
 	"gwcli/stylesheet"
+	ft "gwcli/stylesheet/flagtext"
 	"gwcli/tree/query/datascope"
+	"gwcli/tree/query/transcode"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -56,8 +58,10 @@ type query struct {
 	modifiers modifView
 
 	flagModifiers struct { // flag options that only affect datascope
-		json     bool
-		csv      bool
+		format   transcode.Format
+		project  transcode.Projection
+		stats    bool // open DataScope on the stats tab
+		pageSize int
 		outfn    string
 		append   bool
 		schedule schedule
@@ -65,9 +69,8 @@ type query struct {
 
 	focusedEditor bool
 
-	curSearch   *grav.Search // nil or ongoing/recently-completed search
-	searchDone  atomic.Bool  // waiting thread has returned
-	searchError chan error   // result to be fetched after SearchDone
+	curSearch *grav.Search // nil or ongoing/recently-completed search
+	wait      *searchWait  // completion of curSearch; nil if no search was submitted
 
 	spnr  spinner.Model // wait spinner
 	scope tea.Model     // interactively display data
@@ -82,10 +85,9 @@ var Query action.Model = Initial()
 
 func Initial() *query {
 	q := &query{
-		mode:        inactive,
-		searchError: make(chan error),
-		curSearch:   nil,
-		spnr:        busywait.NewSpinner(),
+		mode:      inactive,
+		curSearch: nil,
+		spnr:      busywait.NewSpinner(),
 	}
 
 	// configure max dimensions
@@ -98,10 +100,8 @@ func Initial() *query {
 	q.focusedEditor = true
 
 	q.keys = []key.Binding{
-		key.NewBinding(key.WithKeys("tab"), // 0: cycle
-			key.WithHelp("tab", "cycle view")),
-		key.NewBinding(key.WithKeys("esc"), // [handled by mother]
-			key.WithHelp("esc", "return to navigation")),
+		keys.cycleView, // 0: cycle
+		keys.quit,
 	}
 
 	// set up help
@@ -132,8 +132,8 @@ func (q *query) Update(msg tea.Msg) tea.Cmd {
 		q.focusedEditor = true
 		return textarea.Blink
 	case waiting: // display spinner and wait
-		if q.searchDone.Load() { // search is done
-			if err := <-q.searchError; err != nil { // failure, return to text input
+		if q.wait.done.Load() { // search is done
+			if err := q.wait.err; err != nil { // failure, return to text input
 				q.editor.err = err.Error()
 				q.mode = prompting
 				var cmd tea.Cmd
@@ -141,31 +141,37 @@ func (q *query) Update(msg tea.Msg) tea.Cmd {
 				return cmd
 			}
 
-			results, tableMode, err := fetchResults(q.curSearch)
+			results, err := fetchResults(q.curSearch)
 			if err != nil {
 				q.editor.err = err.Error()
 				q.mode = prompting
 				var cmd tea.Cmd
 				q.editor.ta, cmd = q.editor.ta.Update(msg)
 				return cmd
-			} else if len(results) == 0 {
+			} else if results.Len() == 0 {
 				q.mode = quitting
 				return tea.Println(NoResultsText)
 			}
 
 			var cmd tea.Cmd
-			// JSON,CSV,outfn,append are user-editable in the DataScope; these just set initial
-			// values
-			q.scope, cmd, err = datascope.NewDataScope(results, true, q.curSearch, tableMode,
+			// format,outfn,append,projection are user-editable in the DataScope; these just set initial values
+			opts := []datascope.DataScopeOption{
 				datascope.WithAutoDownload(
 					q.flagModifiers.outfn,
 					q.flagModifiers.append,
-					q.flagModifiers.json,
-					q.flagModifiers.csv),
+					q.flagModifiers.format,
+					q.flagModifiers.project),
 				datascope.WithSchedule(
 					q.flagModifiers.schedule.cronfreq,
 					q.flagModifiers.schedule.name,
-					q.flagModifiers.schedule.desc))
+					q.flagModifiers.schedule.desc),
+				datascope.WithPageSize(q.flagModifiers.pageSize),
+				datascope.WithPivot(pivot),
+			}
+			if q.flagModifiers.stats {
+				opts = append(opts, datascope.WithStats())
+			}
+			q.scope, cmd, err = datascope.NewDataScope(results, true, q.curSearch, opts...)
 			if err != nil {
 				clilog.Writer.Errorf("failed to create DataScope: %v", err)
 				q.mode = quitting
@@ -258,9 +264,20 @@ func (q *query) Reset() error {
 	// reset modifier view
 	q.modifiers.reset()
 
+	// abandon any search still being waited on; its waiting goroutine reports to a searchWait that
+	// is no longer referenced, so it cannot affect later searches
+	if q.curSearch != nil && q.wait != nil && !q.wait.done.Load() {
+		sid := q.curSearch.ID
+		go func() {
+			if err := connection.Client.StopSearch(sid); err != nil {
+				clilog.Writer.Warnf("failed to stop abandoned search %v: %v", sid, err)
+			}
+		}()
+	}
+
 	// clear query fields
 	q.curSearch = nil
-	q.searchDone.Store(false)
+	q.wait = nil
 	q.scope = nil
 
 	localFS = initialLocalFlagSet()
@@ -268,6 +285,53 @@ func (q *query) Reset() error {
 	return nil
 }
 
+// Pending returns whether the search is still running.
+// Satisfies action.Backgrounder.
+func (q *query) Pending() bool {
+	return q.mode == waiting && !q.wait.done.Load()
+}
+
+// pings the search to keep it alive; swappable for testing.
+var pingSearch = func(s *grav.Search) error { return s.Ping() }
+
+// KeepAlive keeps the displayed search's heartbeat going, as DataScope's Update otherwise would.
+// A search that finished while waiting (ex: in the background) has no DataScope yet, so it is
+// pinged directly until it is displayed, lest it expire before the user views it.
+// Satisfies action.Backgrounder.
+func (q *query) KeepAlive() {
+	switch q.mode {
+	case displaying:
+		if s, ok := q.scope.(interface{ KeepAlive() }); ok {
+			s.KeepAlive()
+		}
+	case waiting:
+		if q.curSearch == nil || !q.wait.done.Load() || q.wait.err != nil ||
+			time.Since(q.wait.pinged) < datascope.PingFrequency {
+			return
+		}
+		q.wait.pinged = time.Now()
+		search := q.curSearch
+		go func() {
+			if err := pingSearch(search); err != nil {
+				clilog.Writer.Warnf("failed to ping search %v: %v", search.ID, err)
+			}
+		}()
+	}
+}
+
+// Resume restarts the wait spinner or, if results are being displayed, returns to the alternate
+// screen.
+// Satisfies action.Backgrounder.
+func (q *query) Resume() tea.Cmd {
+	switch q.mode {
+	case waiting:
+		return q.spnr.Tick
+	case displaying:
+		return tea.EnterAltScreen
+	}
+	return nil
+}
+
 // Consume flags and associated them to the local flagset
 func (q *query) SetArgs(_ *pflag.FlagSet, tokens []string) (string, tea.Cmd, error) {
 	// parse the tokens agains the local flagset
@@ -284,11 +348,18 @@ func (q *query) SetArgs(_ *pflag.FlagSet, tokens []string) (string, tea.Cmd, err
 	if flags.script {
 		return "", nil, errors.New("cannot invoke script mode while in interactive mode")
 	}
+	// assertions are script-only
+	if flags.assert != nil {
+		return "--" + ft.Name.Assert + " and --" + ft.Name.ExpectEmpty +
+			" are not available in interactive mode", nil, nil
+	}
 
 	// set fields by flags
 	q.modifiers.durationTI.SetValue(flags.duration.String())
-	q.flagModifiers.json = flags.json
-	q.flagModifiers.csv = flags.csv
+	q.flagModifiers.format = flags.format
+	q.flagModifiers.project = flags.project
+	q.flagModifiers.stats = flags.stats
+	q.flagModifiers.pageSize = flags.pageSize
 	q.flagModifiers.outfn = flags.outfn
 	q.flagModifiers.append = flags.append
 	q.flagModifiers.schedule = flags.schedule
@@ -306,6 +377,13 @@ func (q *query) SetArgs(_ *pflag.FlagSet, tokens []string) (string, tea.Cmd, err
 
 //#region helper subroutines
 
+// searchWait is the completion of a submitted search, as reported by the goroutine waiting on it.
+type searchWait struct {
+	done   atomic.Bool // the waiting goroutine has returned
+	err    error       // result of the wait; only valid once done
+	pinged time.Time   // last time the finished search was pinged (see KeepAlive)
+}
+
 // Gathers information across both views and initiates the search, placing the model into a waiting
 // state. A seperate goroutine, initialized here, waits on the search, allowing this thread to
 // display a spinner.
@@ -337,14 +415,15 @@ func (q *query) submitQuery() tea.Cmd {
 	}
 
 	// spin up a goroutine to wait on the search while we show a spinner
+	wait := &searchWait{}
 	go func() {
-		err := connection.Client.WaitForSearch(s)
-		// notify we are done and buffer the error for retrieval
-		q.searchDone.Store(true)
-		q.searchError <- err
+		// record the error for retrieval prior to notifying we are done
+		wait.err = connection.Client.WaitForSearch(s)
+		wait.done.Store(true)
 	}()
 
 	q.curSearch = &s
+	q.wait = wait
 	q.mode = waiting
 	return q.spnr.Tick // start the wait spinner
 }
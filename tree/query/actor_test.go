@@ -0,0 +1,70 @@
+package query
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	grav "github.com/gravwell/gravwell/v3/client"
+)
+
+func Test_query_ResetAbandonsWait(t *testing.T) {
+	q := Initial()
+	// a search is being waited on when the query is killed
+	orphan := &searchWait{}
+	q.mode, q.wait = waiting, orphan
+	if !q.Pending() {
+		t.Fatal("expected the query to be pending prior to reset")
+	}
+	if err := q.Reset(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the orphaned goroutine completes after the reset
+	orphan.err = errors.New("search stopped")
+	orphan.done.Store(true)
+
+	if q.wait != nil || q.mode != inactive || q.Pending() {
+		t.Errorf("orphaned wait altered the reset query (mode %v, wait %v)", q.mode, q.wait)
+	}
+}
+
+func Test_query_KeepAliveFinishedWait(t *testing.T) {
+	pinged := make(chan string, 2)
+	defer func(f func(*grav.Search) error) { pingSearch = f }(pingSearch)
+	pingSearch = func(s *grav.Search) error {
+		pinged <- s.ID
+		return nil
+	}
+
+	q := Initial()
+	q.mode, q.wait, q.curSearch = waiting, &searchWait{}, &grav.Search{ID: "123"}
+
+	// a running search is not pinged
+	q.KeepAlive()
+	select {
+	case sid := <-pinged:
+		t.Fatalf("pending search %v was pinged", sid)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// a finished search awaiting display is
+	q.wait.done.Store(true)
+	q.KeepAlive()
+	select {
+	case sid := <-pinged:
+		if sid != "123" {
+			t.Errorf("pinged search %v, expected 123", sid)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("finished search was not pinged")
+	}
+
+	// but no more often than DataScope would ping it
+	q.KeepAlive()
+	select {
+	case sid := <-pinged:
+		t.Errorf("search %v was pinged again within the ping frequency", sid)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
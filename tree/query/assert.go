@@ -0,0 +1,156 @@
+package query
+
+/**
+ * Assertions allow query to act as a simple monitor: after the search completes, a condition is
+ * evaluated on the results and query exits non-zero if the condition was violated.
+ *
+ * Grammar: <subject> <operator> <value>
+ *
+ * subject: `count` (the number of results) or `column(<name>)` (every row's value in the named
+ * column of table results)
+ *
+ * operator: >, >=, <, <=, ==, = (alias of ==), !=
+ *
+ * value: a number. Column assertions may also compare against a (optionally quoted) string with
+ * == and !=.
+ */
+
+import (
+	"errors"
+	"fmt"
+	"gwcli/tree/query/transcode"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// errAssertionFailed is returned when the results violate the assertion.
+var errAssertionFailed = errors.New("assertion failed")
+
+type assertion struct {
+	raw     string
+	column  string // empty if the subject is the result count
+	op      string
+	value   string
+	num     float64
+	numeric bool // value parsed as a number
+}
+
+var assertionRgx = regexp.MustCompile(
+	`^\s*(count|column\(\s*(.+?)\s*\))\s*(==|!=|>=|<=|>|<|=)\s*(.+?)\s*$`)
+
+// parseAssertion validates the given assertion string and returns its structured form.
+func parseAssertion(s string) (*assertion, error) {
+	m := assertionRgx.FindStringSubmatch(s)
+	if m == nil {
+		return nil, fmt.Errorf("malformed assertion '%v'. "+
+			"Expected the form 'count <op> <number>' or 'column(<name>) <op> <value>'", s)
+	}
+	a := &assertion{raw: strings.TrimSpace(s), column: m[2], op: m[3], value: m[4]}
+	if a.op == "=" {
+		a.op = "=="
+	}
+	if n, err := strconv.ParseFloat(a.value, 64); err == nil {
+		a.num, a.numeric = n, true
+	} else {
+		a.value = strings.Trim(a.value, `"'`)
+	}
+
+	if !a.numeric {
+		if a.column == "" {
+			return nil, fmt.Errorf("count can only be compared to a number, not '%v'", a.value)
+		}
+		if a.op != "==" && a.op != "!=" {
+			return nil, fmt.Errorf("'%v' requires a numeric value, not '%v'", a.op, a.value)
+		}
+	}
+	return a, nil
+}
+
+// compareNum returns whether `x <op> a.num` holds.
+func (a *assertion) compareNum(x float64) bool {
+	switch a.op {
+	case ">":
+		return x > a.num
+	case ">=":
+		return x >= a.num
+	case "<":
+		return x < a.num
+	case "<=":
+		return x <= a.num
+	case "==":
+		return x == a.num
+	case "!=":
+		return x != a.num
+	}
+	return false
+}
+
+// holds returns whether the given cell satisfies the assertion.
+// Non-numeric cells never satisfy a numeric assertion.
+func (a *assertion) holds(cell string) bool {
+	if !a.numeric {
+		return (cell == a.value) == (a.op == "==")
+	}
+	x, err := strconv.ParseFloat(strings.TrimSpace(cell), 64)
+	if err != nil {
+		return false
+	}
+	return a.compareNum(x)
+}
+
+// evaluate checks the assertion against the given results, returning whether it held and a
+// summary suitable for display to the user.
+// Returns an error if the assertion cannot be applied to the results.
+func (a *assertion) evaluate(res transcode.Results) (passed bool, summary string, err error) {
+	if a.column == "" {
+		count := res.Len()
+		passed = a.compareNum(float64(count))
+		return passed, fmt.Sprintf("%v: %v (count = %d)", passStr(passed), a.raw, count), nil
+	}
+
+	if !res.Table() {
+		return false, "", errors.New("column assertions require table results")
+	}
+	idx := -1
+	for i, c := range res.Columns {
+		if c == a.column {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		return false, "", fmt.Errorf("no column '%v' in results (columns: %v)",
+			a.column, strings.Join(res.Columns, ", "))
+	}
+
+	var (
+		violations int
+		first      string // description of the first violating row
+	)
+	for i, r := range res.Rows {
+		var cell string
+		if idx < len(r.Row) {
+			cell = r.Row[idx]
+		}
+		if !a.holds(cell) {
+			if violations == 0 {
+				first = fmt.Sprintf("row %d: '%v'", i+1, cell)
+			}
+			violations++
+		}
+	}
+	if violations == 0 {
+		return true, fmt.Sprintf("%v: %v (held for all %d rows)",
+			passStr(true), a.raw, len(res.Rows)), nil
+	}
+	return false, fmt.Sprintf("%v: %v (violated by %d of %d rows; first: %v)",
+		passStr(false), a.raw, violations, len(res.Rows), first), nil
+}
+
+func passStr(passed bool) string {
+	if passed {
+		return "PASS"
+	}
+	return "FAIL"
+}
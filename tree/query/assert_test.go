@@ -0,0 +1,110 @@
+package query
+
+import (
+	"gwcli/tree/query/transcode"
+	"strings"
+	"testing"
+
+	"github.com/gravwell/gravwell/v3/client/types"
+)
+
+func Test_parseAssertion(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    assertion
+		wantErr bool
+	}{
+		{"count", "count > 0",
+			assertion{raw: "count > 0", op: ">", value: "0", numeric: true}, false},
+		{"count no spaces, = alias", "count=5",
+			assertion{raw: "count=5", op: "==", value: "5", num: 5, numeric: true}, false},
+		{"numeric column", "column( hits ) <= 1.5",
+			assertion{raw: "column( hits ) <= 1.5", column: "hits", op: "<=", value: "1.5",
+				num: 1.5, numeric: true}, false},
+		{"string column", `column(status) != "down"`,
+			assertion{raw: `column(status) != "down"`, column: "status", op: "!=", value: "down"},
+			false},
+		{"string count", "count == many", assertion{}, true},
+		{"ordered string", "column(status) > down", assertion{}, true},
+		{"unknown subject", "rows > 1", assertion{}, true},
+		{"missing value", "count >", assertion{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAssertion(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAssertion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && *got != tt.want {
+				t.Errorf("parseAssertion() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_assertion_evaluate(t *testing.T) {
+	text := transcode.Results{Entries: []types.SearchEntry{{}, {}}}
+	tbl := transcode.Results{
+		Columns: []string{"host", "hits"},
+		Rows: []types.TableRow{
+			{Row: []string{"web", "5"}},
+			{Row: []string{"db", "50"}},
+		},
+	}
+	tests := []struct {
+		name       string
+		assert     string
+		res        transcode.Results
+		wantPassed bool
+		wantErr    bool
+	}{
+		{"count holds", "count > 1", text, true, false},
+		{"count violated", "count == 0", text, false, false},
+		{"count on table", "count == 2", tbl, true, false},
+		{"column holds", "column(hits) < 100", tbl, true, false},
+		{"column violated", "column(hits) < 10", tbl, false, false},
+		{"non-numeric cell", "column(host) > 1", tbl, false, false},
+		{"string column", "column(host) != down", tbl, true, false},
+		{"missing column", "column(misses) < 10", tbl, false, true},
+		{"column on text", "column(hits) < 10", text, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := parseAssertion(tt.assert)
+			if err != nil {
+				t.Fatal(err)
+			}
+			passed, summary, err := a.evaluate(tt.res)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("evaluate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if passed != tt.wantPassed {
+				t.Errorf("evaluate() passed = %v, want %v (summary: %v)", passed, tt.wantPassed, summary)
+			}
+		})
+	}
+}
+
+func Test_transmogrifyFlags_assertFrequency(t *testing.T) {
+	tests := []struct {
+		args    []string
+		wantErr bool
+	}{
+		{[]string{"--assert", "count > 0"}, false},
+		{[]string{"--frequency", "* * * * *"}, false},
+		{[]string{"--assert", "count > 0", "--frequency", "* * * * *"}, true},
+		{[]string{"--expect-empty", "--frequency", "* * * * *"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			fs := initialLocalFlagSet()
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := transmogrifyFlags(&fs); (err != nil) != tt.wantErr {
+				t.Errorf("transmogrifyFlags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
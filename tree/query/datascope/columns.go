@@ -0,0 +1,498 @@
+package datascope
+
+/**
+ * Column management for the table tab: sorting rows by a column, hiding, reordering, resizing, and
+ * freezing columns.
+ * The arrangement of columns (but not the sort) is remembered per set of column names, so
+ * re-running a query restores the layout the user last left it in.
+ *
+ * Columns are referred to by their (0-indexed) position in the query's results (the "data column")
+ * regardless of where, or if, they are displayed. The index column is not a data column; it is
+ * always displayed first and never scrolls.
+ */
+
+import (
+	"encoding/json"
+	"errors"
+	"gwcli/clilog"
+	"gwcli/utilities/cfgdir"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/evertras/bubble-table/table"
+)
+
+// narrowest a data column may become before the table scrolls horizontally instead
+const minColumnWidth = 12
+
+// file layouts are persisted to; a variable so tests can redirect it
+var layoutsPath = cfgdir.DefaultLayoutsPath
+
+// columnLayout is the user's arrangement of the table's data columns.
+type columnLayout struct {
+	Order  []int `json:"order"`            // data columns in display order, including hidden columns
+	Hidden []int `json:"hidden,omitempty"` // data columns that are not displayed
+	Frozen int   `json:"frozen,omitempty"` // leading displayed columns that do not scroll
+	Flex   []int `json:"flex,omitempty"`   // flex factor of each data column; empty for defaults
+}
+
+func defaultLayout(count int) columnLayout {
+	return columnLayout{Order: allIndices(count)}
+}
+
+// Returns whether the layout is applicable to a table with the given number of data columns.
+func (cl *columnLayout) valid(count int) bool {
+	if len(cl.Order) != count || (cl.Flex != nil && len(cl.Flex) != count) || cl.Frozen < 0 {
+		return false
+	}
+	sorted := slices.Clone(cl.Order)
+	sort.Ints(sorted)
+	if !slices.Equal(sorted, allIndices(count)) {
+		return false
+	}
+	for _, h := range cl.Hidden {
+		if h < 0 || h >= count {
+			return false
+		}
+	}
+	return len(cl.Hidden) < count // at least one column must remain
+}
+
+// Returns whether columns are displayed in their original order, with none hidden.
+func (cl *columnLayout) isDefault() bool {
+	return len(cl.Hidden) == 0 && slices.Equal(cl.Order, allIndices(len(cl.Order)))
+}
+
+// Returns the displayed data columns, in display order.
+func (cl *columnLayout) visible() []int {
+	vis := make([]int, 0, len(cl.Order))
+	for _, c := range cl.Order {
+		if !slices.Contains(cl.Hidden, c) {
+			vis = append(vis, c)
+		}
+	}
+	return vis
+}
+
+//#region persistence
+
+// Returns the key layouts are saved under for a table with the given columns.
+func layoutSignature(columns []string) string {
+	return strings.Join(columns, ",")
+}
+
+// Reads all saved layouts from the file at path.
+// A missing file is not an error.
+func loadLayouts(path string) (map[string]columnLayout, error) {
+	layouts := make(map[string]columnLayout)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return layouts, nil
+		}
+		return layouts, err
+	}
+	if err := json.Unmarshal(b, &layouts); err != nil {
+		return make(map[string]columnLayout), err
+	}
+	return layouts, nil
+}
+
+// Saves the layout under the given signature, retaining all other layouts in the file at path.
+// A default layout removes the signature's entry.
+func saveLayout(path, signature string, layout columnLayout) error {
+	layouts, err := loadLayouts(path)
+	if err != nil {
+		clilog.Writer.Warnf("discarding unreadable layouts file %v: %v", path, err)
+	}
+	if layout.isDefault() && layout.Frozen == 0 && layout.Flex == nil {
+		delete(layouts, signature)
+	} else {
+		layouts[signature] = layout
+	}
+	b, err := json.MarshalIndent(layouts, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0600)
+}
+
+// Loads the saved layout for the table's columns, if there is one.
+func (tt *tableTab) loadLayout() {
+	tt.layout = defaultLayout(len(tt.columns) - 1)
+	layouts, err := loadLayouts(layoutsPath)
+	if err != nil {
+		clilog.Writer.Warnf("failed to load table layouts from %v: %v", layoutsPath, err)
+		return
+	}
+	if l, ok := layouts[tt.signature]; ok {
+		if !l.valid(len(tt.columns) - 1) {
+			clilog.Writer.Warnf("ignoring invalid layout for columns %v: %+v", tt.signature, l)
+			return
+		}
+		tt.layout = l
+		for i, ff := range l.Flex {
+			tt.columns[i+1] = table.NewFlexColumn(tt.columns[i+1].Key(), tt.columns[i+1].Title(), ff)
+		}
+	}
+}
+
+// Persists the current layout, logging on failure.
+func (tt *tableTab) saveLayout() {
+	if err := saveLayout(layoutsPath, tt.signature, tt.layout); err != nil {
+		clilog.Writer.Warnf("failed to save table layout to %v: %v", layoutsPath, err)
+	}
+}
+
+//#endregion persistence
+
+//#region sorting
+
+// compareCells orders two cells, comparing runs of digits numerically so "9" < "10" and
+// "host2" < "host10".
+// Cells that are entirely numeric (including signs and decimals) are compared as numbers.
+func compareCells(a, b string) int {
+	af, aErr := strconv.ParseFloat(strings.TrimSpace(a), 64)
+	bf, bErr := strconv.ParseFloat(strings.TrimSpace(b), 64)
+	if aErr == nil && bErr == nil {
+		switch {
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		}
+		return 0
+	}
+
+	ar, br := []rune(a), []rune(b)
+	i, j := 0, 0
+	for i < len(ar) && j < len(br) {
+		if unicode.IsDigit(ar[i]) && unicode.IsDigit(br[j]) {
+			// compare the full runs of digits, ignoring leading zeros
+			si, sj := i, j
+			for i < len(ar) && unicode.IsDigit(ar[i]) {
+				i++
+			}
+			for j < len(br) && unicode.IsDigit(br[j]) {
+				j++
+			}
+			da := strings.TrimLeft(string(ar[si:i]), "0")
+			db := strings.TrimLeft(string(br[sj:j]), "0")
+			if len(da) != len(db) {
+				return cmpInt(len(da), len(db))
+			}
+			if c := strings.Compare(da, db); c != 0 {
+				return c
+			}
+			continue
+		}
+		if ar[i] != br[j] {
+			return cmpInt(int(ar[i]), int(br[j]))
+		}
+		i++
+		j++
+	}
+	return cmpInt(len(ar)-i, len(br)-j)
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Orders the shown rows by the sort column or, if there is none, by their original order.
+// Ties retain their original order.
+func (tt *tableTab) sortRows() {
+	sort.Ints(tt.shown)
+	if tt.sortCol < 0 {
+		return
+	}
+	cell := func(row int) string {
+		if r := tt.data[row].Row; tt.sortCol < len(r) {
+			return r[tt.sortCol]
+		}
+		return ""
+	}
+	sort.SliceStable(tt.shown, func(i, j int) bool {
+		c := compareCells(cell(tt.shown[i]), cell(tt.shown[j]))
+		if tt.sortDesc {
+			return c > 0
+		}
+		return c < 0
+	})
+}
+
+// Sorts by the cursor's column: ascending, then descending, then back to the original order.
+func (tt *tableTab) cycleSort() {
+	switch {
+	case tt.sortCol != tt.column:
+		tt.sortCol, tt.sortDesc = tt.column, false
+	case !tt.sortDesc:
+		tt.sortDesc = true
+	default:
+		tt.sortCol, tt.sortDesc = -1, false
+	}
+	tt.sortRows()
+	tt.applyColumns() // update the sort indicator
+	tt.refresh()
+	tt.jumpToRow(tt.cursor)
+}
+
+//#endregion sorting
+
+//#region arrangement
+
+// Hides the cursor's column, moving the cursor to a neighbouring column.
+// The final visible column cannot be hidden.
+func (tt *tableTab) hideColumn() {
+	vis := tt.layout.visible()
+	if len(vis) <= 1 {
+		return
+	}
+	pos := slices.Index(vis, tt.column)
+	if pos < tt.layout.Frozen {
+		tt.layout.Frozen--
+	}
+	tt.layout.Hidden = append(tt.layout.Hidden, tt.column)
+	vis = slices.Delete(vis, pos, pos+1)
+	tt.column = vis[min(pos, len(vis)-1)]
+	tt.layoutChanged()
+}
+
+// Displays all hidden columns.
+func (tt *tableTab) showColumns() {
+	if len(tt.layout.Hidden) == 0 {
+		return
+	}
+	tt.layout.Hidden = nil
+	tt.layoutChanged()
+}
+
+// Swaps the cursor's column with the neighbouring displayed column in the given direction.
+func (tt *tableTab) moveColumn(delta int) {
+	vis := tt.layout.visible()
+	pos := slices.Index(vis, tt.column)
+	if pos+delta < 0 || pos+delta >= len(vis) {
+		return
+	}
+	other := vis[pos+delta]
+	i, j := slices.Index(tt.layout.Order, tt.column), slices.Index(tt.layout.Order, other)
+	tt.layout.Order[i], tt.layout.Order[j] = tt.layout.Order[j], tt.layout.Order[i]
+	tt.layoutChanged()
+}
+
+// Freezes all displayed columns up to and including the cursor's column or, if that is already
+// the case, unfreezes them.
+func (tt *tableTab) toggleFreeze() {
+	n := slices.Index(tt.layout.visible(), tt.column) + 1
+	if tt.layout.Frozen == n {
+		n = 0
+	}
+	tt.layout.Frozen = n
+	tt.layoutChanged()
+}
+
+// Moves the cursor by the given number of displayed columns.
+func (tt *tableTab) stepColumn(delta int) {
+	vis := tt.layout.visible()
+	pos := slices.Index(vis, tt.column) + delta
+	if pos >= 0 && pos < len(vis) {
+		tt.column = vis[pos]
+		tt.scrollToCursor()
+		tt.refresh()
+	}
+}
+
+// alters the flex factor of the displayed column corresponding to the given number key, where 1
+// is the index column.
+// Treats a 0 as a ten.
+func (tt *tableTab) alterColumnSize(numKey uint, increase bool) {
+	if numKey == 0 {
+		numKey = 10
+	}
+	cols := tt.displayColumns()
+	if int(numKey) > len(cols) {
+		return
+	}
+	key := cols[numKey-1].Key()
+	col := slices.IndexFunc(tt.columns, func(c table.Column) bool { return c.Key() == key })
+
+	newFF := tt.columns[col].FlexFactor()
+	if increase {
+		newFF += 1
+	} else {
+		newFF = max(newFF-1, 0)
+	}
+	tt.columns[col] = table.NewFlexColumn(tt.columns[col].Key(), tt.columns[col].Title(), newFF)
+	clilog.Writer.Debugf("targetting column title %v, new flex factor of %v",
+		tt.columns[col].Title(), newFF)
+	if col > 0 { // the index column's size is not persisted
+		if tt.layout.Flex == nil {
+			tt.layout.Flex = make([]int, len(tt.columns)-1)
+			for i := range tt.layout.Flex {
+				tt.layout.Flex[i] = tt.columns[i+1].FlexFactor()
+			}
+		}
+		tt.layout.Flex[col-1] = newFF
+	}
+	tt.layoutChanged()
+}
+
+// Redraws the table after a change to the layout and persists the layout.
+func (tt *tableTab) layoutChanged() {
+	tt.applyColumns()
+	tt.refresh()
+	tt.saveLayout()
+}
+
+//#endregion arrangement
+
+//#region display
+
+// Returns the columns to install in the table: the index column, then each displayed data column.
+func (tt *tableTab) displayColumns() []table.Column {
+	vis := tt.layout.visible()
+	cols := make([]table.Column, 0, len(vis)+1)
+	cols = append(cols, tt.columns[0])
+	for _, c := range vis {
+		col := tt.columns[c+1]
+		if c == tt.sortCol {
+			indicator := " ▲"
+			if tt.sortDesc {
+				indicator = " ▼"
+			}
+			col = table.NewFlexColumn(col.Key(), col.Title()+indicator, col.FlexFactor())
+		}
+		cols = append(cols, col)
+	}
+	return cols
+}
+
+// Installs the displayed columns into the table, widening it beyond the window (and thereby
+// enabling horizontal scrolling) if the columns would otherwise be too narrow.
+func (tt *tableTab) applyColumns() {
+	cols := tt.displayColumns()
+	for tt.tbl.GetHorizontalScrollColumnOffset() > 0 { // the prior offset may no longer be valid
+		tt.tbl = tt.tbl.ScrollLeft()
+	}
+	tt.tbl = tt.tbl.WithColumns(cols).
+		WithHorizontalFreezeColumnCount(1 + tt.layout.Frozen).
+		WithTargetWidth(max(tt.width, minTableWidth(cols))).
+		WithMaxTotalWidth(tt.width)
+	tt.scrollToCursor()
+}
+
+// Returns the total width at which each data column is at least minColumnWidth wide.
+func minTableWidth(cols []table.Column) int {
+	var flex, minFlex int
+	for i, c := range cols {
+		flex += c.FlexFactor()
+		if i > 0 && c.FlexFactor() > 0 && (minFlex == 0 || c.FlexFactor() < minFlex) {
+			minFlex = c.FlexFactor()
+		}
+	}
+	if minFlex == 0 {
+		return 0
+	}
+	// flex width is divided proportionally; borders are not flexible
+	return (minColumnWidth*flex+minFlex-1)/minFlex + len(cols) + 1
+}
+
+// Returns the width the table gives to each of the given flex columns at the given total width.
+// Mirrors the table's own distribution of width.
+func flexWidths(cols []table.Column, total int) []int {
+	var (
+		widths    = make([]int, len(cols))
+		flex, gcd int
+	)
+	for _, c := range cols {
+		flex += c.FlexFactor()
+		gcd = gcdInt(gcd, c.FlexFactor())
+	}
+	if flex == 0 {
+		return widths
+	}
+	flex /= gcd
+	available := total - len(cols) - 1
+	unit, leftover := available/flex, available%flex
+	for i, c := range cols {
+		widths[i] = unit * (c.FlexFactor() / gcd)
+		if leftover > 0 {
+			widths[i]++
+			leftover--
+		}
+		if i == len(cols)-1 {
+			widths[i] += leftover
+		}
+		widths[i] = max(widths[i], 1)
+	}
+	return widths
+}
+
+func gcdInt(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// Scrolls the table horizontally, if necessary, so the cursor's column is displayed.
+func (tt *tableTab) scrollToCursor() {
+	if tt.width == 0 {
+		return
+	}
+	var (
+		cols   = tt.displayColumns()
+		widths = flexWidths(cols, max(tt.width, minTableWidth(cols)))
+		freeze = 1 + tt.layout.Frozen
+		pos    = slices.Index(tt.layout.visible(), tt.column) + 1 // position among cols
+		offset = tt.tbl.GetHorizontalScrollColumnOffset()
+		target = offset
+	)
+	if pos < freeze { // frozen columns are always displayed
+		return
+	}
+	if pos < freeze+target {
+		target = pos - freeze
+	}
+	for target < pos-freeze && !columnDisplayed(widths, tt.width, freeze, target, pos) {
+		target++
+	}
+	for ; offset < target; offset++ {
+		tt.tbl = tt.tbl.ScrollRight()
+	}
+	for ; offset > target; offset-- {
+		tt.tbl = tt.tbl.ScrollLeft()
+	}
+}
+
+// Returns whether the column at pos fits within the given width when the table is scrolled
+// offset columns past its frozen columns.
+func columnDisplayed(widths []int, width, freeze, offset, pos int) bool {
+	const overflowWidth = 2 // scroll indicator and its border
+	w := 1                  // left border
+	for i := 0; i < freeze && i < len(widths); i++ {
+		w += widths[i] + 1
+	}
+	if offset > 0 {
+		w += overflowWidth
+	}
+	for i := freeze + offset; i <= pos && i < len(widths); i++ {
+		w += widths[i] + 1
+	}
+	if pos < len(widths)-1 {
+		w += overflowWidth
+	}
+	return w <= width
+}
+
+//#endregion display
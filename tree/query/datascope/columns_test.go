@@ -0,0 +1,89 @@
+package datascope
+
+import (
+	"gwcli/clilog"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/gravwell/gravwell/v3/client/types"
+)
+
+func Test_compareCells(t *testing.T) {
+	ascending := []string{"", "-3", "2", "9", "10", "10.5", "host2", "host10", "host10a", "x"}
+	got := []string{"host10", "10.5", "x", "9", "host2", "", "-3", "host10a", "10", "2"}
+	sort.SliceStable(got, func(i, j int) bool { return compareCells(got[i], got[j]) < 0 })
+	if !reflect.DeepEqual(got, ascending) {
+		t.Errorf("sorted = %q, want %q", got, ascending)
+	}
+}
+
+func Test_columnLayout_valid(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout columnLayout
+		want   bool
+	}{
+		{"default", defaultLayout(3), true},
+		{"reordered", columnLayout{Order: []int{2, 0, 1}, Hidden: []int{0}, Frozen: 1}, true},
+		{"wrong count", columnLayout{Order: []int{0, 1}}, false},
+		{"duplicate", columnLayout{Order: []int{0, 1, 1}}, false},
+		{"all hidden", columnLayout{Order: []int{0, 1, 2}, Hidden: []int{0, 1, 2}}, false},
+		{"bad flex", columnLayout{Order: []int{0, 1, 2}, Flex: []int{5}}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.layout.valid(3); got != tt.want {
+			t.Errorf("%v: valid() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func Test_tableTab_columns(t *testing.T) {
+	if err := clilog.Init(t.TempDir()+"/log.txt", "DEBUG"); err != nil {
+		t.Fatal(err)
+	}
+	layoutsPath = t.TempDir() + "/layouts.json"
+	cols := []string{"host", "count", "user"}
+	rows := []types.TableRow{
+		{Row: []string{"web10", "5", "alice"}},
+		{Row: []string{"web2", "40", "bob"}},
+		{Row: []string{"web1", "5", "carol"}},
+	}
+	tt := initTableTab(cols, rows)
+	tt.recalculateSize(80, 20)
+
+	// sort by count: ascending, then descending, then back to the original order
+	tt.stepColumn(1)
+	for _, want := range [][]int{{0, 2, 1}, {1, 0, 2}, {0, 1, 2}} {
+		tt.cycleSort()
+		if !reflect.DeepEqual(tt.shown, want) {
+			t.Errorf("sorted rows = %v, want %v", tt.shown, want)
+		}
+	}
+
+	// move count to the front, hide user, and freeze count
+	tt.moveColumn(-1)
+	tt.toggleFreeze()
+	tt.stepColumn(2)
+	tt.hideColumn()
+	want := columnLayout{Order: []int{1, 0, 2}, Hidden: []int{2}, Frozen: 1}
+	if !reflect.DeepEqual(tt.layout, want) {
+		t.Fatalf("layout = %+v, want %+v", tt.layout, want)
+	}
+	var keys []string
+	for _, c := range tt.displayColumns() {
+		keys = append(keys, c.Key())
+	}
+	if want := []string{"index", "2", "1"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("displayed column keys = %v, want %v", keys, want)
+	}
+
+	// the layout is restored for the same columns
+	if restored := initTableTab(cols, rows); !reflect.DeepEqual(restored.layout, want) {
+		t.Errorf("restored layout = %+v, want %+v", restored.layout, want)
+	}
+	tt.showColumns()
+	if len(tt.layout.visible()) != 3 {
+		t.Errorf("showing all columns left %v visible", tt.layout.visible())
+	}
+}
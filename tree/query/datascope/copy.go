@@ -0,0 +1,211 @@
+package datascope
+
+/**
+ * Clipboard support for the results/table tab.
+ * Copying is done via OSC 52 escape sequences, which instruct the terminal itself to set the
+ * clipboard. Thus, copying works over SSH, so long as the terminal supports OSC 52.
+ *
+ * The outcome of each copy is briefly displayed in place of the tab's help footer.
+ */
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"gwcli/clilog"
+	"gwcli/stylesheet"
+	"gwcli/tree/query/transcode"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gravwell/gravwell/v3/client/types"
+)
+
+// how long the outcome of a copy is displayed in the footer
+const noticeDuration = 3 * time.Second
+
+var noticeStyle = func() lipgloss.Style { return lipgloss.NewStyle().Foreground(stylesheet.AccentColor1) }
+
+// copyResultMsg is returned once the clipboard sequence has been written.
+type copyResultMsg struct {
+	what string // description of what was copied
+	size int    // bytes copied
+	err  error
+}
+
+// clearNoticeMsg clears the footer notice, if it has not been replaced since.
+type clearNoticeMsg struct {
+	id uint
+}
+
+// Returns the OSC 52 sequence to set the clipboard to str, wrapped for passthrough by tmux or
+// screen if gwcli is running within one.
+func clipboardSequence(str string) osc52.Sequence {
+	seq := osc52.New(str)
+	if os.Getenv("TMUX") != "" {
+		seq = seq.Tmux()
+	} else if strings.HasPrefix(os.Getenv("TERM"), "screen") {
+		seq = seq.Screen()
+	}
+	return seq
+}
+
+// Returns a command that writes str to the terminal's clipboard.
+// The sequence is written to stderr so it does not interleave with Bubble Tea's renderer.
+func copyToClipboard(what, str string) tea.Cmd {
+	return func() tea.Msg {
+		_, err := clipboardSequence(str).WriteTo(os.Stderr)
+		if err != nil {
+			clilog.Writer.Warnf("failed to copy %v to the clipboard: %v", what, err)
+		}
+		return copyResultMsg{what: what, size: len(str), err: err}
+	}
+}
+
+// Returns the given records as a new set of results.
+func subsetResults(res transcode.Results, records []int) transcode.Results {
+	sub := res
+	if res.Table() {
+		sub.Rows = make([]types.TableRow, len(records))
+		for i, r := range records {
+			sub.Rows[i] = res.Rows[r]
+		}
+	} else {
+		sub.Entries = make([]types.SearchEntry, len(records))
+		for i, r := range records {
+			sub.Entries[i] = res.Entries[r]
+		}
+	}
+	return sub
+}
+
+// Returns the given records, transcoded and stripped of the trailing newline.
+func transcodeRecords(res transcode.Results, f transcode.Format, records []int) (string, error) {
+	var buf bytes.Buffer
+	if err := transcode.Write(&buf, f, subsetResults(res, records)); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// Returns the given fields as a single line of CSV.
+func csvLine(fields []string) (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(fields); err != nil {
+		return "", err
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+//#region DS integration
+
+// Copies the record under the cursor: a text entry's data or a table row as CSV.
+func (s *DataScope) copyRecord() tea.Cmd {
+	cur := s.currentRecord()
+	if !s.anyShown() || cur >= s.records.Len() {
+		return s.flash(stylesheet.ErrStyle.Render("no record to copy"))
+	}
+	if !s.tableMode {
+		return copyToClipboard(fmt.Sprintf("record %d", cur+1), string(s.records.Entries[cur].Data))
+	}
+	line, err := csvLine(s.records.Rows[cur].Row)
+	if err != nil {
+		return s.flash(stylesheet.ErrStyle.Render(err.Error()))
+	}
+	return copyToClipboard(fmt.Sprintf("row %d as CSV", cur+1), line)
+}
+
+// Copies the record under the cursor as a JSON object.
+func (s *DataScope) copyRecordJSON() tea.Cmd {
+	cur := s.currentRecord()
+	if !s.anyShown() || cur >= s.records.Len() {
+		return s.flash(stylesheet.ErrStyle.Render("no record to copy"))
+	}
+	obj, err := transcodeRecords(s.records, transcode.NDJSON, []int{cur})
+	if err != nil {
+		return s.flash(stylesheet.ErrStyle.Render(err.Error()))
+	}
+	what := "record"
+	if s.tableMode {
+		what = "row"
+	}
+	return copyToClipboard(fmt.Sprintf("%s %d as JSON", what, cur+1), obj)
+}
+
+// Copies the cell under the table's cursor.
+func (s *DataScope) copyCell() tea.Cmd {
+	cur, col := s.table.cursor, s.table.column
+	if !s.anyShown() || cur >= len(s.records.Rows) || col >= len(s.records.Rows[cur].Row) ||
+		col >= len(s.records.Columns) {
+		return s.flash(stylesheet.ErrStyle.Render("no cell to copy"))
+	}
+	return copyToClipboard(fmt.Sprintf("%s of row %d", s.records.Columns[col], cur+1),
+		s.records.Rows[cur].Row[col])
+}
+
+// Copies the selected records: text entries' data, one per line, or table rows as CSV (with a
+// header).
+func (s *DataScope) copySelection() tea.Cmd {
+	if len(s.selected) == 0 {
+		return s.flash(stylesheet.ErrStyle.Render("no records selected"))
+	}
+	records := s.selected.indices()
+	str, err := transcodeRecords(s.records, transcode.Raw, records)
+	if err != nil {
+		return s.flash(stylesheet.ErrStyle.Render(err.Error()))
+	}
+	return copyToClipboard(fmt.Sprintf("%d selected records", len(records)), str)
+}
+
+// Displays the given notice in the footer until it expires or is replaced.
+func (s *DataScope) flash(notice string) tea.Cmd {
+	s.noticeID++
+	s.setNotice(notice)
+	id := s.noticeID
+	return tea.Tick(noticeDuration, func(time.Time) tea.Msg { return clearNoticeMsg{id: id} })
+}
+
+func (s *DataScope) setNotice(notice string) {
+	if s.tableMode {
+		s.table.notice = notice
+	} else {
+		s.results.notice = notice
+	}
+}
+
+// Handles the outcome of a copy or the expiry of a notice.
+func (s *DataScope) updateNotice(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case copyResultMsg:
+		if msg.err != nil {
+			return s.flash(stylesheet.ErrStyle.Render("failed to copy " + msg.what))
+		}
+		return s.flash(noticeStyle().Render(fmt.Sprintf("copied %v (%d bytes)", msg.what, msg.size)))
+	case clearNoticeMsg:
+		if msg.id == s.noticeID {
+			s.setNotice("")
+		}
+	}
+	return nil
+}
+
+//#endregion
+
+// Returns the help text or, if there is a notice, the notice centered in its place (occupying the
+// same number of lines).
+func helpOrNotice(help, notice string, width int) string {
+	if notice == "" {
+		return help
+	}
+	return lipgloss.NewStyle().Width(width).Height(lipgloss.Height(help)).
+		AlignHorizontal(lipgloss.Center).AlignVertical(lipgloss.Center).Render(notice)
+}
@@ -0,0 +1,51 @@
+package datascope
+
+import (
+	"gwcli/tree/query/transcode"
+	"testing"
+
+	"github.com/gravwell/gravwell/v3/client/types"
+)
+
+func Test_transcodeRecords(t *testing.T) {
+	table := transcode.Results{Columns: []string{"a", "b"}, Rows: []types.TableRow{
+		{Row: []string{"1", "x,y"}},
+		{Row: []string{"2", "z"}},
+		{Row: []string{"3", `"q"`}},
+	}}
+	text := transcode.Results{Entries: []types.SearchEntry{
+		{Data: []byte("one")}, {Data: []byte("two")}, {Data: []byte("three")},
+	}}
+	tests := []struct {
+		name    string
+		res     transcode.Results
+		f       transcode.Format
+		records []int
+		want    string
+	}{
+		{"text selection", text, transcode.Raw, []int{0, 2}, "one\nthree"},
+		{"table selection", table, transcode.Raw, []int{0, 2}, "a,b\n1,\"x,y\"\n3,\"\"\"q\"\"\""},
+		{"table row as JSON", table, transcode.NDJSON, []int{1}, `{"a":"2","b":"z"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := transcodeRecords(tt.res, tt.f, tt.records)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("transcodeRecords() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_csvLine(t *testing.T) {
+	got, err := csvLine([]string{"a", "b c", "d,e", `"f"`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `a,b c,"d,e","""f"""`; got != want {
+		t.Errorf("csvLine() = %q, want %q", got, want)
+	}
+}
@@ -16,6 +16,8 @@ import (
 	"errors"
 	"gwcli/clilog"
 	activesearchlock "gwcli/tree/query/datascope/ActiveSearchLock"
+	"gwcli/tree/query/transcode"
+	"gwcli/utilities/keymap"
 	"gwcli/utilities/killer"
 	"os"
 	"time"
@@ -31,7 +33,7 @@ import (
 //#region keepAlive syncronization
 
 const (
-	pingFrequency = 50 * time.Second
+	PingFrequency = 50 * time.Second     // how often a search is pinged to keep it alive
 	ageOut        = 5 * 60 * time.Second // 5 minutes
 )
 
@@ -95,7 +97,15 @@ func keepAlive(search *grav.Search) {
 			break
 		}
 		clilog.Writer.Debugf("pinged search %v", mysid)
-		time.Sleep(pingFrequency)
+		time.Sleep(PingFrequency)
+	}
+}
+
+// KeepAlive updates the timestamp keepAlive checks, as Update does, so the search does not age out
+// while DataScope is not being updated (ex: while Mother holds it in the background).
+func (s DataScope) KeepAlive() {
+	if s.search != nil && activesearchlock.GetSearchID() == s.search.ID {
+		activesearchlock.UpdateTS()
 	}
 }
 
@@ -113,8 +123,21 @@ type DataScope struct {
 
 	search *grav.Search // the search being displayed
 
-	download downloadTab
-	schedule scheduleTab
+	download  downloadTab
+	schedule  scheduleTab
+	stats     statsTab
+	histogram histogramTab
+	fields    fieldsTab
+	pipe      pipeOverlay  // overlays the results/table tab when active
+	pivot     pivotOverlay // overlays the results/table tab when active
+	pivotFunc PivotFunc    // runs pivot queries; nil if pivoting is unavailable
+	back      *DataScope   // DataScope replaced by the most recent pivot; nil if none
+	detail    detailPane   // overlays the results/table tab when active
+	find      finder       // search and filter of the results/table tab
+	selected  selection    // records selected in the results/table tab
+	noticeID  uint         // id of the current footer notice, so stale expiries can be ignored
+
+	records transcode.Results // structured results backing the display data
 
 	tableMode bool
 	table     tableTab
@@ -123,13 +146,13 @@ type DataScope struct {
 
 type DataScopeOption func(*DataScope) error
 
-// Returns a new DataScope instance based on the given data array.
+// Returns a new DataScope instance based on the given results.
 // If mother is running, this subroutine will launch her into the alt screen buffer and query the
 // terminal for its size.
-// Table mode indicates if the results should be displayed in a tabular method, replacing the normal
+// Tabular results (see transcode.Results.Table()) are displayed in table mode, replacing the normal
 // display method/struct.
-func NewDataScope(data []string, motherRunning bool,
-	search *grav.Search, table bool, opt ...DataScopeOption,
+func NewDataScope(res transcode.Results, motherRunning bool,
+	search *grav.Search, opt ...DataScopeOption,
 ) (
 	DataScope, tea.Cmd, error,
 ) {
@@ -137,15 +160,21 @@ func NewDataScope(data []string, motherRunning bool,
 	if search == nil {
 		return DataScope{}, nil, errors.New("search cannot be nil")
 	}
-	if len(data) == 0 {
+	if res.Len() == 0 {
 		return DataScope{}, nil, errors.New("no data to display")
 	}
 
 	s := DataScope{
-		tableMode:     table,
+		records:       res,
+		tableMode:     res.Table(),
 		motherRunning: motherRunning,
-		download:      initDownloadTab("", false, false, false),
+		download:      initDownloadTab("", false, transcode.Raw, transcode.Projection{}),
 		schedule:      initScheduleTab("", "", ""),
+		pipe:          initPipe(),
+		pivot:         initPivot(),
+		detail:        detailPane{vp: NewViewport()},
+		find:          initFinder(),
+		selected:      selection{},
 	}
 
 	// set up tabs
@@ -162,10 +191,21 @@ func NewDataScope(data []string, motherRunning bool,
 		s.tabs[results].name = "table"
 		s.tabs[results].updateFunc = updateTable
 		s.tabs[results].viewFunc = viewTable
-		s.table = initTableTab(data)
+		s.table = initTableTab(res.Columns, res.Rows)
+		s.table.selected = s.selected
 	} else {
+		data := make([]string, len(res.Entries))
+		for i, e := range res.Entries {
+			data[i] = string(e.Data)
+		}
 		s.results = initResultsTab(data)
+		s.results.selected = s.selected
 	}
+	s.fields = initFieldsTab(res)
+
+	// the search's metrics and time range are fetched asynchronously (see fetchStats)
+	s.stats = initStatsTab()
+	s.histogram = initHistogramTab(s.recordTimes(), time.Time{}, time.Time{})
 
 	// store data for keepAlive
 	activesearchlock.SetSearchID(search.ID)
@@ -182,29 +222,26 @@ func NewDataScope(data []string, motherRunning bool,
 
 	// mother does not start in alt screen, and thus requires manual measurements
 	if motherRunning {
-		return s, tea.Sequence(tea.EnterAltScreen, func() tea.Msg {
+		return s, tea.Batch(fetchStats(search), tea.Sequence(tea.EnterAltScreen, func() tea.Msg {
 			w, h, err := term.GetSize(os.Stdin.Fd())
 			if err != nil {
 				clilog.Writer.Errorf("Failed to fetch terminal size: %v", err)
 			}
 			return tea.WindowSizeMsg{Width: w, Height: h}
-		}), nil
+		})), nil
 	}
 
-	return s, nil, nil
+	return s, fetchStats(search), nil
 }
 
 //#region constructor options
 
-// Prep-populate the download tab's values and, if able, automatically download the results in the
-// given format.
-func WithAutoDownload(outfn string, append, json, csv bool) DataScopeOption {
+// Prep-populate the download tab's values and, if able, automatically download the (projected)
+// results in the given format.
+func WithAutoDownload(outfn string, append bool, format transcode.Format,
+	project transcode.Projection) DataScopeOption {
 	return func(ds *DataScope) error {
-		if json && csv {
-			return errors.New("output format cannot be both JSON and CSV")
-		}
-
-		ds.download = initDownloadTab(outfn, append, json, csv)
+		ds.download = initDownloadTab(outfn, append, format, project)
 		if outfn != "" {
 			res, success := ds.dl(outfn)
 			ds.download.resultString = res
@@ -230,10 +267,42 @@ func WithSchedule(cronfreq, name, desc string) DataScopeOption {
 	}
 }
 
+// Fix the number of records per page of the results tab.
+// 0 (the default) sizes pages to fit the terminal.
+func WithPageSize(size int) DataScopeOption {
+	return func(ds *DataScope) error {
+		if size < 0 {
+			return errors.New("page size cannot be negative")
+		}
+		if size > 0 {
+			ds.results.pageSize = size
+			ds.results.pager.PerPage = size
+			ds.results.setTotalPages()
+		}
+		return nil
+	}
+}
+
+// Enable pivoting from a table cell or JSON field into a new query, run via the given function.
+func WithPivot(run PivotFunc) DataScopeOption {
+	return func(ds *DataScope) error {
+		ds.pivotFunc = run
+		return nil
+	}
+}
+
+// Open DataScope on the stats tab, rather than the results.
+func WithStats() DataScopeOption {
+	return func(ds *DataScope) error {
+		ds.activeTab = stats
+		return nil
+	}
+}
+
 //#endregion
 
 func (s DataScope) Init() tea.Cmd {
-	return nil
+	return fetchStats(s.search)
 }
 
 func (s DataScope) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -248,9 +317,85 @@ func (s DataScope) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// update the timestamp to keep the heartbeat going
 	activesearchlock.UpdateTS()
 
+	// the pipe overlay consumes all input while active
+	if s.pipe.state != pipeInactive {
+		switch msg.(type) {
+		case tea.KeyMsg, pipeResultMsg:
+			return s, updatePipe(&s, msg)
+		}
+	}
+
+	// as does the pivot overlay, whose query replaces this DataScope once it completes
+	if s.pivot.state != pivotInactive {
+		switch msg.(type) {
+		case tea.KeyMsg, pivotResultMsg:
+			next, cmd := updatePivot(&s, msg)
+			if next != nil {
+				return *next, cmd
+			}
+			return s, cmd
+		}
+	}
+
+	// as does the detail pane
+	if msg, ok := msg.(tea.KeyMsg); ok && s.detail.active {
+		return s, updateDetail(&s, msg)
+	}
+
+	// as do the search and filter prompts
+	if msg, ok := msg.(tea.KeyMsg); ok && s.find.prompt != findNone {
+		return s, updateFinder(&s, msg)
+	}
+
+	// prompts within the active tab consume all keys
+	if _, ok := msg.(tea.KeyMsg); ok && s.promptActive() {
+		return s, s.tabs[s.activeTab].updateFunc(&s, msg)
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg: // tab-agnostic keys
 		switch {
+		case key.Matches(msg, keys.pipe) && s.activeTab == results:
+			return s, s.openPipe()
+		case key.Matches(msg, keys.pivot) && s.activeTab == results && s.tableMode:
+			return s, s.openPivot()
+		case key.Matches(msg, keys.back) && s.activeTab == results && s.back != nil:
+			return *s.popBack(), textinput.Blink
+		case key.Matches(msg, keys.openDetail) && s.activeTab == results:
+			if s.anyShown() {
+				s.openDetail(s.currentRecord())
+			}
+			return s, nil
+		case key.Matches(msg, keys.copyRecord) && s.activeTab == results:
+			return s, s.copyRecord()
+		case key.Matches(msg, keys.copyJSON) && s.activeTab == results:
+			return s, s.copyRecordJSON()
+		case key.Matches(msg, keys.copySelection) && s.activeTab == results:
+			return s, s.copySelection()
+		case key.Matches(msg, keys.copyCell) && s.activeTab == results && s.tableMode:
+			return s, s.copyCell()
+		case key.Matches(msg, keys.search) && s.activeTab == results:
+			return s, s.openFinder(findSearch)
+		case key.Matches(msg, keys.filter) && s.activeTab == results:
+			return s, s.openFinder(findFilter)
+		case key.Matches(msg, keys.toggleSelect) && s.activeTab == results:
+			s.toggleSelected()
+			return s, nil
+		case key.Matches(msg, keys.selectDown) && s.activeTab == results:
+			s.extendSelection(true)
+			return s, nil
+		case key.Matches(msg, keys.selectUp) && s.activeTab == results:
+			s.extendSelection(false)
+			return s, nil
+		case key.Matches(msg, keys.selectAll) && s.activeTab == results:
+			s.toggleSelectAll()
+			return s, nil
+		case key.Matches(msg, keys.nextMatch) && s.activeTab == results && s.find.search != nil:
+			s.nextMatch(true)
+			return s, nil
+		case key.Matches(msg, keys.prevMatch) && s.activeTab == results && s.find.search != nil:
+			s.nextMatch(false)
+			return s, nil
 		case key.Matches(msg, keys.showTabs):
 			s.showTabs = !s.showTabs
 			// recalculate height and update display
@@ -272,6 +417,11 @@ func (s DataScope) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return s, textinput.Blink
 		}
 
+	case copyResultMsg, clearNoticeMsg:
+		return s, s.updateNotice(msg)
+	case statsMsg:
+		s.applyStats(msg)
+		return s, nil
 	case tea.WindowSizeMsg:
 		s.rawHeight = msg.Height
 		s.rawWidth = msg.Width
@@ -284,18 +434,50 @@ func (s DataScope) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (s DataScope) View() string {
+	var view string
+	if s.pipe.state != pipeInactive {
+		view = viewPipe(&s)
+	} else if s.pivot.state != pivotInactive {
+		view = viewPivot(&s)
+	} else if s.detail.active {
+		view = viewDetail(&s)
+	} else {
+		view = s.tabs[s.activeTab].viewFunc(&s)
+		if s.activeTab == results && s.find.visible() {
+			view += "\n" + s.viewFinder()
+		}
+	}
 	if s.showTabs {
-		return s.renderTabs(s.rawWidth) + "\n" + s.tabs[s.activeTab].viewFunc(&s)
+		return s.renderTabs(s.rawWidth) + "\n" + view
+	}
+	return view
+}
+
+// Hands the fetched stats to the DataScope displaying their search, which may have since been
+// replaced by a pivot.
+func (s *DataScope) applyStats(msg statsMsg) {
+	for ds := s; ds != nil; ds = ds.back {
+		if ds.search == nil || ds.search.ID != msg.sid || ds.stats.fetched {
+			continue
+		}
+		ds.stats.setStats(msg.stats, msg.err)
+		if msg.err == nil {
+			ds.histogram.widen(msg.stats.Start, msg.stats.End)
+		}
+		return
 	}
-	return s.tabs[s.activeTab].viewFunc(&s)
 }
 
 // Creates a new bubble tea program, in alt buffer mode, running only the DataScope.
 // For use from Cobra.Run() subroutines.
 // Start the returned program via .Run().
-func CobraNew(data []string, search *grav.Search, table bool, opts ...DataScopeOption,
+// Returns an error if the user's keymap is invalid.
+func CobraNew(res transcode.Results, search *grav.Search, opts ...DataScopeOption,
 ) (p *tea.Program, err error) {
-	ds, _, err := NewDataScope(data, false, search, table, opts...)
+	if err := keymap.Validate(); err != nil {
+		return nil, err
+	}
+	ds, _, err := NewDataScope(res, false, search, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -324,12 +506,28 @@ func (s *DataScope) recalculateWindowMargins(rawWidth, rawHeight int) {
 	if s.showTabs {
 		clippedHeight -= lipgloss.Height(s.renderTabs(s.rawWidth))
 	}
-	// inform the appropriate tab of the size change
+	// inform the appropriate tab of the size change, reserving space for the finder's bar
+	resultsHeight := clippedHeight
+	if s.find.visible() {
+		resultsHeight -= lipgloss.Height(s.viewFinder())
+	}
 	if s.tableMode {
-		s.table.recalculateSize(rawWidth, clippedHeight)
+		s.table.recalculateSize(rawWidth, resultsHeight)
 	} else {
-		s.results.recalculateSize(rawWidth, clippedHeight)
+		s.results.recalculateSize(rawWidth, resultsHeight)
 	}
+	s.stats.recalculateSize(rawWidth, clippedHeight)
+	s.histogram.recalculateSize(rawWidth, clippedHeight)
+	s.fields.recalculateSize(rawWidth, clippedHeight, s.find.cell)
+	s.pipe.recalculateSize(rawWidth, clippedHeight)
+	s.pivot.recalculateSize(rawWidth)
+	s.detail.recalculateSize(rawWidth, clippedHeight)
+}
+
+// Returns whether the active tab has a prompt open that should receive all key input, including
+// those DS would otherwise intercept (such as tab cycling).
+func (s *DataScope) promptActive() bool {
+	return s.activeTab == results && !s.tableMode && s.results.gotoMode != gotoNone
 }
 
 // Returns the width of the terminal available for tabs to use, minus any margins reserved by the
@@ -0,0 +1,262 @@
+package datascope
+
+/**
+ * The detail pane displays a single record in full: its metadata (timestamp, tag, source, and
+ * enumerated values) and its data, pretty-printed according to its detected format.
+ * JSON is displayed as a collapsible tree, navigated with a cursor.
+ * Like the pipe overlay, it sits atop the results/table tab and consumes all key input while open.
+ */
+
+import (
+	"fmt"
+	"gwcli/stylesheet"
+	"gwcli/utilities/keymap"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var detailKeys = struct {
+	toggle      key.Binding // collapse/expand the node under the cursor
+	collapse    key.Binding // collapse the node or move to its parent
+	expand      key.Binding // expand the node or move to its first child
+	collapseAll key.Binding
+	expandAll   key.Binding
+	close       key.Binding
+}{
+	toggle:      keymap.Bind(detailScope, "toggle", "collapse/expand", " ", "enter"),
+	collapse:    keymap.Bind(detailScope, "collapse", "collapse or move to parent", "left", "h"),
+	expand:      keymap.Bind(detailScope, "expand", "expand or move to first child", "right", "l"),
+	collapseAll: keymap.Bind(detailScope, "collapse-all", "collapse all", "C"),
+	expandAll:   keymap.Bind(detailScope, "expand-all", "expand all", "E"),
+	close:       keymap.Bind(detailScope, "close", "close", "q"),
+}
+
+type detailPane struct {
+	active bool
+	record int // index of the displayed record
+	format dataFormat
+	meta   string // rendered metadata
+	body   string // rendered data; unused if the data is displayed as a tree
+
+	tree   *jsonNode   // nil unless the data is JSON
+	nodes  []*jsonNode // visible tree nodes, in display order
+	cursor int         // index in nodes
+
+	vp    viewport.Model
+	width int
+}
+
+// Opens the detail pane on the given record.
+func (s *DataScope) openDetail(idx int) {
+	if idx < 0 || idx >= s.records.Len() {
+		return
+	}
+	d := detailPane{active: true, record: idx, vp: s.detail.vp, width: s.detail.width}
+
+	var meta [][2]string
+	if s.tableMode {
+		row := s.records.Rows[idx]
+		meta = append(meta, [2]string{"Timestamp", formatTimestamp(row.TS.StandardTime())})
+		cols := make([][2]string, len(s.records.Columns))
+		for i, c := range s.records.Columns {
+			if i < len(row.Row) {
+				cols[i] = [2]string{c, row.Row[i]}
+			} else {
+				cols[i] = [2]string{c, ""}
+			}
+		}
+		d.format, d.body = formatKV, renderFields(cols)
+	} else {
+		e := s.records.Entries[idx]
+		meta = append(meta,
+			[2]string{"Timestamp", formatTimestamp(e.TS.StandardTime())},
+			[2]string{"Tag", s.records.TagName(e.Tag)},
+			[2]string{"Source", e.SRC.String()})
+		for _, ev := range e.Enumerated {
+			meta = append(meta, [2]string{ev.Name, ev.Value})
+		}
+		d.format = detectFormat(e.Data)
+		d.body = renderData(d.format, e.Data)
+		if d.format == formatJSON {
+			if tree, err := parseJSONTree(e.Data); err == nil {
+				d.tree = tree
+				d.nodes = tree.visible(nil)
+			}
+		}
+	}
+	d.meta = renderFields(meta)
+	s.detail = d
+	s.detail.refresh()
+	s.detail.vp.GotoTop()
+}
+
+func formatTimestamp(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
+
+// Returns the data pretty-printed in the given format, falling back to the raw data.
+func renderData(f dataFormat, data []byte) string {
+	switch f {
+	case formatXML:
+		if s, err := prettyXML(data); err == nil {
+			return s
+		}
+	case formatKV:
+		if pairs := kvPairs(strings.TrimSpace(string(data))); pairs != nil {
+			return renderFields(pairs)
+		}
+	case formatSyslog:
+		if pairs := syslogFields(strings.TrimSpace(string(data))); pairs != nil {
+			return renderFields(pairs)
+		}
+	}
+	return string(data)
+}
+
+// Returns the number of lines preceding the body within the viewport.
+func (dp *detailPane) bodyOffset() int {
+	return lipgloss.Height(dp.meta) + 1
+}
+
+// Redraws the pane's content, keeping the cursor in view.
+func (dp *detailPane) refresh() {
+	var body string
+	if dp.tree != nil {
+		lines := make([]string, len(dp.nodes))
+		trunc := lipgloss.NewStyle().MaxWidth(dp.width) // tree lines must not wrap
+		for i, n := range dp.nodes {
+			prefix := " "
+			if i == dp.cursor {
+				prefix = lipgloss.NewStyle().Foreground(stylesheet.FocusedColor).
+					Render(string(stylesheet.SelectionPrefix))
+			}
+			lines[i] = trunc.Render(prefix + n.String())
+		}
+		body = strings.Join(lines, "\n")
+	} else {
+		body = wrap(dp.width, dp.body)
+	}
+	dp.vp.SetContent(dp.meta + "\n\n" + body)
+
+	if dp.tree != nil { // scroll the cursor into view
+		line := dp.bodyOffset() + dp.cursor
+		if line < dp.vp.YOffset {
+			dp.vp.SetYOffset(line)
+		} else if line >= dp.vp.YOffset+dp.vp.Height {
+			dp.vp.SetYOffset(line - dp.vp.Height + 1)
+		}
+	}
+}
+
+// Handles input while the detail pane is open.
+func updateDetail(s *DataScope, msg tea.KeyMsg) tea.Cmd {
+	dp := &s.detail
+	if key.Matches(msg, keys.cancel, detailKeys.close) {
+		dp.active = false
+		return nil
+	}
+	if key.Matches(msg, keys.pivot) {
+		return s.openPivot()
+	}
+	if dp.tree == nil {
+		if !viewportAddtlKeys(msg, &dp.vp) {
+			dp.vp, _ = dp.vp.Update(msg)
+		}
+		return nil
+	}
+
+	// tree navigation
+	node := dp.nodes[dp.cursor]
+	switch {
+	case key.Matches(msg, dp.vp.KeyMap.Up):
+		dp.cursor = max(dp.cursor-1, 0)
+	case key.Matches(msg, dp.vp.KeyMap.Down):
+		dp.cursor = min(dp.cursor+1, len(dp.nodes)-1)
+	case key.Matches(msg, detailKeys.toggle):
+		if node.container {
+			node.collapsed = !node.collapsed
+		}
+	case key.Matches(msg, detailKeys.collapse):
+		if node.container && !node.collapsed {
+			node.collapsed = true
+		} else if node.parent != nil {
+			dp.focus(node.parent)
+		}
+	case key.Matches(msg, detailKeys.expand):
+		if node.container && node.collapsed {
+			node.collapsed = false
+		} else if len(node.children) > 0 {
+			dp.focus(node.children[0])
+		}
+	case key.Matches(msg, detailKeys.collapseAll):
+		dp.tree.setCollapsedBelow(true)
+		dp.cursor = 0
+	case key.Matches(msg, detailKeys.expandAll):
+		dp.tree.setCollapsedBelow(false)
+	default: // paging and other viewport keys
+		if !viewportAddtlKeys(msg, &dp.vp) {
+			dp.vp, _ = dp.vp.Update(msg)
+		}
+		return nil
+	}
+	focused := dp.nodes[dp.cursor]
+	dp.nodes = dp.tree.visible(nil)
+	dp.focus(focused)
+	return nil
+}
+
+// Moves the cursor to the given node, if it is visible.
+func (dp *detailPane) focus(n *jsonNode) {
+	for i, v := range dp.nodes {
+		if v == n {
+			dp.cursor = i
+			break
+		}
+	}
+	dp.refresh()
+}
+
+func viewDetail(s *DataScope) string {
+	dp := &s.detail
+	header := stylesheet.Header1Style.Render(fmt.Sprintf("Record %d", dp.record+1)) + " " +
+		stylesheet.GreyedOutStyle.Render("("+dp.format.String()+")")
+	var (
+		scroll    = hint(viewportKeys.Up, viewportKeys.Down)
+		closeKeys = hint(detailKeys.close, keys.cancel)
+		help      = fmt.Sprintf("%v scroll • %v: close", scroll, closeKeys)
+	)
+	if dp.tree != nil {
+		help = fmt.Sprintf("%v move • %v: toggle • %v collapse/expand • %v: collapse/expand all"+
+			" • %v: pivot • %v: close", scroll, hint(detailKeys.toggle),
+			hint(detailKeys.collapse, detailKeys.expand), hint(detailKeys.collapseAll, detailKeys.expandAll),
+			hint(keys.pivot), closeKeys)
+	} else if s.tableMode {
+		help = fmt.Sprintf("%v scroll • %v: pivot on the cursor's cell • %v: close", scroll,
+			hint(keys.pivot), closeKeys)
+	}
+	notice := s.results.notice
+	if s.tableMode {
+		notice = s.table.notice
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		header,
+		dp.vp.View(),
+		scrollPercentLine(dp.vp.Width, dp.vp.ScrollPercent()),
+		helpOrNotice(lipgloss.NewStyle().Width(dp.vp.Width).AlignHorizontal(lipgloss.Center).
+			Render(stylesheet.GreyedOutStyle.Render(help)), notice, dp.vp.Width))
+}
+
+// The clipped height is the height available to the pane (height - tabs height).
+func (dp *detailPane) recalculateSize(rawWidth, clippedHeight int) {
+	dp.width = rawWidth
+	dp.vp.Width = rawWidth
+	dp.vp.Height = max(1, clippedHeight-3) // header, scroll line, and help
+	if dp.active {
+		dp.refresh()
+	}
+}
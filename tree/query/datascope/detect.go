@@ -0,0 +1,334 @@
+package datascope
+
+/**
+ * Data format detection and pretty-printing for the detail pane.
+ * Supported formats are JSON (displayed as a collapsible tree), XML, key-value pairs, and syslog
+ * (RFC 5424 and RFC 3164 headers). Anything else is displayed as plain text.
+ */
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"gwcli/stylesheet"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+type dataFormat uint
+
+const (
+	formatText dataFormat = iota
+	formatJSON
+	formatXML
+	formatKV
+	formatSyslog
+)
+
+func (df dataFormat) String() string {
+	switch df {
+	case formatText:
+		return "text"
+	case formatJSON:
+		return "json"
+	case formatXML:
+		return "xml"
+	case formatKV:
+		return "key-value"
+	case formatSyslog:
+		return "syslog"
+	}
+	return fmt.Sprintf("unknown format %d", df)
+}
+
+var (
+	fieldKeyStyle = func() lipgloss.Style { return lipgloss.NewStyle().Foreground(stylesheet.AccentColor1) }
+	stringStyle   = func() lipgloss.Style { return lipgloss.NewStyle().Foreground(stylesheet.SecondaryColor) }
+	numberStyle   = func() lipgloss.Style { return lipgloss.NewStyle().Foreground(stylesheet.TertiaryColor) }
+	literalStyle  = func() lipgloss.Style { // booleans and null
+		return lipgloss.NewStyle().Foreground(stylesheet.AccentColor2)
+	}
+)
+
+var (
+	// <pri>, optionally followed by an RFC 5424 version
+	syslogRgx = regexp.MustCompile(`^<(\d{1,3})>`)
+	// <pri>version timestamp hostname app-name procid msgid structured-data [msg]
+	rfc5424Rgx = regexp.MustCompile(
+		`^<(\d{1,3})>(\d{1,2}) (\S+) (\S+) (\S+) (\S+) (\S+) (-|(?:\[.*?[^\\]\])+)(?: (.*))?$`)
+	// <pri>Mmm dd hh:mm:ss hostname tag[pid]: msg
+	rfc3164Rgx = regexp.MustCompile(
+		`^<(\d{1,3})>([A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2}) (\S+) ([^:\[\s]+)(?:\[(\d+)\])?: ?(.*)$`)
+	// key=value, where value may be quoted
+	kvRgx = regexp.MustCompile(`([A-Za-z_][\w.\-]*)=("(?:[^"\\]|\\.)*"|[^\s,;"]*)`)
+	// what may separate key-value pairs
+	kvSepRgx = regexp.MustCompile(`^[\s,;]*$`)
+)
+
+// detectFormat returns the most likely format of the given data.
+func detectFormat(data []byte) dataFormat {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return formatText
+	}
+	switch {
+	case (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid(trimmed):
+		return formatJSON
+	case syslogRgx.Match(trimmed):
+		return formatSyslog
+	case trimmed[0] == '<' && validXML(trimmed):
+		return formatXML
+	case kvPairs(string(trimmed)) != nil:
+		return formatKV
+	}
+	return formatText
+}
+
+// validXML returns whether the data is well-formed XML containing at least one element.
+func validXML(data []byte) bool {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var elements bool
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return elements
+		} else if err != nil {
+			return false
+		}
+		if _, ok := tok.(xml.StartElement); ok {
+			elements = true
+		}
+	}
+}
+
+// kvPairs returns the key-value pairs in s, in order, or nil if s is not entirely (at least two)
+// key-value pairs.
+func kvPairs(s string) [][2]string {
+	locs := kvRgx.FindAllStringSubmatchIndex(s, -1)
+	if len(locs) < 2 {
+		return nil
+	}
+	var (
+		pairs = make([][2]string, len(locs))
+		prev  int
+	)
+	for i, l := range locs {
+		if !kvSepRgx.MatchString(s[prev:l[0]]) { // stray text between pairs
+			return nil
+		}
+		val := s[l[4]:l[5]]
+		if uq, err := strconv.Unquote(val); err == nil {
+			val = uq
+		}
+		pairs[i] = [2]string{s[l[2]:l[3]], val}
+		prev = l[1]
+	}
+	if !kvSepRgx.MatchString(s[prev:]) {
+		return nil
+	}
+	return pairs
+}
+
+// renderFields returns the given key-value pairs as aligned, colored lines.
+func renderFields(pairs [][2]string) string {
+	var width int
+	for _, p := range pairs {
+		width = max(width, lipgloss.Width(p[0]))
+	}
+	lines := make([]string, len(pairs))
+	for i, p := range pairs {
+		// pad the key before styling it, so the escape codes do not skew alignment
+		lines[i] = fieldKeyStyle().Render(fmt.Sprintf("%-*s", width+1, p[0]+":")) + " " + p[1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// prettyXML re-indents the given XML, coloring its tags.
+func prettyXML(data []byte) (string, error) {
+	var (
+		buf bytes.Buffer
+		dec = xml.NewDecoder(bytes.NewReader(data))
+		enc = xml.NewEncoder(&buf)
+	)
+	enc.Indent("", "  ")
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return "", err
+		}
+		// drop whitespace-only character data; the encoder provides its own indentation
+		if cd, ok := tok.(xml.CharData); ok && len(bytes.TrimSpace(cd)) == 0 {
+			continue
+		}
+		if err := enc.EncodeToken(xml.CopyToken(tok)); err != nil {
+			return "", err
+		}
+	}
+	if err := enc.Flush(); err != nil {
+		return "", err
+	}
+	return xmlTagRgx.ReplaceAllStringFunc(buf.String(),
+		func(tag string) string { return fieldKeyStyle().Render(tag) }), nil
+}
+
+var xmlTagRgx = regexp.MustCompile(`</?[^\s>/]+|/?>`)
+
+var (
+	syslogFacilities = []string{"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+		"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+		"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7"}
+	syslogSeverities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info",
+		"debug"}
+)
+
+// syslogFields breaks a syslog message into its header fields and message.
+// Returns nil if the message matches neither RFC 5424 nor RFC 3164.
+func syslogFields(s string) [][2]string {
+	var (
+		pairs [][2]string
+		pri   string
+	)
+	if m := rfc5424Rgx.FindStringSubmatch(s); m != nil {
+		pri = m[1]
+		pairs = [][2]string{{"Version", m[2]}, {"Timestamp", m[3]}, {"Hostname", m[4]},
+			{"App", m[5]}, {"ProcID", m[6]}, {"MsgID", m[7]}, {"Structured Data", m[8]},
+			{"Message", m[9]}}
+	} else if m := rfc3164Rgx.FindStringSubmatch(s); m != nil {
+		pri = m[1]
+		pairs = [][2]string{{"Timestamp", m[2]}, {"Hostname", m[3]}, {"Tag", m[4]},
+			{"PID", m[5]}, {"Message", m[6]}}
+	} else {
+		return nil
+	}
+	if p, err := strconv.Atoi(pri); err == nil && p/8 < len(syslogFacilities) {
+		pairs = append([][2]string{
+			{"Facility", syslogFacilities[p/8]},
+			{"Severity", syslogSeverities[p%8]}}, pairs...)
+	}
+	return pairs
+}
+
+//#region JSON tree
+
+// jsonNode is a single value within a collapsible JSON tree.
+type jsonNode struct {
+	key       string // object key or array index; empty for the root
+	value     any    // scalar value; unused by containers
+	children  []*jsonNode
+	parent    *jsonNode
+	container bool // object or array
+	array     bool
+	collapsed bool
+	depth     int
+}
+
+// parseJSONTree decodes the given JSON into a tree, preserving the order of object keys.
+func parseJSONTree(data []byte) (*jsonNode, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return decodeJSONNode(dec, nil, "")
+}
+
+func decodeJSONNode(dec *json.Decoder, parent *jsonNode, key string) (*jsonNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	n := &jsonNode{key: key, parent: parent}
+	if parent != nil {
+		n.depth = parent.depth + 1
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		n.value = tok
+		return n, nil
+	}
+	n.container, n.array = true, delim == '['
+	for i := 0; dec.More(); i++ {
+		childKey := strconv.Itoa(i)
+		if !n.array {
+			kt, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			childKey, _ = kt.(string)
+		}
+		child, err := decodeJSONNode(dec, n, childKey)
+		if err != nil {
+			return nil, err
+		}
+		n.children = append(n.children, child)
+	}
+	if _, err := dec.Token(); err != nil { // consume the closing delimiter
+		return nil, err
+	}
+	return n, nil
+}
+
+// Appends the node and its visible descendants to out, in display order.
+func (n *jsonNode) visible(out []*jsonNode) []*jsonNode {
+	out = append(out, n)
+	if n.container && !n.collapsed {
+		for _, c := range n.children {
+			out = c.visible(out)
+		}
+	}
+	return out
+}
+
+// Collapses or expands every container beneath (but not including) this node.
+func (n *jsonNode) setCollapsedBelow(collapsed bool) {
+	for _, c := range n.children {
+		if c.container {
+			c.collapsed = collapsed
+			c.setCollapsedBelow(collapsed)
+		}
+	}
+}
+
+// Returns the node's line within the tree.
+func (n *jsonNode) String() string {
+	var sb strings.Builder
+	sb.WriteString(strings.Repeat("  ", n.depth))
+	switch {
+	case !n.container:
+		sb.WriteString("  ")
+	case n.collapsed:
+		sb.WriteString("▸ ")
+	default:
+		sb.WriteString("▾ ")
+	}
+	if n.parent != nil {
+		sb.WriteString(fieldKeyStyle().Render(n.key) + ": ")
+	}
+	if n.container {
+		open, close := "{", "}"
+		if n.array {
+			open, close = "[", "]"
+		}
+		sb.WriteString(stylesheet.GreyedOutStyle.Render(
+			fmt.Sprintf("%s%d%s", open, len(n.children), close)))
+		return sb.String()
+	}
+	switch v := n.value.(type) {
+	case string:
+		sb.WriteString(stringStyle().Render(strconv.Quote(v)))
+	case json.Number:
+		sb.WriteString(numberStyle().Render(v.String()))
+	case nil:
+		sb.WriteString(literalStyle().Render("null"))
+	default:
+		sb.WriteString(literalStyle().Render(fmt.Sprint(v)))
+	}
+	return sb.String()
+}
+
+//#endregion
@@ -0,0 +1,100 @@
+package datascope
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_detectFormat(t *testing.T) {
+	tests := []struct {
+		data string
+		want dataFormat
+	}{
+		{"", formatText},
+		{"hello world", formatText},
+		{`{"a": 1, "b": [true, null]}`, formatJSON},
+		{` [1, 2, 3] `, formatJSON},
+		{`{"a": 1`, formatText},
+		{"<a><b>x</b></a>", formatXML},
+		{"<a><b>x</a>", formatText},
+		{`user=bob action="log in" ok=true`, formatKV},
+		{"user=bob", formatText},
+		{"user=bob and then some", formatText},
+		{"<34>1 2003-10-11T22:14:15.003Z host su - ID47 - 'su root' failed", formatSyslog},
+		{"<13>Oct 11 22:14:15 host sshd[42]: accepted", formatSyslog},
+	}
+	for _, tt := range tests {
+		if got := detectFormat([]byte(tt.data)); got != tt.want {
+			t.Errorf("detectFormat(%q) = %v, want %v", tt.data, got, tt.want)
+		}
+	}
+}
+
+func Test_kvPairs(t *testing.T) {
+	tests := []struct {
+		s    string
+		want [][2]string
+	}{
+		{"a=1 b=2", [][2]string{{"a", "1"}, {"b", "2"}}},
+		{`a="x y", b.c=, d=3;`, [][2]string{{"a", "x y"}, {"b.c", ""}, {"d", "3"}}},
+		{"a=1", nil},
+		{"a=1 stray b=2", nil},
+	}
+	for _, tt := range tests {
+		if got := kvPairs(tt.s); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("kvPairs(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func Test_syslogFields(t *testing.T) {
+	tests := []struct {
+		s    string
+		want [][2]string
+	}{
+		{"<13>Oct 11 22:14:15 host sshd[42]: accepted",
+			[][2]string{{"Facility", "user"}, {"Severity", "notice"}, {"Timestamp", "Oct 11 22:14:15"},
+				{"Hostname", "host"}, {"Tag", "sshd"}, {"PID", "42"}, {"Message", "accepted"}}},
+		{"<165>1 2003-10-11T22:14:15.003Z host app 1 ID47 [ex@1 a=\"b\"] msg",
+			[][2]string{{"Facility", "local4"}, {"Severity", "notice"}, {"Version", "1"},
+				{"Timestamp", "2003-10-11T22:14:15.003Z"}, {"Hostname", "host"}, {"App", "app"},
+				{"ProcID", "1"}, {"MsgID", "ID47"}, {"Structured Data", `[ex@1 a="b"]`},
+				{"Message", "msg"}}},
+		{"<13> not really syslog", nil},
+	}
+	for _, tt := range tests {
+		if got := syslogFields(tt.s); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("syslogFields(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func Test_parseJSONTree(t *testing.T) {
+	tree, err := parseJSONTree([]byte(`{"z": 1, "a": {"b": [1, 2]}, "c": "x"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := func(nodes []*jsonNode) (k []string) {
+		for _, n := range nodes {
+			k = append(k, n.key)
+		}
+		return k
+	}
+
+	// key order is preserved
+	if got, want := keys(tree.visible(nil)), []string{"", "z", "a", "b", "0", "1", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("visible nodes = %v, want %v", got, want)
+	}
+	tree.children[1].collapsed = true
+	if got, want := keys(tree.visible(nil)), []string{"", "z", "a", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("visible nodes with 'a' collapsed = %v, want %v", got, want)
+	}
+	tree.setCollapsedBelow(false)
+	if got := len(tree.visible(nil)); got != 7 {
+		t.Errorf("expanding all: got %v visible nodes, want 7", got)
+	}
+	tree.setCollapsedBelow(true)
+	if got := len(tree.visible(nil)); got != 4 {
+		t.Errorf("collapsing all: got %v visible nodes, want 4", got)
+	}
+}
@@ -6,18 +6,19 @@ package datascope
  */
 
 import (
-	"errors"
 	"fmt"
 	"gwcli/clilog"
 	"gwcli/connection"
 	"gwcli/stylesheet"
 	"gwcli/stylesheet/colorizer"
+	"gwcli/tree/query/transcode"
+	"gwcli/utilities/keymap"
 	"io"
 	"os"
-	"strconv"
+	"sort"
 	"strings"
-	"unicode"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -33,6 +34,12 @@ const (
 	dlfmtjson
 	dlfmtcsv
 	dlfmtraw
+	dlfmtndjson
+	dlfmttsv
+	dlfmtmd
+	dlfmthtml
+	dlfields
+	dlexclude
 	dlrecords
 	dlhighBound
 )
@@ -43,54 +50,51 @@ type downloadTab struct {
 	outfileTI textinput.Model // user input file to write to
 	append    bool            // append to the outfile instead of truncating
 	format    struct {
-		enabled bool
-		json    bool
-		csv     bool
-		raw     bool
+		enabled  bool
+		selected transcode.Format
 	}
 
+	fieldsTI  textinput.Model // fields to keep
+	excludeTI textinput.Model // fields to drop
+
 	recordsTI        textinput.Model // user input to select the pages to download
 	selected         uint
 	resultString     string // results of the previous download
 	inputErrorString string // issues with current user input
 }
 
+// formatCursors maps each format option's cursor position to the format it selects.
+var formatCursors = map[downloadCursor]transcode.Format{
+	dlfmtjson:   transcode.JSON,
+	dlfmtcsv:    transcode.CSV,
+	dlfmtraw:    transcode.Raw,
+	dlfmtndjson: transcode.NDJSON,
+	dlfmttsv:    transcode.TSV,
+	dlfmtmd:     transcode.Markdown,
+	dlfmthtml:   transcode.HTML,
+}
+
 // Initialize and return a DownloadTab struct suitable for representing the download option.
-//
-// ! JSON and CSV should not both be true. However, they can both be false.
-// Setting both to true is undefined behavior.
-func initDownloadTab(outfn string, append, json, csv bool) downloadTab {
+func initDownloadTab(outfn string, append bool, format transcode.Format,
+	project transcode.Projection) downloadTab {
 	d := downloadTab{
 		outfileTI: stylesheet.NewTI(outfn, false),
 		append:    append,
 		format: struct {
-			enabled bool
-			json    bool
-			csv     bool
-			raw     bool
-		}{enabled: true, json: json, csv: csv, raw: false},
+			enabled  bool
+			selected transcode.Format
+		}{enabled: true, selected: format},
+		fieldsTI:  stylesheet.NewTI(strings.Join(project.Include, ","), true),
+		excludeTI: stylesheet.NewTI(strings.Join(project.Exclude, ","), true),
 		recordsTI: stylesheet.NewTI("", true),
 		selected:  dloutfile,
 	}
 
-	// set raw if !(json or csv)
-	if !json && !csv {
-		d.format.raw = true
-	}
-
 	// focus outfileTI
 	d.outfileTI.Focus()
 
 	// add validator to recordsTI
-	d.recordsTI.Validate = func(s string) error {
-		for _, r := range s {
-			if r == ',' || unicode.IsNumber(r) {
-				continue
-			}
-			return errors.New("must be numeric")
-		}
-		return nil
-	}
+	d.recordsTI.Validate = validateRanges
 
 	return d
 }
@@ -100,6 +104,23 @@ func initDownloadTab(outfn string, append, json, csv bool) downloadTab {
 func updateDownload(s *DataScope, msg tea.Msg) tea.Cmd {
 	if msg, ok := msg.(tea.KeyMsg); ok {
 		s.download.inputErrorString = "" // clear input error on newest key message
+		if key.Matches(msg, keys.submit) {
+			// gather and validate selections
+			fn := strings.TrimSpace(s.download.outfileTI.Value())
+			if fn == "" {
+				str := "output file cannot be empty"
+				s.download.inputErrorString = str
+				return nil
+			}
+			res, success := s.dl(fn)
+			s.download.resultString = res
+			if !success {
+				clilog.Writer.Error(res)
+			} else {
+				clilog.Writer.Info(res)
+			}
+			return nil
+		}
 		switch msg.Type {
 		case tea.KeyUp:
 			cycleUp(&s.download)
@@ -108,53 +129,24 @@ func updateDownload(s *DataScope, msg tea.Msg) tea.Cmd {
 			cycleDown(&s.download)
 			return textinput.Blink
 		case tea.KeySpace, tea.KeyEnter:
-			if msg.Alt && msg.Type == tea.KeyEnter { // only accept alt+enter
-				// gather and validate selections
-				fn := strings.TrimSpace(s.download.outfileTI.Value())
-				if fn == "" {
-					str := "output file cannot be empty"
-					s.download.inputErrorString = str
-					return nil
-				}
-				res, success := s.dl(fn)
-				s.download.resultString = res
-				if !success {
-					clilog.Writer.Error(res)
-				} else {
-					clilog.Writer.Info(res)
-				}
-				return nil
-			}
 			// handle booleans
 			switch s.download.selected {
 			case dlappend:
 				s.download.append = !s.download.append
-			case dlfmtjson:
-				s.download.format.json = true
-				if s.download.format.json {
-					s.download.format.csv = false
-					s.download.format.raw = false
-				}
-			case dlfmtcsv:
-				s.download.format.csv = true
-				if s.download.format.csv {
-					s.download.format.json = false
-					s.download.format.raw = false
-				}
-			case dlfmtraw:
-				s.download.format.raw = true
-				if s.download.format.raw {
-					s.download.format.json = false
-					s.download.format.csv = false
+			default:
+				if f, ok := formatCursors[s.download.selected]; ok {
+					s.download.format.selected = f
 				}
 			}
 		}
 	}
 
 	// pass onto the TIs
-	var cmds []tea.Cmd = make([]tea.Cmd, 2)
+	var cmds []tea.Cmd = make([]tea.Cmd, 4)
 	s.download.outfileTI, cmds[0] = s.download.outfileTI.Update(msg)
-	s.download.recordsTI, cmds[1] = s.download.recordsTI.Update(msg)
+	s.download.fieldsTI, cmds[1] = s.download.fieldsTI.Update(msg)
+	s.download.excludeTI, cmds[2] = s.download.excludeTI.Update(msg)
+	s.download.recordsTI, cmds[3] = s.download.recordsTI.Update(msg)
 
 	// if recordsTI has input, disable format section
 	if strings.TrimSpace(s.download.recordsTI.Value()) != "" {
@@ -170,48 +162,66 @@ func updateDownload(s *DataScope, msg tea.Msg) tea.Cmd {
 // skipping the format section if it is disabled and looping to the last option if the user cycles
 // up while on the first.
 func cycleUp(dl *downloadTab) {
-	dl.outfileTI.Blur()
-	dl.recordsTI.Blur()
+	dl.blurTIs()
 	dl.selected -= 1
 	// if the format section is disabled, skip its elements
 	if !dl.format.enabled {
-		switch dl.selected {
-		case dlfmtjson, dlfmtcsv, dlfmtraw:
+		if _, ok := formatCursors[dl.selected]; ok {
 			dl.selected = dlappend
 		} // if no format elements are selection do nothing
 	}
 	if dl.selected <= dllowBound {
 		dl.selected = dlhighBound - 1
 	}
-	if dl.selected == dloutfile {
-		dl.outfileTI.Focus()
-	} else if dl.selected == dlrecords {
-		dl.recordsTI.Focus()
-	}
+	dl.focusSelected()
 }
 
 // See cycleUp()
 func cycleDown(dl *downloadTab) {
-	dl.outfileTI.Blur()
-	dl.recordsTI.Blur()
+	dl.blurTIs()
 	dl.selected += 1
 	// if the format section is disabled, skip its elements
 	if !dl.format.enabled {
-		switch dl.selected {
-		case dlfmtjson, dlfmtcsv, dlfmtraw:
-			dl.selected = dlrecords
+		if _, ok := formatCursors[dl.selected]; ok {
+			dl.selected = dlfields
 		} // if no format elements are selection do nothing
 	}
 	if dl.selected >= dlhighBound {
 		dl.selected = dllowBound + 1
 	}
-	if dl.selected == dloutfile {
+	dl.focusSelected()
+}
+
+// Blurs every TI in the download tab.
+func (dl *downloadTab) blurTIs() {
+	dl.outfileTI.Blur()
+	dl.fieldsTI.Blur()
+	dl.excludeTI.Blur()
+	dl.recordsTI.Blur()
+}
+
+// Focuses the TI under the cursor, if there is one.
+func (dl *downloadTab) focusSelected() {
+	switch dl.selected {
+	case dloutfile:
 		dl.outfileTI.Focus()
-	} else if dl.selected == dlrecords {
+	case dlfields:
+		dl.fieldsTI.Focus()
+	case dlexclude:
+		dl.excludeTI.Focus()
+	case dlrecords:
 		dl.recordsTI.Focus()
 	}
 }
 
+// Returns the projection described by the fields and exclude TIs.
+func (dl *downloadTab) projection() transcode.Projection {
+	return transcode.Projection{
+		Include: transcode.ParseFieldList(dl.fieldsTI.Value()),
+		Exclude: transcode.ParseFieldList(dl.excludeTI.Value()),
+	}
+}
+
 // The actual download function that consumes the user inputs and creates a file
 // based on the parameters.
 // fn must not be the empty string.
@@ -239,11 +249,19 @@ func (s *DataScope) dl(fn string) (result string, success bool) {
 
 	clilog.Writer.Debugf("Successfully opened file %v", f.Name())
 
+	// tables are downloaded as the user has arranged their columns
+	res, arranged := s.records, false
+	if s.tableMode && !s.table.layout.isDefault() {
+		res, arranged = res.Arrange(s.table.layout.visible()), true
+	}
+
 	// branch on records-only or full download
 	if strRecords := strings.TrimSpace(s.download.recordsTI.Value()); strRecords != "" {
 		// specific records
 		var data []string
-		if s.tableMode {
+		if project := s.download.projection(); !project.Empty() || arranged {
+			data = recordLines(res.Project(project))
+		} else if s.tableMode {
 			data = s.table.rows
 		} else {
 			data = s.results.data
@@ -258,9 +276,19 @@ func (s *DataScope) dl(fn string) (result string, success bool) {
 		if s.download.append {
 			word = "Appended"
 		}
-		return fmt.Sprintf("%v entries %v to %v", word, records, f.Name()), true
+		return fmt.Sprintf("%v entries %v to %v", word, formatRanges(records), f.Name()) +
+			unmatchedNote(res, s.download.projection()), true
 	}
 	// whole file
+	if why := s.localRenderReason(); why != "" {
+		// transcode the results we already have
+		fmtSel := s.download.format.selected
+		if err := transcode.Write(f, fmtSel, res.Project(s.download.projection())); err != nil {
+			return baseErrorResultString + err.Error(), false
+		}
+		return connection.DownloadQuerySuccessfulString(f.Name(), s.download.append, fmtSel.String()) +
+			" (rendered locally: " + why + ")" + unmatchedNote(res, s.download.projection()), true
+	}
 	var (
 		format string
 		rc     io.ReadCloser
@@ -268,8 +296,8 @@ func (s *DataScope) dl(fn string) (result string, success bool) {
 	if rc, format, err = connection.DownloadSearch(
 		s.search,
 		types.TimeRange{},
-		s.download.format.csv,
-		s.download.format.json,
+		s.download.format.selected == transcode.CSV,
+		s.download.format.selected == transcode.JSON,
 	); err != nil {
 		clilog.Writer.Errorf("DownloadSearch for ID '%v', format '%v' failed: %v",
 			s.search.ID, format, err) // log extra data
@@ -290,37 +318,61 @@ func (s *DataScope) dl(fn string) (result string, success bool) {
 	return connection.DownloadQuerySuccessfulString(f.Name(), s.download.append, format), true
 }
 
-// helper record for dl.
-// Writes just the records specified in the comma-seperated list strRecords to the file f.
-// Returns the list of record numbers whose values were written or an error
-func dlrecordsOnly(f *os.File, strRecordsTI string, data []string) ([]uint32, error) {
-	exploded := strings.Split(strRecordsTI, ",")
-	var writtenRecords []uint32
-	for _, strRec := range exploded {
-		// sanity check record
-		if strings.TrimSpace(strRec) == "" {
-			continue
-		}
-		var rec uint32
-		if n, err := strconv.ParseUint(strRec, 10, 32); err != nil {
-			return nil, fmt.Errorf("failed to parse record '%v':\n%v", strRec, err)
-		} else {
-			rec = (uint32(n))
-		}
+// helper subroutine for dl and viewDownload.
+// Returns why a whole-file download must be rendered locally from the results DataScope fetched,
+// rather than downloaded from the server, or the empty string if it can be downloaded.
+func (s *DataScope) localRenderReason() string {
+	var why []string
+	if f := s.download.format.selected; f.Local() {
+		why = append(why, f.String()+" is a gwcli format")
+	}
+	if !s.download.projection().Empty() {
+		why = append(why, "fields are selected")
+	}
+	if s.tableMode && !s.table.layout.isDefault() {
+		why = append(why, "columns are arranged")
+	}
+	return strings.Join(why, "; ")
+}
 
-		totalRecords := uint32(len(data))
-		if rec > totalRecords || rec == 0 {
-			return nil, fmt.Errorf(
-				"record %v is outside the set of available records [1-%v]",
-				rec, totalRecords)
-		}
+// Returns a note naming the selected fields that match nothing in res; empty if all match.
+func unmatchedNote(res transcode.Results, p transcode.Projection) string {
+	if unmatched := res.Unmatched(p); len(unmatched) > 0 {
+		return " (no column or key matches " + strings.Join(unmatched, ", ") + ")"
+	}
+	return ""
+}
 
-		// requested number is in good condition; write is data to the file
-		f.WriteString(data[rec-1] + "\n") // user sees indices from 1, not 0
-		writtenRecords = append(writtenRecords, rec)
+// helper record for dl.
+// Writes just the records specified by the range list strRecords (see parseRanges) to the file f,
+// in the order given.
+// Returns the (0-indexed, ascending) records whose values were written or an error
+func dlrecordsOnly(f *os.File, strRecordsTI string, data []string) ([]int, error) {
+	records, err := parseRanges(strRecordsTI, len(data))
+	if err != nil {
+		return nil, err
 	}
+	for _, rec := range records {
+		f.WriteString(data[rec] + "\n")
+	}
+	sort.Ints(records)
+	return records, nil
+}
 
-	return writtenRecords, nil
+// helper subroutine for dl.
+// Returns each record in the same form the results and table tabs hold them.
+func recordLines(res transcode.Results) []string {
+	lines := make([]string, res.Len())
+	if res.Table() {
+		for i, r := range res.Rows {
+			lines[i] = strings.Join(r.Row, sep)
+		}
+		return lines
+	}
+	for i, e := range res.Entries {
+		lines[i] = string(e.Data)
+	}
+	return lines
 }
 
 func viewDownload(s *DataScope) string {
@@ -335,21 +387,34 @@ func viewDownload(s *DataScope) string {
 	)
 
 	tabDesc := tabDescStyle(s.usableWidth()).Render("Download all data in your preferred format or" +
-		" cherry-pick specific records by their index.")
+		" cherry-pick specific records by their index." +
+		" Tables are saved with their columns as arranged in the table tab.")
 
 	prime := outputFormatSegment(titleSty, subtitleSty, lcolAligner, rcolAligner, sel, &s.download)
 
 	recs := recordSegment(titleSty, lcolAligner, rcolAligner, sel, &s.download)
 
+	fields := fieldsSegment(titleSty, subtitleSty, lcolAligner, rcolAligner, sel, &s.download)
+
+	// warn that the download will not come from the server
+	var local string
+	if why := s.localRenderReason(); why != "" && strings.TrimSpace(s.download.recordsTI.Value()) == "" {
+		local = stylesheet.GreyedOutStyle.Width(s.usableWidth()).AlignHorizontal(lipgloss.Center).
+			Render("Will be rendered locally from the fetched results (" + why + ")")
+	}
+
 	return lipgloss.Place(s.usableWidth(), s.usableHeight(),
 		lipgloss.Center, verticalPlace,
 		lipgloss.JoinVertical(lipgloss.Center,
 			tabDesc,
 			prime,
 			"",
+			fields,
+			"",
 			recs,
 			"",
-			colorizer.SubmitString("alt+enter", s.download.inputErrorString, s.download.resultString, s.usableWidth()),
+			colorizer.SubmitString(keymap.Keys(keys.submit), s.download.inputErrorString, s.download.resultString, s.usableWidth()),
+			local,
 		),
 	)
 }
@@ -390,31 +455,18 @@ func outputFormatSegment(titleSty, subtitleSty, lcolAligner, rcolAligner lipglos
 	}
 
 	// generate format segment
-	var ( // format segment left column elements
-		jsonStr = fmt.Sprintf("%s%s",
-			colorizer.Pip(selected, dlfmtjson), subtitleSty.Render("JSON"))
-		csvStr = fmt.Sprintf("%s%s",
-			colorizer.Pip(selected, dlfmtcsv), subtitleSty.Render("CSV"))
-		rawStr = fmt.Sprintf("%s%s",
-			colorizer.Pip(selected, dlfmtraw), subtitleSty.Render("raw"))
-	)
-
-	var ( // format segment right column elements
-		jsonBox = colorizer.Radiobox(dl.format.json)
-		csvBox  = colorizer.Radiobox(dl.format.csv)
-		rawBox  = colorizer.Radiobox(dl.format.raw)
-	)
+	var lcol, rcol []string
+	for c := dlfmtjson; c <= dlfmthtml; c++ {
+		f := formatCursors[c]
+		lcol = append(lcol, lcolAligner.Render(fmt.Sprintf("%s%s",
+			colorizer.Pip(selected, c), subtitleSty.Render(f.String()))))
+		rcol = append(rcol, rcolAligner.Render(colorizer.Radiobox(dl.format.selected == f)))
+	}
 
 	// conjoin format pieces
 	formatSeg := lipgloss.JoinHorizontal(lipgloss.Center,
-		lipgloss.JoinVertical(lipgloss.Right,
-			lcolAligner.Render(jsonStr),
-			lcolAligner.Render(csvStr),
-			lcolAligner.Render(rawStr)),
-		lipgloss.JoinVertical(lipgloss.Left,
-			rcolAligner.Render(jsonBox),
-			rcolAligner.Render(csvBox),
-			rcolAligner.Render(rawBox)),
+		lipgloss.JoinVertical(lipgloss.Right, lcol...),
+		lipgloss.JoinVertical(lipgloss.Left, rcol...),
 	)
 
 	return lipgloss.JoinVertical(lipgloss.Center,
@@ -423,6 +475,27 @@ func outputFormatSegment(titleSty, subtitleSty, lcolAligner, rcolAligner lipglos
 		formatSeg)
 }
 
+// helper subroutine for viewDownload.
+// Generates the field projection inputs.
+func fieldsSegment(titleSty, subtitleSty, lcolAligner, rcolAligner lipgloss.Style,
+	selected downloadCursor, dl *downloadTab) string {
+	l := lipgloss.JoinVertical(lipgloss.Right,
+		lcolAligner.Render(fmt.Sprintf("%s%s",
+			colorizer.Pip(selected, dlfields), subtitleSty.Render("Only:"))),
+		lcolAligner.Render(fmt.Sprintf("%s%s",
+			colorizer.Pip(selected, dlexclude), subtitleSty.Render("Exclude:"))),
+	)
+	r := lipgloss.JoinVertical(lipgloss.Left,
+		rcolAligner.Render(dl.fieldsTI.View()),
+		rcolAligner.Render(dl.excludeTI.View()),
+	)
+
+	return lipgloss.JoinVertical(lipgloss.Center,
+		titleSty.Render("Fields"),
+		lipgloss.JoinHorizontal(lipgloss.Center, l, r),
+		stylesheet.GreyedOutStyle.Render("comma-separated table columns or JSON keys"))
+}
+
 // helper subroutine for viewDownload.
 // Generates the visual pieces associated to individual record selection.
 func recordSegment(titleSty, lcolAligner, rcolAligner lipgloss.Style,
@@ -441,7 +514,7 @@ func recordSegment(titleSty, lcolAligner, rcolAligner lipgloss.Style,
 	)
 
 	recordsDesc := lipgloss.NewStyle().
-		Render("Enter a comma-seperated list of records to download just those records,") + "\n"
+		Render("Enter records or ranges (ex: 1-20,45,60-) to download just those records,") + "\n"
 	recordsDesc += lipgloss.NewStyle().Bold(true).Render("instead of the whole file.")
 	recordsDescFormatted := tooltipSty.
 		Width(lipgloss.Width(recs)).
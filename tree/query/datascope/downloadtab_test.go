@@ -0,0 +1,35 @@
+package datascope
+
+import (
+	"gwcli/tree/query/transcode"
+	"testing"
+)
+
+func Test_localRenderReason(t *testing.T) {
+	tests := []struct {
+		name      string
+		format    transcode.Format
+		project   transcode.Projection
+		tableMode bool
+		layout    columnLayout
+		want      string
+	}{
+		{"server format", transcode.CSV, transcode.Projection{}, true, defaultLayout(2), ""},
+		{"local format", transcode.NDJSON, transcode.Projection{}, false, columnLayout{}, "NDJSON is a gwcli format"},
+		{"projected", transcode.JSON, transcode.Projection{Include: []string{"a"}}, false, columnLayout{},
+			"fields are selected"},
+		{"arranged", transcode.CSV, transcode.Projection{}, true, columnLayout{Order: []int{1, 0}},
+			"columns are arranged"},
+		{"arranged outside of table mode", transcode.CSV, transcode.Projection{}, false,
+			columnLayout{Order: []int{1, 0}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := DataScope{download: initDownloadTab("", false, tt.format, tt.project), tableMode: tt.tableMode}
+			s.table.layout = tt.layout
+			if got := s.localRenderReason(); got != tt.want {
+				t.Errorf("localRenderReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,350 @@
+package datascope
+
+/**
+ * The fields tab profiles each column of table results: how many distinct values it holds, its
+ * most frequent values, how many cells are empty, and, for numeric columns, the distribution of
+ * its values.
+ *
+ * The value under the cursor can be used to narrow the table to just the rows holding it
+ * (alongside any filter).
+ */
+
+import (
+	"fmt"
+	"gwcli/stylesheet"
+	"gwcli/tree/query/transcode"
+	"gwcli/utilities/killer"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	defaultTopValues = 5  // number of most frequent values displayed per column
+	maxTopValues     = 50 // the number of values displayed cannot be raised beyond this
+	frequencyBarSize = 20 // width of the frequency bars
+)
+
+// valueCount is a distinct value within a column and the number of rows holding it.
+type valueCount struct {
+	value string
+	count int
+}
+
+type columnProfile struct {
+	name   string
+	values []valueCount // every distinct, non-empty value; most frequent first
+	empty  int          // cells that are blank or null
+
+	numeric        bool // all non-empty cells are numbers
+	min, max, mean float64
+	p50, p90, p99  float64
+}
+
+// Returns whether the given cell should be considered empty.
+func emptyCell(c string) bool {
+	c = strings.TrimSpace(c)
+	return c == "" || strings.EqualFold(c, "null")
+}
+
+// Profiles the given cells of a column.
+func profileColumn(name string, cells []string) columnProfile {
+	p := columnProfile{name: name}
+	var (
+		counts = make(map[string]int)
+		nums   []float64
+	)
+	p.numeric = true
+	for _, c := range cells {
+		if emptyCell(c) {
+			p.empty++
+			continue
+		}
+		counts[c]++
+		if p.numeric {
+			if f, err := strconv.ParseFloat(strings.TrimSpace(c), 64); err != nil {
+				p.numeric = false
+			} else {
+				nums = append(nums, f)
+			}
+		}
+	}
+
+	p.values = make([]valueCount, 0, len(counts))
+	for v, c := range counts {
+		p.values = append(p.values, valueCount{v, c})
+	}
+	slices.SortFunc(p.values, func(a, b valueCount) int {
+		if a.count != b.count {
+			return cmpInt(b.count, a.count)
+		}
+		return compareCells(a.value, b.value)
+	})
+
+	if p.numeric = p.numeric && len(nums) > 0; p.numeric {
+		slices.Sort(nums)
+		var sum float64
+		for _, f := range nums {
+			sum += f
+		}
+		p.min, p.max, p.mean = nums[0], nums[len(nums)-1], sum/float64(len(nums))
+		p.p50, p.p90, p.p99 = percentile(nums, 50), percentile(nums, 90), percentile(nums, 99)
+	}
+	return p
+}
+
+// Returns the nearest-rank percentile of the given, sorted values.
+func percentile(sorted []float64, pct float64) float64 {
+	rank := int(math.Ceil(pct / 100 * float64(len(sorted))))
+	return sorted[min(max(rank-1, 0), len(sorted)-1)]
+}
+
+// Returns f without trailing zeros, rounded to two decimal places.
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+}
+
+// fieldValue identifies a value displayed by the fields tab.
+type fieldValue struct {
+	col   int // index of the column in the results
+	value string
+}
+
+type fieldsTab struct {
+	vp       viewport.Model
+	rows     int // number of rows profiled
+	profiles []columnProfile
+	topN     int          // number of values displayed per column
+	items    []fieldValue // displayed values, in display order
+	lines    []int        // line each item is drawn on
+	cursor   int          // index in items of the value under the cursor
+	ready    bool
+}
+
+// Initializes the fields tab, profiling every column of the given results.
+// Text results are not profiled.
+func initFieldsTab(res transcode.Results) fieldsTab {
+	f := fieldsTab{vp: NewViewport(), topN: defaultTopValues}
+	if !res.Table() {
+		return f
+	}
+	f.rows = len(res.Rows)
+	cells := make([]string, len(res.Rows))
+	for col, name := range res.Columns {
+		for i, r := range res.Rows {
+			cells[i] = ""
+			if col < len(r.Row) {
+				cells[i] = r.Row[col]
+			}
+		}
+		f.profiles = append(f.profiles, profileColumn(name, cells))
+	}
+	return f
+}
+
+func updateFields(s *DataScope, msg tea.Msg) tea.Cmd {
+	f := &s.fields
+	keyMsg, ok := msg.(tea.KeyMsg)
+	// every cell may be empty, leaving nothing to place the cursor on
+	if !ok || len(f.profiles) == 0 || len(f.items) == 0 {
+		return nil
+	}
+	switch {
+	case key.Matches(keyMsg, f.vp.KeyMap.Up):
+		f.cursor = max(f.cursor-1, 0)
+	case key.Matches(keyMsg, f.vp.KeyMap.Down):
+		f.cursor = min(f.cursor+1, len(f.items)-1)
+	case key.Matches(keyMsg, f.vp.KeyMap.PageUp):
+		f.cursor = f.itemAtLine(f.lines[f.cursor] - f.vp.Height)
+	case key.Matches(keyMsg, f.vp.KeyMap.PageDown):
+		f.cursor = f.itemAtLine(f.lines[f.cursor] + f.vp.Height)
+	case keyMsg.Type == tea.KeyHome:
+		f.cursor = 0
+	case keyMsg.Type == tea.KeyEnd:
+		f.cursor = len(f.items) - 1
+	case key.Matches(keyMsg, keys.pageSizeUp):
+		f.setTopN(f.topN + 1)
+	case key.Matches(keyMsg, keys.pageSizeDown):
+		f.setTopN(f.topN - 1)
+	case key.Matches(keyMsg, keys.pageSizeAuto):
+		f.setTopN(defaultTopValues)
+	case keyMsg.Type == tea.KeyEnter && f.cursor < len(f.items):
+		v := f.items[f.cursor]
+		s.setCellFilter(&v)
+		s.activeTab = results
+	case key.Matches(keyMsg, keys.filter) && f.cursor < len(f.items):
+		v := f.items[f.cursor]
+		if s.find.cell != nil && *s.find.cell == v {
+			s.setCellFilter(nil)
+		} else {
+			s.setCellFilter(&v)
+		}
+	}
+	f.refresh(s.find.cell)
+	return nil
+}
+
+// Returns the last item drawn at or before the given line (or the first item, if none are).
+func (f *fieldsTab) itemAtLine(line int) int {
+	i, _ := slices.BinarySearch(f.lines, line+1)
+	return max(i-1, 0)
+}
+
+// Sets the number of values displayed per column, keeping the cursor on the same value if it
+// remains displayed.
+func (f *fieldsTab) setTopN(n int) {
+	n = min(max(n, 1), maxTopValues)
+	if n == f.topN {
+		return
+	}
+	var prior fieldValue
+	if f.cursor < len(f.items) {
+		prior = f.items[f.cursor]
+	}
+	f.topN = n
+	f.refresh(nil)
+	f.cursor = 0
+	for i, v := range f.items {
+		if v.col > prior.col {
+			break
+		}
+		f.cursor = i
+		if v == prior {
+			break
+		}
+	}
+}
+
+// Redraws the profiles, marking the value the table is narrowed to (if any) and keeping the cursor
+// in view.
+func (f *fieldsTab) refresh(narrowed *fieldValue) {
+	if len(f.profiles) == 0 {
+		f.vp.SetContent("Field statistics are only available for table results.")
+		return
+	}
+	var (
+		lines      []string
+		valueWidth = max(f.vp.Width/3, 8)
+		countWidth = len(strconv.Itoa(f.rows))
+		greyed     = stylesheet.GreyedOutStyle
+	)
+	f.items, f.lines = f.items[:0], f.lines[:0]
+	for col, p := range f.profiles {
+		desc := fmt.Sprintf(" %d distinct • %d empty", len(p.values), p.empty)
+		if p.numeric {
+			desc += " • numeric"
+		}
+		lines = append(lines, stylesheet.Header1Style.Render(p.name)+greyed.Render(desc))
+
+		for _, vc := range p.values[:min(f.topN, len(p.values))] {
+			item := fieldValue{col, vc.value}
+			prefix, value := "  ", padRight(lipgloss.NewStyle().Inline(true).MaxWidth(valueWidth).
+				Render(strings.ReplaceAll(vc.value, "\n", "⏎")), valueWidth)
+			if len(f.items) == f.cursor {
+				prefix = lipgloss.NewStyle().Foreground(stylesheet.FocusedColor).
+					Render(string(stylesheet.SelectionPrefix)) + " "
+				value = cursorStyle.Render(value)
+			}
+			line := fmt.Sprintf("  %s%s %s %*d %5.1f%%", prefix, value,
+				barStyle().Render(bar(vc.count, f.rows, frequencyBarSize)), countWidth, vc.count,
+				float64(vc.count)/float64(f.rows)*100)
+			if narrowed != nil && *narrowed == item {
+				line += noticeStyle().Render(" (narrowed)")
+			}
+			f.items, f.lines = append(f.items, item), append(f.lines, len(lines))
+			lines = append(lines, line)
+		}
+		if more := len(p.values) - f.topN; more > 0 {
+			lines = append(lines, greyed.Render(fmt.Sprintf("      … %d more", more)))
+		}
+		if p.numeric {
+			lines = append(lines, "    "+greyed.Render(fmt.Sprintf(
+				"min %v • max %v • mean %v • p50 %v • p90 %v • p99 %v",
+				formatNumber(p.min), formatNumber(p.max), formatNumber(p.mean),
+				formatNumber(p.p50), formatNumber(p.p90), formatNumber(p.p99))))
+		}
+		lines = append(lines, "")
+	}
+	f.vp.SetContent(strings.Join(lines, "\n"))
+
+	if len(f.items) == 0 {
+		return
+	}
+	f.cursor = min(f.cursor, len(f.items)-1)
+	if line := f.lines[f.cursor]; line-1 < f.vp.YOffset { // keep the column's name in view
+		f.vp.SetYOffset(max(line-1, 0))
+	} else if line >= f.vp.YOffset+f.vp.Height {
+		f.vp.SetYOffset(line - f.vp.Height + 1)
+	}
+}
+
+// Pads s with spaces to the given width.
+func padRight(s string, width int) string {
+	return s + strings.Repeat(" ", max(width-lipgloss.Width(s), 0))
+}
+
+func viewFields(s *DataScope) string {
+	f := &s.fields
+	if !f.ready {
+		return "\nInitializing..."
+	}
+	return f.renderHeader() + "\n" + f.vp.View() + "\n" + f.renderFooter()
+}
+
+func (f *fieldsTab) renderHeader() string {
+	if len(f.profiles) == 0 {
+		return ""
+	}
+	return lipgloss.NewStyle().Width(f.vp.Width).AlignHorizontal(lipgloss.Center).
+		Render(stylesheet.Header1Style.Render(
+			fmt.Sprintf("%d columns across %d rows", len(f.profiles), f.rows)))
+}
+
+func fieldsShortHelp() string {
+	return stylesheet.GreyedOutStyle.Render(
+		fmt.Sprintf("%v select value • %v: more/fewer/default values per column\n"+
+			"enter: filter table to value • %v: narrow rows/clear • %v: cycle • %v: quit",
+			hint(viewportKeys.Up, viewportKeys.Down),
+			hint(keys.pageSizeUp, keys.pageSizeDown, keys.pageSizeAuto), hint(keys.filter),
+			hint(keys.cycleTabs), hint(killer.ChildKill)),
+	)
+}
+
+func (f *fieldsTab) renderFooter() string {
+	return lipgloss.JoinVertical(lipgloss.Center,
+		scrollPercentLine(f.vp.Width, f.vp.ScrollPercent()),
+		lipgloss.NewStyle().Width(f.vp.Width).AlignHorizontal(lipgloss.Center).Render(fieldsShortHelp()),
+	)
+}
+
+// The clipped height is the height available to the fields tab (height - tabs height).
+func (f *fieldsTab) recalculateSize(rawWidth, clippedHeight int, narrowed *fieldValue) {
+	f.vp.Width = rawWidth
+	f.vp.Height = max(1, clippedHeight-lipgloss.Height(f.renderHeader())-
+		lipgloss.Height(f.renderFooter())-1) // header is separated by a newline
+	f.refresh(narrowed)
+	f.ready = true
+}
+
+//#region DS integration
+
+// Returns whether the given row holds the value.
+func (s *DataScope) cellMatches(v *fieldValue, idx int) bool {
+	row := s.records.Rows[idx].Row
+	return v.col < len(row) && row[v.col] == v.value
+}
+
+// Narrows the displayed rows to those holding the given value (or, if nil, stops narrowing).
+func (s *DataScope) setCellFilter(v *fieldValue) {
+	s.find.cell = v
+	s.applyFilter()
+	s.recalculateWindowMargins(s.rawWidth, s.rawHeight) // the finder's bar may have (dis)appeared
+}
+
+//#endregion
@@ -0,0 +1,99 @@
+package datascope
+
+import (
+	"gwcli/clilog"
+	"gwcli/tree/query/transcode"
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gravwell/gravwell/v3/client/types"
+)
+
+func Test_profileColumn(t *testing.T) {
+	tests := []struct {
+		name  string
+		cells []string
+		want  columnProfile
+	}{
+		{"text", []string{"b", "a", "", "b", "NULL", "c"}, columnProfile{name: "text",
+			values: []valueCount{{"b", 2}, {"a", 1}, {"c", 1}}, empty: 2}},
+		{"numeric", []string{"10", "2", " 3", "2", "", "1"}, columnProfile{name: "numeric",
+			values: []valueCount{{"2", 2}, {"1", 1}, {" 3", 1}, {"10", 1}}, empty: 1,
+			numeric: true, min: 1, max: 10, mean: 3.6, p50: 2, p90: 10, p99: 10}},
+		{"mixed", []string{"1", "x"}, columnProfile{name: "mixed",
+			values: []valueCount{{"1", 1}, {"x", 1}}}},
+		{"all empty", []string{"", " "}, columnProfile{name: "all empty", values: []valueCount{},
+			empty: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := profileColumn(tt.name, tt.cells); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("profileColumn() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDataScope_cellFilter(t *testing.T) {
+	if err := clilog.Init(t.TempDir()+"/log.txt", "DEBUG"); err != nil {
+		t.Fatal(err)
+	}
+	layoutsPath = t.TempDir() + "/layouts.json"
+	res := transcode.Results{Columns: []string{"host", "status"}, Rows: []types.TableRow{
+		{Row: []string{"web1", "200"}},
+		{Row: []string{"web2", "500"}},
+		{Row: []string{"web1", "500"}},
+	}}
+	s := DataScope{records: res, tableMode: true, table: initTableTab(res.Columns, res.Rows),
+		fields: initFieldsTab(res), find: initFinder()}
+	s.recalculateWindowMargins(80, 30)
+
+	// the cursor starts on the most frequent host
+	if got, want := s.fields.items[0], (fieldValue{0, "web1"}); got != want {
+		t.Fatalf("first value = %v, want %v", got, want)
+	}
+	s.activeTab = fieldStats
+	updateFields(&s, tea.KeyMsg{Type: tea.KeyEnter})
+	if got, want := s.shownRecords(), []int{0, 2}; !reflect.DeepEqual(got, want) ||
+		s.activeTab != results {
+		t.Errorf("filtering to web1 displays rows %v on tab %v, want %v", got, s.activeTab, want)
+	}
+
+	// narrow by status as well as a regex filter
+	if err := s.setFilter("500"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.shownRecords(), []int{2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("narrowed rows = %v, want %v", got, want)
+	}
+	s.setCellFilter(nil)
+	if got, want := s.shownRecords(), []int{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("clearing the cell filter displays rows %v, want %v", got, want)
+	}
+}
+
+func Test_updateFields_allEmpty(t *testing.T) {
+	if err := clilog.Init(t.TempDir()+"/log.txt", "DEBUG"); err != nil {
+		t.Fatal(err)
+	}
+	layoutsPath = t.TempDir() + "/layouts.json"
+	res := transcode.Results{Columns: []string{"missing"}, Rows: []types.TableRow{
+		{Row: []string{""}},
+		{Row: []string{""}},
+	}}
+	s := DataScope{records: res, tableMode: true, table: initTableTab(res.Columns, res.Rows),
+		fields: initFieldsTab(res), find: initFinder()}
+	s.recalculateWindowMargins(80, 30)
+	s.activeTab = fieldStats
+
+	// none of these may panic when there are profiles but no values to place the cursor on
+	for _, k := range []tea.KeyMsg{{Type: tea.KeyPgDown}, {Type: tea.KeyPgUp}, {Type: tea.KeyDown},
+		{Type: tea.KeyEnter}, {Type: tea.KeyEnd}, {Type: tea.KeyUp}} {
+		updateFields(&s, k)
+	}
+	if s.fields.cursor != 0 || s.activeTab != fieldStats || s.find.cell != nil {
+		t.Errorf("keys altered an empty fields tab: cursor %d, tab %v, cell filter %v",
+			s.fields.cursor, s.activeTab, s.find.cell)
+	}
+}
@@ -0,0 +1,380 @@
+package datascope
+
+/**
+ * The histogram tab buckets records by timestamp across the search's time range and draws the
+ * distribution as a horizontal bar chart, one bucket per line.
+ * The bucket size is selected automatically from the time range, but can be made coarser or finer.
+ *
+ * The bucket under the cursor can be jumped to in the results/table tab or used to narrow the
+ * displayed records to just those within it (alongside any filter).
+ */
+
+import (
+	"fmt"
+	"gwcli/stylesheet"
+	"gwcli/utilities/killer"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	targetBuckets = 40    // approximate number of buckets automatic sizing aims for
+	maxBuckets    = 10000 // buckets cannot be made any finer than this many
+)
+
+// bucket sizes to select from, ascending
+var bucketSizes = []time.Duration{
+	time.Second, 2 * time.Second, 5 * time.Second, 10 * time.Second, 15 * time.Second,
+	30 * time.Second,
+	time.Minute, 2 * time.Minute, 5 * time.Minute, 10 * time.Minute, 15 * time.Minute,
+	30 * time.Minute,
+	time.Hour, 2 * time.Hour, 3 * time.Hour, 6 * time.Hour, 12 * time.Hour,
+	24 * time.Hour, 2 * 24 * time.Hour, 7 * 24 * time.Hour, 14 * 24 * time.Hour, 30 * 24 * time.Hour,
+}
+
+// partial blocks, in eighths, for drawing the fractional end of a bar
+var barEighths = []string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+
+var barStyle = func() lipgloss.Style { return lipgloss.NewStyle().Foreground(stylesheet.AccentColor2) }
+
+type bucket struct {
+	start time.Time
+	count int
+}
+
+// timeWindow is a half-open range of time, [start, end).
+type timeWindow struct {
+	start, end time.Time
+}
+
+func (w timeWindow) contains(t time.Time) bool {
+	return !t.Before(w.start) && t.Before(w.end)
+}
+
+func (w timeWindow) String() string {
+	layout := "2006-01-02 15:04:05"
+	if w.start.Format(time.DateOnly) == w.end.Add(-time.Nanosecond).Format(time.DateOnly) {
+		return w.start.Format(layout) + "–" + w.end.Format(time.TimeOnly)
+	}
+	return w.start.Format(layout) + "–" + w.end.Format(layout)
+}
+
+type histogramTab struct {
+	vp      viewport.Model
+	times   []time.Time // timestamp of each record; zero if the record has none
+	start   time.Time   // beginning of the time range
+	end     time.Time   // end of the time range
+	sizeIdx int         // index of the current bucket size in bucketSizes
+	buckets []bucket
+	cursor  int    // index of the bucket under the cursor
+	err     string // issue with the most recent action
+	ready   bool
+}
+
+// Initializes the histogram tab over the given record timestamps.
+// start and end are the search's time range, if known; the range is extended to cover all
+// timestamps.
+func initHistogramTab(times []time.Time, start, end time.Time) histogramTab {
+	h := histogramTab{vp: NewViewport(), times: times}
+	if slices.ContainsFunc(times, func(t time.Time) bool { return !t.IsZero() }) {
+		h.start, h.end = start, end
+	}
+	for _, t := range times {
+		if t.IsZero() {
+			continue
+		}
+		if h.start.IsZero() || t.Before(h.start) {
+			h.start = t
+		}
+		if h.end.IsZero() || !t.Before(h.end) {
+			h.end = t.Add(time.Nanosecond) // the range is half-open
+		}
+	}
+	h.sizeIdx = autoBucketSize(h.end.Sub(h.start))
+	h.rebucket()
+	return h
+}
+
+// Widens the time range to cover the given search range (if known), re-selecting the bucket size.
+// Records without timestamps are still not charted.
+func (h *histogramTab) widen(start, end time.Time) {
+	if h.start.IsZero() {
+		return
+	}
+	if !start.IsZero() && start.Before(h.start) {
+		h.start = start
+	}
+	if end.After(h.end) {
+		h.end = end
+	}
+	h.sizeIdx = autoBucketSize(h.end.Sub(h.start))
+	h.rebucket()
+	if h.ready {
+		h.refresh()
+	}
+}
+
+// Returns the index of the smallest bucket size that divides the span into (roughly) no more than
+// targetBuckets buckets.
+func autoBucketSize(span time.Duration) int {
+	for i, size := range bucketSizes {
+		if span/size < targetBuckets {
+			return i
+		}
+	}
+	return len(bucketSizes) - 1
+}
+
+// Returns the size of the current buckets.
+func (h *histogramTab) size() time.Duration {
+	return bucketSizes[h.sizeIdx]
+}
+
+// Recounts the records into buckets of the current size, keeping the cursor on the bucket
+// containing the start of its prior bucket.
+func (h *histogramTab) rebucket() {
+	var prior time.Time
+	if h.cursor < len(h.buckets) {
+		prior = h.buckets[h.cursor].start
+	}
+	h.buckets = nil
+	if h.start.IsZero() { // no timestamps
+		return
+	}
+	size := h.size()
+	first := h.start.Truncate(size)
+	h.buckets = make([]bucket, int(h.end.Sub(first)/size)+1)
+	for i := range h.buckets {
+		h.buckets[i].start = first.Add(time.Duration(i) * size)
+	}
+	for _, t := range h.times {
+		if !t.IsZero() {
+			if i := int(t.Sub(first) / size); i >= 0 && i < len(h.buckets) {
+				h.buckets[i].count++
+			}
+		}
+	}
+	h.cursor = 0
+	if !prior.IsZero() {
+		h.cursor = min(max(int(prior.Sub(first)/size), 0), len(h.buckets)-1)
+	}
+}
+
+// Returns the time range covered by the given bucket.
+func (h *histogramTab) window(i int) timeWindow {
+	return timeWindow{h.buckets[i].start, h.buckets[i].start.Add(h.size())}
+}
+
+// Returns the layout bucket labels are formatted with, given the current bucket size.
+func (h *histogramTab) labelLayout() string {
+	switch size := h.size(); {
+	case size >= 24*time.Hour:
+		return time.DateOnly
+	case size >= time.Minute:
+		return "2006-01-02 15:04"
+	}
+	return time.DateTime
+}
+
+func updateHistogram(s *DataScope, msg tea.Msg) tea.Cmd {
+	h := &s.histogram
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || len(h.buckets) == 0 {
+		return nil
+	}
+	h.err = ""
+	switch {
+	case key.Matches(keyMsg, h.vp.KeyMap.Up):
+		h.cursor = max(h.cursor-1, 0)
+	case key.Matches(keyMsg, h.vp.KeyMap.Down):
+		h.cursor = min(h.cursor+1, len(h.buckets)-1)
+	case key.Matches(keyMsg, h.vp.KeyMap.PageUp):
+		h.cursor = max(h.cursor-h.vp.Height, 0)
+	case key.Matches(keyMsg, h.vp.KeyMap.PageDown):
+		h.cursor = min(h.cursor+h.vp.Height, len(h.buckets)-1)
+	case keyMsg.Type == tea.KeyHome:
+		h.cursor = 0
+	case keyMsg.Type == tea.KeyEnd:
+		h.cursor = len(h.buckets) - 1
+	case key.Matches(keyMsg, keys.pageSizeUp) && h.sizeIdx < len(bucketSizes)-1: // coarser
+		h.sizeIdx++
+		h.rebucket()
+	case key.Matches(keyMsg, keys.pageSizeDown) && h.sizeIdx > 0 &&
+		h.end.Sub(h.start)/bucketSizes[h.sizeIdx-1] < maxBuckets: // finer
+		h.sizeIdx--
+		h.rebucket()
+	case key.Matches(keyMsg, keys.pageSizeAuto):
+		h.sizeIdx = autoBucketSize(h.end.Sub(h.start))
+		h.rebucket()
+	case keyMsg.Type == tea.KeyEnter:
+		if !s.jumpToWindow(h.window(h.cursor)) {
+			h.err = "no displayed records within this bucket"
+		}
+	case key.Matches(keyMsg, keys.filter):
+		w := h.window(h.cursor)
+		if s.find.window != nil && *s.find.window == w {
+			s.setWindow(nil)
+		} else {
+			s.setWindow(&w)
+		}
+	}
+	h.refresh()
+	return nil
+}
+
+// Redraws the histogram, keeping the cursor in view.
+func (h *histogramTab) refresh() {
+	if len(h.buckets) == 0 {
+		h.vp.SetContent("No timestamped records to chart.")
+		return
+	}
+	var (
+		layout   = h.labelLayout()
+		maxCount int
+	)
+	for _, b := range h.buckets {
+		maxCount = max(maxCount, b.count)
+	}
+	countWidth := len(strconv.Itoa(maxCount))
+	// prefix + label + " │" + bar + " " + count
+	barWidth := max(h.vp.Width-2-len(layout)-2-1-countWidth, 1)
+
+	lines := make([]string, len(h.buckets))
+	for i, b := range h.buckets {
+		prefix := "  "
+		label := b.start.Format(layout)
+		if i == h.cursor {
+			prefix = lipgloss.NewStyle().Foreground(stylesheet.FocusedColor).
+				Render(string(stylesheet.SelectionPrefix)) + " "
+			label = cursorStyle.Render(label)
+		}
+		lines[i] = fmt.Sprintf("%s%s │%s %*d", prefix, label,
+			barStyle().Render(bar(b.count, maxCount, barWidth)), countWidth, b.count)
+	}
+	h.vp.SetContent(strings.Join(lines, "\n"))
+
+	if h.cursor < h.vp.YOffset {
+		h.vp.SetYOffset(h.cursor)
+	} else if h.cursor >= h.vp.YOffset+h.vp.Height {
+		h.vp.SetYOffset(h.cursor - h.vp.Height + 1)
+	}
+}
+
+// Returns a bar representing count, scaled such that maxCount fills width.
+// Any non-zero count is drawn as at least a sliver.
+func bar(count, maxCount, width int) string {
+	if count == 0 || maxCount == 0 {
+		return strings.Repeat(" ", width)
+	}
+	eighths := max(int(math.Round(float64(count)/float64(maxCount)*float64(width*8))), 1)
+	s := strings.Repeat("█", eighths/8) + barEighths[eighths%8]
+	return s + strings.Repeat(" ", width-lipgloss.Width(s))
+}
+
+func viewHistogram(s *DataScope) string {
+	h := &s.histogram
+	if !h.ready {
+		return "\nInitializing..."
+	}
+	return h.renderHeader(s.find.window) + "\n" + h.vp.View() + "\n" + h.renderFooter()
+}
+
+// Describes the time range and bucketing.
+func (h *histogramTab) renderHeader(narrowed *timeWindow) string {
+	if len(h.buckets) == 0 {
+		return ""
+	}
+	desc := fmt.Sprintf("%v • %v buckets of %v", timeWindow{h.start, h.end}, len(h.buckets),
+		h.size())
+	if narrowed != nil {
+		desc += " • narrowed to " + narrowed.String()
+	}
+	return lipgloss.NewStyle().Width(h.vp.Width).AlignHorizontal(lipgloss.Center).
+		Render(stylesheet.Header1Style.Render(desc))
+}
+
+func histogramShortHelp() string {
+	return stylesheet.GreyedOutStyle.Render(
+		fmt.Sprintf("%v select bucket • %v: coarser/finer/automatic buckets\n"+
+			"enter: jump to bucket • %v: narrow records to bucket/clear • %v: cycle • %v: quit",
+			hint(viewportKeys.Up, viewportKeys.Down),
+			hint(keys.pageSizeUp, keys.pageSizeDown, keys.pageSizeAuto), hint(keys.filter),
+			hint(keys.cycleTabs), hint(killer.ChildKill)),
+	)
+}
+
+func (h *histogramTab) renderFooter() string {
+	help := histogramShortHelp()
+	if h.err != "" {
+		help = helpOrNotice(help, stylesheet.ErrStyle.Render(h.err), h.vp.Width)
+	}
+	return lipgloss.JoinVertical(lipgloss.Center,
+		scrollPercentLine(h.vp.Width, h.vp.ScrollPercent()),
+		lipgloss.NewStyle().Width(h.vp.Width).AlignHorizontal(lipgloss.Center).Render(help),
+	)
+}
+
+// The clipped height is the height available to the histogram tab (height - tabs height).
+func (h *histogramTab) recalculateSize(rawWidth, clippedHeight int) {
+	h.vp.Width = rawWidth
+	h.vp.Height = max(1, clippedHeight-lipgloss.Height(h.renderHeader(nil))-
+		lipgloss.Height(h.renderFooter())-1) // header is separated by a newline
+	h.refresh()
+	h.ready = true
+}
+
+//#region DS integration
+
+// Returns the timestamp of each record.
+func (s *DataScope) recordTimes() []time.Time {
+	times := make([]time.Time, s.records.Len())
+	for i := range times {
+		times[i] = s.recordTime(i)
+	}
+	return times
+}
+
+// Returns the timestamp of the given record; zero if it has none.
+func (s *DataScope) recordTime(idx int) time.Time {
+	var t time.Time
+	if s.tableMode {
+		t = s.records.Rows[idx].TS.StandardTime()
+	} else {
+		t = s.records.Entries[idx].TS.StandardTime()
+	}
+	if t.Unix() == 0 {
+		return time.Time{}
+	}
+	return t
+}
+
+// Switches to the results/table tab, placing the cursor on the first displayed record within the
+// window.
+// Returns false if no displayed record is within the window.
+func (s *DataScope) jumpToWindow(w timeWindow) bool {
+	for _, i := range s.shownRecords() {
+		if w.contains(s.recordTime(i)) {
+			s.activeTab = results
+			s.jumpToRecord(i)
+			return true
+		}
+	}
+	return false
+}
+
+// Narrows the displayed records to those within the given window (or, if nil, stops narrowing).
+func (s *DataScope) setWindow(w *timeWindow) {
+	s.find.window = w
+	s.applyFilter()
+	s.recalculateWindowMargins(s.rawWidth, s.rawHeight) // the finder's bar may have (dis)appeared
+}
+
+//#endregion
@@ -0,0 +1,123 @@
+package datascope
+
+import (
+	"gwcli/tree/query/searchstats"
+	"gwcli/tree/query/transcode"
+	"reflect"
+	"testing"
+	"time"
+
+	grav "github.com/gravwell/gravwell/v3/client"
+	"github.com/gravwell/gravwell/v3/client/types"
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+)
+
+func Test_autoBucketSize(t *testing.T) {
+	tests := []struct {
+		span time.Duration
+		want time.Duration
+	}{
+		{0, time.Second},
+		{30 * time.Second, time.Second},
+		{time.Hour, 2 * time.Minute},
+		{24 * time.Hour, time.Hour},
+		{10 * 365 * 24 * time.Hour, 30 * 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := bucketSizes[autoBucketSize(tt.span)]; got != tt.want {
+			t.Errorf("autoBucketSize(%v) = %v, want %v", tt.span, got, tt.want)
+		}
+	}
+}
+
+func Test_bar(t *testing.T) {
+	tests := []struct {
+		count, maxCount int
+		want            string
+	}{
+		{0, 10, "    "},
+		{10, 10, "████"},
+		{5, 10, "██  "},
+		{1, 100, "▏   "}, // non-zero counts are always visible
+		{3, 8, "█▌  "},
+	}
+	for _, tt := range tests {
+		if got := bar(tt.count, tt.maxCount, 4); got != tt.want {
+			t.Errorf("bar(%v, %v) = %q, want %q", tt.count, tt.maxCount, got, tt.want)
+		}
+	}
+}
+
+func TestDataScope_histogram(t *testing.T) {
+	base := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	offsets := []time.Duration{0, 10 * time.Second, 70 * time.Second, 75 * time.Second,
+		3 * time.Minute}
+	entries := make([]types.SearchEntry, len(offsets))
+	data := make([]string, len(offsets))
+	for i, o := range offsets {
+		entries[i].TS = entry.FromStandard(base.Add(o))
+		data[i] = string(rune('a' + i))
+		entries[i].Data = []byte(data[i])
+	}
+	s := DataScope{records: transcode.Results{Entries: entries}, results: initResultsTab(data),
+		find: initFinder()}
+	s.results.vp.Width, s.results.vp.Height = 80, 10
+	// the search's range extends past the final entry
+	s.histogram = initHistogramTab(s.recordTimes(), base, base.Add(4*time.Minute))
+	s.histogram.sizeIdx = 6 // 1m
+	s.histogram.rebucket()
+
+	var counts []int
+	for _, b := range s.histogram.buckets {
+		counts = append(counts, b.count)
+	}
+	if want := []int{2, 2, 0, 1, 0}; !reflect.DeepEqual(counts, want) {
+		t.Fatalf("bucket counts = %v, want %v", counts, want)
+	}
+
+	// narrow to the second minute, then jump to it
+	w := s.histogram.window(1)
+	s.setWindow(&w)
+	if got, want := s.shownRecords(), []int{2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("narrowed records = %v, want %v", got, want)
+	}
+	if s.jumpToWindow(s.histogram.window(0)) {
+		t.Error("jumped to a window with no displayed records")
+	}
+	s.activeTab = histogram
+	if !s.jumpToWindow(w) || s.currentRecord() != 2 || s.activeTab != results {
+		t.Errorf("jump left record %v current on tab %v", s.currentRecord(), s.activeTab)
+	}
+	s.setWindow(nil)
+	if got := len(s.shownRecords()); got != len(entries) {
+		t.Errorf("clearing the window displays %d records, want %d", got, len(entries))
+	}
+}
+
+func TestDataScope_applyStats(t *testing.T) {
+	base := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	entries := []types.SearchEntry{{TS: entry.FromStandard(base.Add(time.Minute))}}
+	newScope := func(sid string) *DataScope {
+		s := &DataScope{records: transcode.Results{Entries: entries}, search: &grav.Search{ID: sid},
+			stats: initStatsTab()}
+		s.histogram = initHistogramTab(s.recordTimes(), time.Time{}, time.Time{})
+		return s
+	}
+	prev, cur := newScope("1"), newScope("2")
+	cur.back = prev
+
+	// stats arriving after a pivot belong to the DataScope on the back stack
+	cur.applyStats(statsMsg{sid: "1",
+		stats: searchstats.Stats{Start: base, End: base.Add(time.Hour)}})
+	if cur.stats.fetched || !prev.stats.fetched {
+		t.Fatalf("stats applied to the wrong DataScope (current %v, back %v)",
+			cur.stats.fetched, prev.stats.fetched)
+	}
+	if !prev.histogram.start.Equal(base) || !prev.histogram.end.Equal(base.Add(time.Hour)) {
+		t.Errorf("histogram range = %v–%v, want the search's range", prev.histogram.start,
+			prev.histogram.end)
+	}
+	if !cur.histogram.start.Equal(base.Add(time.Minute)) {
+		t.Errorf("current histogram was widened to start at %v", cur.histogram.start)
+	}
+}
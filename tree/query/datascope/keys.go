@@ -1,22 +1,209 @@
 package datascope
 
 import (
+	"gwcli/utilities/keymap"
+	"strconv"
+	"strings"
+
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/paginator"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+// keymap scopes of DataScope's bindings (see keymap.Scope).
+// Records' bindings are active on the results/table tab; text and table bindings are only active
+// in their respective modes. The detail pane and finder prompt consume all keys while open, so
+// their bindings only need to be distinct from DataScope's tab-agnostic bindings.
+var (
+	dsScope      = keymap.NewScope("datascope", keymap.Global)
+	recordsScope = keymap.NewScope("records", dsScope)
+	textScope    = keymap.NewScope("text", recordsScope)
+	tableScope   = keymap.NewScope("table", recordsScope)
+	detailScope  = keymap.NewScope("detail", dsScope)
+	finderScope  = keymap.NewScope("finder", dsScope)
+)
+
 var keys = struct {
 	showTabs         key.Binding
 	cycleTabs        key.Binding
 	reverseCycleTabs key.Binding
+	pipe             key.Binding // open the pipe overlay from the results/table tab
+	pivot            key.Binding // open the pivot overlay from the table tab or detail pane
+	back             key.Binding // return to the results prior to the most recent pivot
+	cancel           key.Binding // close the active prompt or overlay
+	submit           key.Binding // submit the download or schedule tab
+
+	// results/table tab
+	openDetail  key.Binding // open the detail pane on the record under the cursor
+	search      key.Binding
+	filter      key.Binding
+	nextMatch   key.Binding
+	prevMatch   key.Binding
+	toggleRegex key.Binding // while the search/filter prompt is open
+
+	copyRecord    key.Binding // record, or table row as CSV
+	copyJSON      key.Binding // record or table row as JSON
+	copySelection key.Binding
+
+	toggleSelect key.Binding
+	selectUp     key.Binding // extend the selection upward
+	selectDown   key.Binding // extend the selection downward
+	selectAll    key.Binding // select all displayed records or clear the selection
+
+	// table tab
+	copyCell        key.Binding
+	prevColumn      key.Binding // move the cell cursor
+	nextColumn      key.Binding
+	sortColumn      key.Binding // cycle ascending/descending/unsorted by the cursor's column
+	hideColumn      key.Binding
+	showColumns     key.Binding // reveal all hidden columns
+	moveColumnLeft  key.Binding
+	moveColumnRight key.Binding
+	freezeColumns   key.Binding   // freeze the columns through the cursor's column
+	growColumn      []key.Binding // grow the (i+1)th displayed column
+	shrinkColumn    []key.Binding // shrink the (i+1)th displayed column
+
+	// results tab
+	pageSizeUp   key.Binding
+	pageSizeDown key.Binding
+	pageSizeAuto key.Binding // size pages to fit the terminal
+	gotoPage     key.Binding
+	gotoRecord   key.Binding
 }{
-	showTabs: key.NewBinding(
-		key.WithKeys(tea.KeyCtrlS.String()),
-	),
-	cycleTabs: key.NewBinding(
-		key.WithKeys(tea.KeyTab.String()),
-	),
-	reverseCycleTabs: key.NewBinding(
-		key.WithKeys(tea.KeyShiftTab.String()),
-	),
+	showTabs: keymap.Bind(dsScope, "show-tabs", "toggle tab visibility",
+		tea.KeyCtrlS.String()),
+	cycleTabs: keymap.Bind(dsScope, "cycle-tabs", "cycle tabs",
+		tea.KeyTab.String()),
+	reverseCycleTabs: keymap.Bind(dsScope, "reverse-cycle-tabs", "reverse cycle tabs",
+		tea.KeyShiftTab.String()),
+	pipe: keymap.Bind(recordsScope, "pipe", "pipe records into a shell command",
+		"|"),
+	pivot: keymap.Bind(recordsScope, "pivot", "pivot on the cell or field into a new query",
+		"p"),
+	back: keymap.Bind(recordsScope, "back", "return to the results prior to the pivot",
+		tea.KeyBackspace.String()),
+	cancel: keymap.Bind(dsScope, "cancel", "close prompt or detail pane",
+		tea.KeyCtrlX.String()),
+	submit: keymap.Bind(dsScope, "submit", "submit download/schedule",
+		"alt+enter"),
+
+	openDetail: keymap.Bind(recordsScope, "details", "show details of the current record",
+		tea.KeyEnter.String()),
+	search: keymap.Bind(recordsScope, "search", "search records",
+		"/"),
+	filter: keymap.Bind(recordsScope, "filter", "filter records",
+		"&"),
+	nextMatch: keymap.Bind(recordsScope, "next-match", "next match",
+		"n"),
+	prevMatch: keymap.Bind(recordsScope, "previous-match", "previous match",
+		"N"),
+	toggleRegex: keymap.Bind(finderScope, "toggle-regex", "toggle regex",
+		tea.KeyCtrlR.String()),
+
+	copyRecord: keymap.Bind(recordsScope, "copy", "copy record (table: row as CSV)",
+		"y"),
+	copyJSON: keymap.Bind(recordsScope, "copy-json", "copy record as JSON",
+		"J"),
+	copySelection: keymap.Bind(recordsScope, "copy-selection", "copy selected records",
+		"Y"),
+
+	toggleSelect: keymap.Bind(recordsScope, "select", "toggle selection of the current record",
+		" "),
+	selectUp: keymap.Bind(recordsScope, "select-up", "extend selection upward",
+		tea.KeyShiftUp.String()),
+	selectDown: keymap.Bind(recordsScope, "select-down", "extend selection downward",
+		tea.KeyShiftDown.String()),
+	selectAll: keymap.Bind(recordsScope, "select-all", "select all/clear selection",
+		tea.KeyCtrlA.String()),
+
+	copyCell: keymap.Bind(tableScope, "copy-cell", "copy cell",
+		"c"),
+	prevColumn: keymap.Bind(tableScope, "previous-column", "move the cursor to the previous column",
+		tea.KeyLeft.String()),
+	nextColumn: keymap.Bind(tableScope, "next-column", "move the cursor to the next column",
+		tea.KeyRight.String()),
+	sortColumn: keymap.Bind(tableScope, "sort", "sort by column",
+		"s"),
+	hideColumn: keymap.Bind(tableScope, "hide-column", "hide column",
+		"x"),
+	showColumns: keymap.Bind(tableScope, "show-columns", "show all columns",
+		"X"),
+	moveColumnLeft: keymap.Bind(tableScope, "move-column-left", "move column left",
+		"<"),
+	moveColumnRight: keymap.Bind(tableScope, "move-column-right", "move column right",
+		">"),
+	freezeColumns: keymap.Bind(tableScope, "freeze-columns", "freeze columns through cursor",
+		"F"),
+	growColumn: bindColumns("grow-column", "increase the size of column",
+		"alt+1", "alt+2", "alt+3", "alt+4", "alt+5", "alt+6", "alt+7", "alt+8", "alt+9", "alt+0"),
+	shrinkColumn: bindColumns("shrink-column", "decrease the size of column",
+		"alt+!", "alt+@", "alt+#", "alt+$", "alt+%", "alt+^", "alt+&", "alt+*", "alt+(", "alt+)"),
+
+	pageSizeUp: keymap.Bind(textScope, "page-size-up", "more records per page",
+		"+"),
+	pageSizeDown: keymap.Bind(textScope, "page-size-down", "fewer records per page",
+		"-"),
+	pageSizeAuto: keymap.Bind(textScope, "page-size-auto", "fit records per page to the window",
+		"="),
+	gotoPage: keymap.Bind(textScope, "goto-page", "go to page",
+		"g"),
+	gotoRecord: keymap.Bind(textScope, "goto-record", "go to record",
+		"#"),
+}
+
+// Binds a table action per displayed column, numbering the actions (and their help) from 1.
+// The ith default key is bound to the ith column.
+func bindColumns(name, help string, defaults ...string) []key.Binding {
+	bs := make([]key.Binding, len(defaults))
+	for i, k := range defaults {
+		n := strconv.Itoa(i + 1)
+		bs[i] = keymap.Bind(tableScope, name+"-"+n, help+" "+n, k)
+	}
+	return bs
+}
+
+// keys directly supported by the viewport bubble; other keybinds are managed by the tabs
+var viewportKeys = viewport.KeyMap{
+	PageDown: keymap.Bind(dsScope, "page-down", "scroll down a page",
+		"pgdown", "f"), // space is reserved for selection
+	PageUp: keymap.Bind(dsScope, "page-up", "scroll up a page",
+		"pgup", "b"),
+	HalfPageUp: keymap.Bind(dsScope, "half-page-up", "scroll up half a page",
+		"u", "ctrl+u"),
+	HalfPageDown: keymap.Bind(dsScope, "half-page-down", "scroll down half a page",
+		"d", "ctrl+d"),
+	Up: keymap.Bind(dsScope, "up", "scroll up",
+		"up", "k"),
+	Down: keymap.Bind(dsScope, "down", "scroll down",
+		"down", "j"),
+}
+
+// keys of the results tab's paginator; pgup/pgdown are left to the viewport
+var pageKeys = paginator.KeyMap{
+	PrevPage: keymap.Bind(textScope, "previous-page", "previous page",
+		"left", "h"),
+	NextPage: keymap.Bind(textScope, "next-page", "next page",
+		"right", "l"),
+}
+
+// arrow keys are displayed as glyphs in footers, to keep them compact
+var arrows = map[string]string{"up": "↑", "down": "↓", "left": "←", "right": "→"}
+
+// Returns the primary (first) key of each of the given bindings, joined for display in footers.
+// The help tab lists every key of each binding.
+func hint(bindings ...key.Binding) string {
+	ks := make([]string, 0, len(bindings))
+	for _, b := range bindings {
+		if len(b.Keys()) == 0 {
+			continue
+		}
+		k := b.Keys()[0]
+		i := strings.LastIndex(k, "+") + 1 // preserve modifiers
+		if glyph, ok := arrows[k[i:]]; ok {
+			k = k[:i] + glyph
+		}
+		ks = append(ks, keymap.Display(k))
+	}
+	return strings.Join(ks, "/")
 }
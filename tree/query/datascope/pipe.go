@@ -0,0 +1,287 @@
+package datascope
+
+/**
+ * The pipe overlay feeds DataScope's records (the current page, the selection, or all of them,
+ * less any that are filtered out) to a local shell command on stdin and displays the command's
+ * output in a scrollable viewport.
+ * It sits atop the results/table tab; while active, it consumes all key input.
+ */
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"gwcli/clilog"
+	"gwcli/stylesheet"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maximum time a piped command is allowed to run before it is killed
+const pipeTimeout = 30 * time.Second
+
+type pipeState uint
+
+const (
+	pipeInactive   pipeState = iota
+	pipePrompting            // user is entering a command
+	pipeRunning              // command is executing
+	pipeDisplaying           // command output is being displayed
+)
+
+// records to be fed to the piped command
+type pipeScope uint
+
+const (
+	pipeScopePage      pipeScope = iota // the records on the current page of the results tab
+	pipeScopeAll                        // all displayed records
+	pipeScopeSelection                  // selected records, regardless of the filter
+)
+
+func (ps pipeScope) String() string {
+	switch ps {
+	case pipeScopePage:
+		return "page"
+	case pipeScopeAll:
+		return "all"
+	case pipeScopeSelection:
+		return "selected"
+	}
+	return fmt.Sprintf("unknown scope %d", ps)
+}
+
+type pipeOverlay struct {
+	state   pipeState
+	scope   pipeScope
+	ti      textinput.Model
+	vp      viewport.Model
+	run     uint   // id of the most recent execution, so stale results can be discarded
+	command string // command that generated the displayed output
+	output  string // output of the most recent command
+	status  string // exit status of the command that generated the displayed output
+}
+
+// pipeResultMsg is returned by a piped command once it completes.
+type pipeResultMsg struct {
+	run    uint
+	output []byte
+	err    error
+}
+
+func initPipe() pipeOverlay {
+	ti := stylesheet.NewTI("", false)
+	ti.Prompt = stylesheet.TIPromptPrefix
+	ti.Placeholder = "jq .src | sort | uniq -c"
+	return pipeOverlay{ti: ti, vp: NewViewport()}
+}
+
+// Returns the scopes currently available to pipe.
+// Table mode does not paginate, so it does not support piping a page.
+func (s *DataScope) pipeScopes() []pipeScope {
+	var scopes []pipeScope
+	if !s.tableMode {
+		scopes = append(scopes, pipeScopePage)
+	}
+	scopes = append(scopes, pipeScopeAll)
+	if len(s.selected) > 0 {
+		scopes = append(scopes, pipeScopeSelection)
+	}
+	return scopes
+}
+
+// Opens the pipe overlay's prompt, defaulting to the selection if there is one.
+func (s *DataScope) openPipe() tea.Cmd {
+	s.pipe.state = pipePrompting
+	scopes := s.pipeScopes()
+	if len(s.selected) > 0 {
+		s.pipe.scope = pipeScopeSelection
+	} else if !slices.Contains(scopes, s.pipe.scope) {
+		s.pipe.scope = scopes[0]
+	}
+	return s.pipe.ti.Focus()
+}
+
+func (s *DataScope) closePipe() {
+	s.pipe.state = pipeInactive
+	s.pipe.run++ // discard any in-flight results
+	s.pipe.ti.Blur()
+}
+
+// Handles all input while the overlay is active.
+func updatePipe(s *DataScope, msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(pipeResultMsg); ok {
+		if msg.run != s.pipe.run || s.pipe.state != pipeRunning {
+			return nil // stale
+		}
+		s.pipe.status = "exit 0"
+		if msg.err != nil {
+			s.pipe.status = msg.err.Error()
+		}
+		s.pipe.output = string(msg.output)
+		s.pipe.vp.SetContent(wrap(s.pipe.vp.Width, s.pipe.output))
+		s.pipe.vp.GotoTop()
+		s.pipe.state = pipeDisplaying
+		return nil
+	}
+
+	keyMsg, isKey := msg.(tea.KeyMsg)
+	if isKey && key.Matches(keyMsg, keys.cancel) {
+		s.closePipe()
+		return textinput.Blink
+	}
+
+	switch s.pipe.state {
+	case pipePrompting:
+		if isKey {
+			switch keyMsg.Type {
+			case tea.KeyEnter:
+				command := strings.TrimSpace(s.pipe.ti.Value())
+				if command == "" {
+					return nil
+				}
+				s.pipe.command = command
+				s.pipe.state = pipeRunning
+				s.pipe.run++
+				s.pipe.ti.Blur()
+				return runPipe(s.pipe.run, command, s.pipeInput(s.pipe.scope))
+			case tea.KeyTab, tea.KeyShiftTab:
+				scopes := s.pipeScopes()
+				i := slices.Index(scopes, s.pipe.scope) + 1
+				s.pipe.scope = scopes[i%len(scopes)]
+				return nil
+			}
+		}
+		var cmd tea.Cmd
+		s.pipe.ti, cmd = s.pipe.ti.Update(msg)
+		return cmd
+	case pipeDisplaying:
+		if isKey {
+			switch {
+			case keyMsg.String() == "q":
+				s.closePipe()
+				return textinput.Blink
+			case keyMsg.Type == tea.KeyEnter: // return to the prompt to edit the command
+				s.pipe.state = pipePrompting
+				return s.pipe.ti.Focus()
+			}
+		}
+		if viewportAddtlKeys(msg, &s.pipe.vp) {
+			return nil
+		}
+		var cmd tea.Cmd
+		s.pipe.vp, cmd = s.pipe.vp.Update(msg)
+		return cmd
+	}
+	return nil
+}
+
+// Returns the records in the given scope as newline-delimited input for a piped command.
+func (s *DataScope) pipeInput(scope pipeScope) []byte {
+	var (
+		all   = recordLines(s.records)
+		shown = s.shownRecords()
+	)
+	switch scope {
+	case pipeScopePage:
+		if !s.tableMode {
+			start, end := s.results.pager.GetSliceBounds(len(shown))
+			shown = shown[start:end]
+		}
+	case pipeScopeSelection:
+		shown = s.selected.indices()
+	}
+	if len(shown) == 0 {
+		return nil
+	}
+	var sb strings.Builder
+	for _, i := range shown {
+		sb.WriteString(all[i])
+		sb.WriteRune('\n')
+	}
+	return []byte(sb.String())
+}
+
+// Returns a command that executes the given shell command, feeding it input on stdin.
+// Stdout and stderr are combined.
+func runPipe(run uint, command string, input []byte) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), pipeTimeout)
+		defer cancel()
+
+		var c *exec.Cmd
+		if runtime.GOOS == "windows" {
+			c = exec.CommandContext(ctx, "cmd", "/C", command)
+		} else {
+			c = exec.CommandContext(ctx, "sh", "-c", command)
+		}
+		c.Stdin = bytes.NewReader(input)
+		out, err := c.CombinedOutput()
+		if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("killed after %v", pipeTimeout)
+		}
+		if err != nil {
+			clilog.Writer.Infof("piped command '%v' failed: %v", command, err)
+		}
+		return pipeResultMsg{run: run, output: out, err: err}
+	}
+}
+
+func viewPipe(s *DataScope) string {
+	titleSty := stylesheet.Header1Style
+	help := stylesheet.GreyedOutStyle
+	switch s.pipe.state {
+	case pipeDisplaying:
+		header := titleSty.Render("| "+s.pipe.command) + " " + help.Render("("+s.pipe.status+")")
+		footer := lipgloss.NewStyle().Width(s.pipe.vp.Width).AlignHorizontal(lipgloss.Center).
+			Render(help.Render(fmt.Sprintf("%v scroll • enter: edit command • q/%v: close",
+				stylesheet.UpDown, hint(keys.cancel))))
+		return lipgloss.JoinVertical(lipgloss.Left,
+			header,
+			s.pipe.vp.View(),
+			scrollPercentLine(s.pipe.vp.Width, s.pipe.vp.ScrollPercent()),
+			footer)
+	case pipeRunning:
+		return lipgloss.Place(s.usableWidth(), s.usableHeight(), lipgloss.Center, lipgloss.Center,
+			"Running "+titleSty.Render(s.pipe.command)+"...")
+	}
+
+	// prompting
+	var scopes []string
+	for _, sc := range s.pipeScopes() {
+		str := sc.String()
+		if sc == s.pipe.scope {
+			str = lipgloss.NewStyle().Foreground(stylesheet.FocusedColor).Render(
+				string(stylesheet.SelectionPrefix) + str)
+		} else {
+			str = " " + str
+		}
+		scopes = append(scopes, str)
+	}
+	body := lipgloss.JoinVertical(lipgloss.Left,
+		titleSty.Render("Pipe records to a shell command"),
+		"",
+		"records: "+strings.Join(scopes, "  "),
+		"| "+s.pipe.ti.View(),
+		"",
+		help.Render(fmt.Sprintf("enter: run • tab: change records • %v: cancel",
+			hint(keys.cancel))),
+	)
+	return lipgloss.Place(s.usableWidth(), s.usableHeight(), lipgloss.Center, verticalPlace, body)
+}
+
+// The clipped height is the height available to the overlay (height - tabs height).
+func (po *pipeOverlay) recalculateSize(rawWidth, clippedHeight int) {
+	po.ti.Width = max(20, rawWidth/2)
+	po.vp.Width = rawWidth
+	po.vp.Height = max(1, clippedHeight-3) // header, scroll line, and help
+	po.vp.SetContent(wrap(rawWidth, po.output))
+}
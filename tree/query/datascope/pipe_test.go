@@ -0,0 +1,36 @@
+package datascope
+
+import (
+	"gwcli/tree/query/transcode"
+	"testing"
+
+	"github.com/gravwell/gravwell/v3/client/types"
+)
+
+func TestDataScope_pipeInput(t *testing.T) {
+	entries := make([]types.SearchEntry, 30)
+	data := make([]string, 30)
+	for i := range entries {
+		data[i] = string(rune('a' + i%26))
+		entries[i].Data = []byte(data[i])
+	}
+	s := DataScope{records: transcode.Results{Entries: entries}, results: initResultsTab(data)}
+	s.results.pager.Page = 1 // second page holds records 26-30
+
+	if got, want := string(s.pipeInput(pipeScopePage)), "z\na\nb\nc\nd\n"; got != want {
+		t.Errorf("page input = %q, want %q", got, want)
+	}
+	if got := s.pipeInput(pipeScopeAll); len(got) != 60 {
+		t.Errorf("all input is %d bytes, want 60", len(got))
+	}
+}
+
+func Test_runPipe(t *testing.T) {
+	msg := runPipe(3, "tr a-z A-Z", []byte("foo\nbar\n"))().(pipeResultMsg)
+	if msg.err != nil {
+		t.Fatal(msg.err)
+	}
+	if msg.run != 3 || string(msg.output) != "FOO\nBAR\n" {
+		t.Errorf("runPipe() = (%d, %q), want (3, \"FOO\\nBAR\\n\")", msg.run, msg.output)
+	}
+}
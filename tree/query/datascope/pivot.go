@@ -0,0 +1,331 @@
+package datascope
+
+/**
+ * Pivoting launches a new query from a value in the current results: the cell under the table's
+ * cursor or the field under the detail pane's cursor.
+ * The user picks one of their pivot templates (and may edit the query it produces); the query is
+ * run over the same time range as the current search and its results replace the current ones.
+ *
+ * The replaced DataScope is kept on a back stack, so the user can return to it.
+ * Like the pipe overlay, the pivot overlay sits atop the results/table tab and consumes all key
+ * input while open.
+ */
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"gwcli/clilog"
+	"gwcli/stylesheet"
+	activesearchlock "gwcli/tree/query/datascope/ActiveSearchLock"
+	"gwcli/tree/query/transcode"
+	"gwcli/utilities/cfgdir"
+	"gwcli/utilities/uniques"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	grav "github.com/gravwell/gravwell/v3/client"
+)
+
+// file pivot templates are read from; a variable so tests can redirect it
+var pivotsPath = cfgdir.DefaultPivotsPath
+
+// templates offered if the user has not written their own
+var defaultPivotTemplates = []string{
+	`tag=* grep "{{value}}"`,
+	`tag=* words {{value}}`,
+	`tag=* ax {{column}}=="{{value}}" | table`,
+}
+
+// PivotFunc runs the given query over the given time range, returning the completed search and its
+// results.
+// It is supplied by the caller (see WithPivot), as DataScope does not fetch results itself.
+type PivotFunc func(qry string, start, end time.Time) (*grav.Search, transcode.Results, error)
+
+// Reads the user's pivot templates from the file at path: one per line, ignoring blank lines and
+// #-comments.
+// Returns the default templates if the file does not exist or contains no templates.
+func loadPivotTemplates(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return defaultPivotTemplates, nil
+		}
+		return defaultPivotTemplates, err
+	}
+	defer f.Close()
+	var templates []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if line := strings.TrimSpace(sc.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			templates = append(templates, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return defaultPivotTemplates, err
+	}
+	if len(templates) == 0 {
+		return defaultPivotTemplates, nil
+	}
+	return templates, nil
+}
+
+// Fills the template's {{value}} and {{column}} placeholders.
+func expandPivot(template, column, value string) string {
+	return strings.NewReplacer("{{value}}", value, "{{column}}", column).Replace(template)
+}
+
+type pivotState uint
+
+const (
+	pivotInactive pivotState = iota
+	pivotChoosing            // user is picking a template and editing the query
+	pivotRunning             // query is executing
+)
+
+type pivotOverlay struct {
+	state     pivotState
+	column    string // name of the pivoted column or field
+	value     string // pivoted value
+	templates []string
+	selected  int // index of the template the query was drawn from
+	ti        textinput.Model
+	run       uint   // id of the most recent query, so stale results can be discarded
+	err       string // issue with the most recent query
+}
+
+// pivotResultMsg is returned by a pivot query once it completes.
+type pivotResultMsg struct {
+	run     uint
+	search  *grav.Search
+	results transcode.Results
+	err     error
+}
+
+func initPivot() pivotOverlay {
+	ti := stylesheet.NewTI("", false)
+	ti.Prompt = stylesheet.TIPromptPrefix
+	return pivotOverlay{ti: ti}
+}
+
+// Selects the template at the given index, replacing the query with its expansion.
+func (po *pivotOverlay) choose(i int) {
+	po.selected = (i + len(po.templates)) % len(po.templates)
+	po.ti.SetValue(expandPivot(po.templates[po.selected], po.column, po.value))
+	po.ti.CursorEnd()
+}
+
+// Returns the column and value under the cursor of the detail pane (if it is open) or the table.
+func (s *DataScope) pivotTarget() (column, value string, err error) {
+	if s.detail.active && s.detail.tree != nil {
+		n := s.detail.nodes[s.detail.cursor]
+		if n.container || n.parent == nil {
+			return "", "", errors.New("select a field to pivot on")
+		}
+		if n.value == nil {
+			return n.key, "null", nil
+		}
+		return n.key, fmt.Sprint(n.value), nil
+	}
+	if !s.tableMode {
+		return "", "", errors.New("pivoting requires a table cell or JSON field")
+	}
+	row, col := s.table.cursor, s.table.column
+	if s.detail.active {
+		row = s.detail.record
+	} else if !s.anyShown() {
+		return "", "", errors.New("no cell to pivot on")
+	}
+	if row >= len(s.records.Rows) || col >= len(s.records.Columns) ||
+		col >= len(s.records.Rows[row].Row) {
+		return "", "", errors.New("no cell to pivot on")
+	}
+	return s.records.Columns[col], s.records.Rows[row].Row[col], nil
+}
+
+// Opens the pivot overlay on the value under the cursor.
+func (s *DataScope) openPivot() tea.Cmd {
+	if s.pivotFunc == nil {
+		return s.flash(stylesheet.ErrStyle.Render("pivoting is unavailable"))
+	}
+	column, value, err := s.pivotTarget()
+	if err != nil {
+		return s.flash(stylesheet.ErrStyle.Render(err.Error()))
+	}
+	templates, err := loadPivotTemplates(pivotsPath)
+	if err != nil {
+		clilog.Writer.Warnf("failed to read pivot templates from %v: %v", pivotsPath, err)
+	}
+	s.pivot.state, s.pivot.err = pivotChoosing, ""
+	s.pivot.column, s.pivot.value, s.pivot.templates = column, value, templates
+	s.pivot.choose(0)
+	return s.pivot.ti.Focus()
+}
+
+func (s *DataScope) closePivot() {
+	s.pivot.state = pivotInactive
+	s.pivot.run++ // discard any in-flight results
+	s.pivot.ti.Blur()
+}
+
+// Handles all input while the overlay is active.
+// Returns the DataScope that should replace s, if the pivot query completed successfully.
+func updatePivot(s *DataScope, msg tea.Msg) (*DataScope, tea.Cmd) {
+	if msg, ok := msg.(pivotResultMsg); ok {
+		if msg.run != s.pivot.run || s.pivot.state != pivotRunning {
+			return nil, nil // stale
+		}
+		if msg.err != nil {
+			s.pivot.state, s.pivot.err = pivotChoosing, msg.err.Error()
+			return nil, s.pivot.ti.Focus()
+		}
+		return s.pivotTo(msg.search, msg.results)
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil, nil
+	}
+	if key.Matches(keyMsg, keys.cancel) {
+		s.closePivot()
+		return nil, textinput.Blink
+	}
+	if s.pivot.state != pivotChoosing {
+		return nil, nil
+	}
+	switch keyMsg.Type {
+	case tea.KeyUp:
+		s.pivot.choose(s.pivot.selected - 1)
+		return nil, nil
+	case tea.KeyDown:
+		s.pivot.choose(s.pivot.selected + 1)
+		return nil, nil
+	case tea.KeyEnter:
+		qry := strings.TrimSpace(s.pivot.ti.Value())
+		if qry == "" {
+			return nil, nil
+		}
+		start, end := s.timeRange()
+		s.pivot.state, s.pivot.err = pivotRunning, ""
+		s.pivot.run++
+		s.pivot.ti.Blur()
+		run, pivot := s.pivot.run, s.pivotFunc
+		return nil, func() tea.Msg {
+			search, res, err := pivot(qry, start, end)
+			if err != nil {
+				clilog.Writer.Infof("pivot query '%v' failed: %v", qry, err)
+			}
+			return pivotResultMsg{run: run, search: search, results: res, err: err}
+		}
+	}
+	s.pivot.err = ""
+	var cmd tea.Cmd
+	s.pivot.ti, cmd = s.pivot.ti.Update(msg)
+	return nil, cmd
+}
+
+// Returns the time range of the displayed search; zero if it cannot be determined.
+func (s *DataScope) timeRange() (start, end time.Time) {
+	if s.search == nil {
+		return time.Time{}, time.Time{}
+	}
+	var err error
+	if start, err = time.Parse(uniques.SearchTimeFormat, s.search.SearchStart); err != nil {
+		clilog.Writer.Warnf("failed to read search start time: %v", err)
+		return time.Time{}, time.Time{}
+	}
+	if end, err = time.Parse(uniques.SearchTimeFormat, s.search.SearchEnd); err != nil {
+		clilog.Writer.Warnf("failed to read search end time: %v", err)
+		return time.Time{}, time.Time{}
+	}
+	return start, end
+}
+
+// Returns a new DataScope displaying the pivot's results, with s on its back stack.
+func (s *DataScope) pivotTo(search *grav.Search, res transcode.Results) (*DataScope, tea.Cmd) {
+	s.closePivot()
+	s.detail.active = false
+	next, _, err := NewDataScope(res, s.motherRunning, search,
+		WithPivot(s.pivotFunc), WithPageSize(s.results.pageSize))
+	if err != nil {
+		s.pivot.state, s.pivot.err = pivotChoosing, err.Error()
+		return nil, s.pivot.ti.Focus()
+	}
+	prev := *s
+	next.back = &prev
+	next.showTabs = s.showTabs
+	next.recalculateWindowMargins(s.rawWidth, s.rawHeight)
+	recompileHelp(&next)
+	return &next, tea.Batch(fetchStats(search), textinput.Blink)
+}
+
+// Returns the DataScope on top of the back stack, restoring its search's heartbeat.
+func (s *DataScope) popBack() *DataScope {
+	prev := s.back
+	activesearchlock.SetSearchID(prev.search.ID)
+	activesearchlock.UpdateTS()
+	go keepAlive(prev.search)
+	prev.recalculateWindowMargins(s.rawWidth, s.rawHeight) // the window may have been resized
+	recompileHelp(prev)
+	return prev
+}
+
+// Returns the number of DataScopes on the back stack.
+func (s *DataScope) backDepth() (depth int) {
+	for b := s.back; b != nil; b = b.back {
+		depth++
+	}
+	return depth
+}
+
+func viewPivot(s *DataScope) string {
+	var (
+		titleSty = stylesheet.Header1Style
+		help     = stylesheet.GreyedOutStyle
+	)
+	if s.pivot.state == pivotRunning {
+		return lipgloss.Place(s.usableWidth(), s.usableHeight(), lipgloss.Center, lipgloss.Center,
+			"Running "+titleSty.Render(s.pivot.ti.Value())+"...")
+	}
+
+	templates := make([]string, len(s.pivot.templates))
+	for i, t := range s.pivot.templates {
+		if i == s.pivot.selected {
+			templates[i] = lipgloss.NewStyle().Foreground(stylesheet.FocusedColor).
+				Render(string(stylesheet.SelectionPrefix) + t)
+		} else {
+			templates[i] = " " + t
+		}
+	}
+	start, end := s.timeRange()
+	rng := "the default duration"
+	if !start.IsZero() {
+		rng = timeWindow{start, end}.String()
+	}
+	body := []string{
+		titleSty.Render("Pivot on "+s.pivot.column) + " " + help.Render("("+s.pivot.value+")"),
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, templates...),
+		"",
+		s.pivot.ti.View(),
+		help.Render("over " + rng),
+		"",
+		help.Render(fmt.Sprintf("%v: choose template • enter: run • %v: cancel", stylesheet.UpDown,
+			hint(keys.cancel))),
+	}
+	if s.pivot.err != "" {
+		body = append(body, stylesheet.ErrStyle.Render(s.pivot.err))
+	}
+	return lipgloss.Place(s.usableWidth(), s.usableHeight(), lipgloss.Center, verticalPlace,
+		lipgloss.NewStyle().MaxWidth(s.usableWidth()).Render(lipgloss.JoinVertical(lipgloss.Left, body...)))
+}
+
+// Sizes the query prompt to the window.
+func (po *pivotOverlay) recalculateSize(rawWidth int) {
+	po.ti.Width = max(20, rawWidth*2/3)
+}
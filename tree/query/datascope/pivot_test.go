@@ -0,0 +1,82 @@
+package datascope
+
+import (
+	"gwcli/tree/query/transcode"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/gravwell/gravwell/v3/client/types"
+)
+
+func Test_loadPivotTemplates(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content *string // nil to leave the file nonexistent
+		want    []string
+	}{
+		{"no file", nil, defaultPivotTemplates},
+		{"only comments", ptr("# tag=* grep {{value}}\n\n"), defaultPivotTemplates},
+		{"templates", ptr("# by ip\ntag=netflow ip {{value}}\n\n  tag=* ax {{column}}=={{value}}  \n"),
+			[]string{"tag=netflow ip {{value}}", "tag=* ax {{column}}=={{value}}"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := dir + "/" + tt.name
+			if tt.content != nil {
+				if err := os.WriteFile(path, []byte(*tt.content), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := loadPivotTemplates(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadPivotTemplates() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func Test_expandPivot(t *testing.T) {
+	got := expandPivot(`tag=* ax {{column}}=="{{value}}" | grep {{value}}`, "src", "10.0.0.1")
+	if want := `tag=* ax src=="10.0.0.1" | grep 10.0.0.1`; got != want {
+		t.Errorf("expandPivot() = %q, want %q", got, want)
+	}
+}
+
+func TestDataScope_pivotTarget(t *testing.T) {
+	// table cell under the cursor
+	s := DataScope{tableMode: true, records: transcode.Results{Columns: []string{"src", "dst"},
+		Rows: []types.TableRow{{Row: []string{"10.0.0.1", "10.0.0.2"}}}}}
+	s.table.column, s.table.shown = 1, []int{0}
+	if col, val, err := s.pivotTarget(); err != nil || col != "dst" || val != "10.0.0.2" {
+		t.Errorf("pivotTarget() = (%q, %q, %v), want (dst, 10.0.0.2, nil)", col, val, err)
+	}
+	// the filter hides every row
+	s.table.shown = []int{}
+	if _, _, err := s.pivotTarget(); err == nil {
+		t.Error("pivotTarget() targeted a hidden row")
+	}
+
+	// JSON field under the detail pane's cursor
+	s = DataScope{records: transcode.Results{Entries: []types.SearchEntry{
+		{Data: []byte(`{"user": {"name": "bob", "id": 7}}`)},
+	}}, detail: detailPane{vp: NewViewport()}}
+	s.openDetail(0)
+	for cursor, want := range []struct {
+		col, val string
+		err      bool
+	}{{err: true}, {err: true}, {"name", "bob", false}, {"id", "7", false}} {
+		s.detail.cursor = cursor
+		col, val, err := s.pivotTarget()
+		if (err != nil) != want.err || col != want.col || val != want.val {
+			t.Errorf("pivotTarget() on node %d = (%q, %q, %v), want (%q, %q, error: %v)",
+				cursor, col, val, err, want.col, want.val, want.err)
+		}
+	}
+}
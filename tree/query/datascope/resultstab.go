@@ -8,32 +8,61 @@ import (
 	"fmt"
 	"gwcli/stylesheet"
 	"gwcli/stylesheet/colorizer"
+	"gwcli/utilities/killer"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/paginator"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
+// records per page until the window size is known (or if the user sets a fixed size)
+const defaultPageSize = 25
+
+// amount the page size keys alter the records per page by
+const pageSizeStep = 5
+
+// the go-to prompt currently accepting input, if any
+type gotoMode uint
+
+const (
+	gotoNone gotoMode = iota
+	gotoPage
+	gotoRecord
+)
+
 type resultsTab struct {
-	vp    viewport.Model
-	pager paginator.Model
-	data  []string // complete set of data to be paged
+	vp       viewport.Model
+	pager    paginator.Model
+	data     []string  // complete set of data to be paged
+	shown    []int     // indices of the data to display (those that pass the filter), ascending
+	pageSize int       // user-selected records per page; 0 sizes pages to fit the viewport
+	offsets  []int     // line offset within the viewport of each record on the current page
+	anchor   int       // record under the cursor; kept in view when page boundaries shift
+	selected selection // shared with DS
+	notice   string    // transient message displayed in place of the help
+
+	highlight *regexp.Regexp // pattern to highlight in displayed records; nil for none
+
+	gotoMode gotoMode
+	gotoTI   textinput.Model
+	gotoErr  string
+
 	ready bool
 }
 
 func initResultsTab(data []string) resultsTab {
 	// set up backend paginator
-	paginator.DefaultKeyMap = paginator.KeyMap{ // do not use pgup/pgdn
-		PrevPage: key.NewBinding(key.WithKeys("left", "h")),
-		NextPage: key.NewBinding(key.WithKeys("right", "l")),
-	}
+	paginator.DefaultKeyMap = pageKeys // do not use pgup/pgdn
 	p := paginator.New()
 	p.Type = paginator.Dots
-	p.PerPage = 25
+	p.PerPage = defaultPageSize
 	p.ActiveDot = lipgloss.NewStyle().Foreground(stylesheet.FocusedColor).Render("•")
 	p.InactiveDot = lipgloss.NewStyle().Foreground(stylesheet.UnfocusedColor).Render("•")
 	p.SetTotalPages(len(data))
@@ -41,10 +70,15 @@ func initResultsTab(data []string) resultsTab {
 	// set up viewport
 	vp := NewViewport()
 
+	ti := stylesheet.NewTI("", false)
+	ti.Width = 8
+
 	r := resultsTab{
-		vp:    vp,
-		pager: p,
-		data:  data,
+		vp:     vp,
+		pager:  p,
+		data:   data,
+		shown:  allIndices(len(data)),
+		gotoTI: ti,
 	}
 
 	return r
@@ -56,23 +90,59 @@ func updateResults(s *DataScope, msg tea.Msg) tea.Cmd {
 		cmds []tea.Cmd
 	)
 
+	if s.results.gotoMode != gotoNone {
+		return s.results.updateGoto(msg)
+	}
+
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, keys.pageSizeUp):
+			s.results.setPageSize(s.results.pager.PerPage + pageSizeStep)
+			return nil
+		case key.Matches(msg, keys.pageSizeDown):
+			s.results.setPageSize(max(1, s.results.pager.PerPage-pageSizeStep))
+			return nil
+		case key.Matches(msg, keys.pageSizeAuto):
+			s.results.setPageSize(0)
+			return nil
+		case key.Matches(msg, keys.gotoPage):
+			return s.results.openGoto(gotoPage)
+		case key.Matches(msg, keys.gotoRecord):
+			return s.results.openGoto(gotoRecord)
+		}
+	}
+
 	// handle pager modifications first
-	prevPage := s.results.pager.Page
+	prevPage, prevY := s.results.pager.Page, s.results.vp.YOffset
 	s.results.pager, cmd = s.results.pager.Update(msg)
 	cmds = append(cmds, cmd)
 
-	s.setResultsDisplayed()               // pass the new content to the view
-	if prevPage != s.results.pager.Page { // if page changed, reset to top of view
+	if prevPage != s.results.pager.Page { // if page changed, display it from the top
+		s.results.setDisplayed()
 		s.results.vp.GotoTop()
 	}
 
 	// check for keybinds not directly supported by the viewport
-	if viewportAddtlKeys(msg, &s.results.vp) {
-		return cmds[0]
+	if !viewportAddtlKeys(msg, &s.results.vp) {
+		s.results.vp, cmd = s.results.vp.Update(msg)
+		cmds = append(cmds, cmd)
 	}
 
-	s.results.vp, cmd = s.results.vp.Update(msg)
-	cmds = append(cmds, cmd)
+	// if the user moved, anchor on the record they moved to
+	prevAnchor := s.results.anchor
+	if prevPage != s.results.pager.Page || prevY != s.results.vp.YOffset {
+		s.results.anchor = s.results.topRecord()
+	} else if msg, ok := msg.(tea.KeyMsg); ok {
+		// the viewport cannot scroll any further, so step the cursor through the remaining records
+		if key.Matches(msg, s.results.vp.KeyMap.Down) && s.results.vp.AtBottom() {
+			s.results.stepAnchor(1)
+		} else if key.Matches(msg, s.results.vp.KeyMap.Up) && s.results.vp.AtTop() {
+			s.results.stepAnchor(-1)
+		}
+	}
+	if prevAnchor != s.results.anchor { // redraw the cursor
+		s.results.refresh()
+	}
 	return tea.Sequence(cmds...)
 }
 
@@ -85,31 +155,198 @@ func viewResults(s *DataScope) string {
 }
 
 // Determines and sets the the content currently visible in the results viewport.
-func (s *DataScope) setResultsDisplayed() {
-	start, end := s.results.pager.GetSliceBounds(len(s.results.data))
-	data := s.results.data[start:end]
-
-	// apply alterating color scheme
-	var bldr strings.Builder
-	var trueIndex int = start // index of full results, between start and end
-	for _, d := range data {
-		bldr.WriteString(colorizer.Index(trueIndex+1) + ":")
-		if trueIndex%2 == 0 {
-			bldr.WriteString(evenEntryStyle.Render(d))
-		} else {
-			bldr.WriteString(oddEntryStyle.Render(d))
+func (rt *resultsTab) setDisplayed() {
+	start, end := rt.pager.GetSliceBounds(len(rt.shown))
+	rt.offsets = make([]int, 0, end-start)
+
+	// apply alterating color scheme, tracking where each record begins
+	var (
+		bldr strings.Builder
+		line int
+	)
+	for _, trueIndex := range rt.shown[start:end] { // index of full results
+		sty, label := evenEntryStyle(), colorizer.Index(trueIndex+1)
+		if trueIndex%2 != 0 {
+			sty = oddEntryStyle()
+		}
+		if trueIndex == rt.anchor {
+			label = cursorStyle.Render(strconv.Itoa(trueIndex + 1))
 		}
+		if rt.selected[trueIndex] {
+			sty, label = selectedEntryStyle(), selectedMarker()+label
+		}
+		rendered := wrap(rt.vp.Width, label+":"+highlight(rt.data[trueIndex], rt.highlight, sty))
+		rt.offsets = append(rt.offsets, line)
+		line += lipgloss.Height(rendered)
+		bldr.WriteString(rendered)
 		bldr.WriteRune('\n')
-		trueIndex += 1
 	}
-	s.results.vp.SetContent(wrap(s.results.vp.Width, bldr.String()))
+	rt.vp.SetContent(bldr.String())
 }
 
-var resultShortHelp = stylesheet.GreyedOutStyle.Render(
-	fmt.Sprintf("%v page • %v scroll • home: jump top • end: jump bottom\n"+
-		"tab: cycle • esc: quit",
-		stylesheet.LeftRight, stylesheet.UpDown),
-)
+// Returns the (0-indexed) record at the top of the viewport.
+func (rt *resultsTab) topRecord() int {
+	if len(rt.offsets) == 0 {
+		return rt.anchor
+	}
+	start, _ := rt.pager.GetSliceBounds(len(rt.shown))
+	i := sort.Search(len(rt.offsets), func(i int) bool { return rt.offsets[i] > rt.vp.YOffset })
+	return rt.shown[start+max(i-1, 0)]
+}
+
+// Displays the page containing the given (0-indexed) record, scrolled to the record, and anchors
+// on it.
+// If the record is hidden by the filter, the nearest following record is displayed instead.
+func (rt *resultsTab) jumpToRecord(idx int) {
+	if idx < 0 || idx >= len(rt.data) {
+		return
+	}
+	rt.anchor = idx
+	pos := min(sort.SearchInts(rt.shown, idx), len(rt.shown)-1)
+	if pos < 0 { // nothing to display
+		rt.pager.Page = 0
+		rt.setDisplayed()
+		return
+	}
+	rt.pager.Page = pos / rt.pager.PerPage
+	rt.setDisplayed()
+	start, _ := rt.pager.GetSliceBounds(len(rt.shown))
+	rt.vp.SetYOffset(rt.offsets[pos-start])
+}
+
+// Sets the records to display, keeping the current record in view if it is still shown.
+func (rt *resultsTab) setShown(shown []int) {
+	rt.shown = shown
+	rt.setTotalPages()
+	rt.jumpToRecord(rt.anchor)
+}
+
+// Sets the pattern to highlight in the displayed records.
+func (rt *resultsTab) setHighlight(rgx *regexp.Regexp) {
+	rt.highlight = rgx
+	rt.refresh()
+}
+
+// Redraws the current page without moving the viewport.
+func (rt *resultsTab) refresh() {
+	y := rt.vp.YOffset
+	rt.setDisplayed()
+	rt.vp.SetYOffset(y)
+}
+
+// Moves the anchor by the given number of records within the current page.
+func (rt *resultsTab) stepAnchor(delta int) {
+	start, end := rt.pager.GetSliceBounds(len(rt.shown))
+	pos := sort.SearchInts(rt.shown[start:end], rt.anchor) + start + delta
+	if pos >= start && pos < end {
+		rt.anchor = rt.shown[pos]
+	}
+}
+
+// Sets the number of records per page. 0 sizes pages to fit the viewport.
+func (rt *resultsTab) setPageSize(size int) {
+	rt.pageSize = size
+	rt.applyPageSize()
+}
+
+// Recalculates the records per page, keeping the current record in view if the page boundaries
+// shifted.
+func (rt *resultsTab) applyPageSize() {
+	perPage := rt.pageSize
+	if perPage <= 0 {
+		perPage = max(1, rt.vp.Height)
+	}
+	if perPage == rt.pager.PerPage {
+		rt.setDisplayed()
+		return
+	}
+	rt.pager.PerPage = perPage
+	rt.setTotalPages()
+	rt.jumpToRecord(rt.anchor)
+}
+
+// Updates the page count, falling back to a numeric paginator if the dots would not fit.
+func (rt *resultsTab) setTotalPages() {
+	if len(rt.shown) == 0 { // SetTotalPages ignores empty sets
+		rt.pager.TotalPages, rt.pager.Page = 1, 0
+	} else {
+		rt.pager.SetTotalPages(len(rt.shown))
+	}
+	if rt.pager.TotalPages > rt.vp.Width {
+		rt.pager.Type = paginator.Arabic
+	} else {
+		rt.pager.Type = paginator.Dots
+	}
+}
+
+//#region go-to prompt
+
+func (rt *resultsTab) openGoto(mode gotoMode) tea.Cmd {
+	rt.gotoMode = mode
+	rt.gotoErr = ""
+	rt.gotoTI.Reset()
+	return rt.gotoTI.Focus()
+}
+
+func (rt *resultsTab) closeGoto() {
+	rt.gotoMode = gotoNone
+	rt.gotoTI.Blur()
+}
+
+// Handles input while a go-to prompt is active.
+func (rt *resultsTab) updateGoto(msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, keys.cancel):
+			rt.closeGoto()
+			return nil
+		case msg.Type == tea.KeyEnter:
+			upper := len(rt.data)
+			if rt.gotoMode == gotoPage {
+				upper = rt.pager.TotalPages
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(rt.gotoTI.Value()))
+			if err != nil || n < 1 || n > upper {
+				rt.gotoErr = fmt.Sprintf("must be a number from 1 to %d", upper)
+				return nil
+			}
+			if rt.gotoMode == gotoPage {
+				rt.pager.Page = n - 1
+				rt.setDisplayed()
+				rt.vp.GotoTop()
+				rt.anchor = rt.topRecord()
+			} else if i := sort.SearchInts(rt.shown, n-1); i == len(rt.shown) || rt.shown[i] != n-1 {
+				rt.gotoErr = fmt.Sprintf("record %d is hidden by the filter", n)
+				return nil
+			} else {
+				rt.jumpToRecord(n - 1)
+			}
+			rt.closeGoto()
+			return nil
+		}
+		rt.gotoErr = ""
+	}
+	var cmd tea.Cmd
+	rt.gotoTI, cmd = rt.gotoTI.Update(msg)
+	return cmd
+}
+
+//#endregion
+
+func resultShortHelp() string {
+	return stylesheet.GreyedOutStyle.Render(
+		fmt.Sprintf("%v page • %v scroll • home: jump top • end: jump bottom\n"+
+			"%v: select • %v: page size • %v: go to page • %v: go to record\n"+
+			"%v: copy record/as JSON • %v: copy selection\n"+
+			"%v: details • %v: search • %v: filter • %v: pipe • %v: cycle • %v: quit",
+			hint(pageKeys.PrevPage, pageKeys.NextPage), hint(viewportKeys.Up, viewportKeys.Down),
+			hint(keys.toggleSelect),
+			hint(keys.pageSizeUp, keys.pageSizeDown, keys.pageSizeAuto), hint(keys.gotoPage),
+			hint(keys.gotoRecord), hint(keys.copyRecord, keys.copyJSON), hint(keys.copySelection),
+			hint(keys.openDetail), hint(keys.search), hint(keys.filter), hint(keys.pipe),
+			hint(keys.cycleTabs), hint(killer.ChildKill)),
+	)
+}
 
 // generates a renderFooter with the box+line and help keys
 func (rt *resultsTab) renderFooter(width int) string {
@@ -120,17 +357,33 @@ func (rt *resultsTab) renderFooter(width int) string {
 		Render(strconv.Itoa(rt.pager.Page+1)) + " "
 	spl := scrollPercentLine(width-lipgloss.Width(pageNumber), rt.vp.ScrollPercent())
 
+	// the go-to prompt, if active, replaces the paginator
+	nav := rt.pager.View()
+	if rt.gotoMode != gotoNone {
+		var prompt, upper = "go to record", len(rt.data)
+		if rt.gotoMode == gotoPage {
+			prompt, upper = "go to page", rt.pager.TotalPages
+		}
+		nav = fmt.Sprintf("%s (1-%d): %s", prompt, upper, rt.gotoTI.View())
+		if rt.gotoErr != "" {
+			nav += " " + stylesheet.ErrStyle.Render(rt.gotoErr)
+		}
+	}
+
 	return lipgloss.JoinVertical(lipgloss.Center,
 		pageNumber+spl,
-		alignerSty.Render(rt.pager.View()),
-		alignerSty.Render(resultShortHelp),
+		alignerSty.Render(nav),
+		helpOrNotice(alignerSty.Render(resultShortHelp()), rt.notice, rt.vp.Width),
 	)
 }
 
 // recalculate the dimensions of the results tab, factoring in results-specific margins.
 // The clipped height is the height available to the results tab (height - tabs height).
+// Pages are resized to fit, unless the user selected a fixed page size.
 func (rt *resultsTab) recalculateSize(rawWidth, clippedHeight int) {
-	rt.vp.Height = clippedHeight - lipgloss.Height(rt.renderFooter(rawWidth))
 	rt.vp.Width = rawWidth
+	rt.setTotalPages()
+	rt.vp.Height = clippedHeight - lipgloss.Height(rt.renderFooter(rawWidth))
+	rt.applyPageSize()
 	rt.ready = true
 }
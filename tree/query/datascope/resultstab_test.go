@@ -0,0 +1,38 @@
+package datascope
+
+import (
+	"strconv"
+	"testing"
+)
+
+func Test_resultsTab_applyPageSize(t *testing.T) {
+	data := make([]string, 100)
+	for i := range data {
+		data[i] = strconv.Itoa(i)
+	}
+	rt := initResultsTab(data)
+	rt.vp.Width, rt.vp.Height = 80, 10
+
+	// auto-sizing fits one single-line record per line
+	rt.setPageSize(0)
+	if rt.pager.PerPage != 10 || rt.pager.TotalPages != 10 {
+		t.Fatalf("auto page size: got %d per page over %d pages, want 10 over 10",
+			rt.pager.PerPage, rt.pager.TotalPages)
+	}
+
+	// anchor on record 57 and ensure its page is displayed as the boundaries shift
+	rt.jumpToRecord(57)
+	if rt.anchor != 57 || rt.pager.Page != 5 {
+		t.Fatalf("jumping to 57 anchored on %d (page %d)", rt.anchor, rt.pager.Page)
+	}
+	for _, size := range []int{25, 7, 3} {
+		rt.setPageSize(size)
+		if want := 57 / size; rt.pager.Page != want {
+			t.Errorf("page size %d: on page %d, want %d", size, rt.pager.Page, want)
+		}
+		start, end := rt.pager.GetSliceBounds(len(data))
+		if start > 57 || end <= 57 {
+			t.Errorf("page size %d: page spans [%d, %d), excluding 57", size, start, end)
+		}
+	}
+}
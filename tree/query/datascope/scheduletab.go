@@ -11,11 +11,13 @@ import (
 	"gwcli/connection"
 	"gwcli/stylesheet"
 	"gwcli/stylesheet/colorizer"
+	"gwcli/utilities/keymap"
 	"gwcli/utilities/uniques"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -65,6 +67,10 @@ func initScheduleTab(cronfreq, name, desc string) scheduleTab {
 func updateSchedule(s *DataScope, msg tea.Msg) tea.Cmd {
 	if msg, ok := msg.(tea.KeyMsg); ok {
 		s.schedule.inputErrorString = ""
+		if key.Matches(msg, keys.submit) {
+			s.sch()
+			return nil
+		}
 		switch msg.Type {
 		case tea.KeyUp:
 			s.schedule.selected -= 1
@@ -80,11 +86,6 @@ func updateSchedule(s *DataScope, msg tea.Msg) tea.Cmd {
 			}
 			s.schedule.focusSelected()
 			return textinput.Blink
-		case tea.KeyEnter:
-			if msg.Alt { // only accept alt+enter
-				s.sch()
-				return nil
-			}
 		}
 	}
 
@@ -151,7 +152,7 @@ func viewSchedule(s *DataScope) string {
 			tabDesc,
 			composed,
 			"",
-			colorizer.SubmitString("alt+enter", s.schedule.inputErrorString, s.schedule.resultString, s.usableWidth()),
+			colorizer.SubmitString(keymap.Keys(keys.submit), s.schedule.inputErrorString, s.schedule.resultString, s.usableWidth()),
 		),
 	)
 }
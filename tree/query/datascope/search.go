@@ -0,0 +1,392 @@
+package datascope
+
+/**
+ * Search and filtering for the results and table tabs.
+ * Searching highlights matches and jumps between matching records; filtering hides non-matching
+ * records across all pages.
+ * Patterns are plain substrings unless regex mode is toggled in the prompt. Either way, an
+ * all-lowercase pattern matches case-insensitively.
+ * The finder is owned by DS, which draws its bar beneath the results/table tab while it is in use.
+ */
+
+import (
+	"fmt"
+	"gwcli/stylesheet"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var matchStyle = lipgloss.NewStyle().Reverse(true)
+
+// the prompt currently accepting input, if any
+type finderPrompt uint
+
+const (
+	findNone finderPrompt = iota
+	findSearch
+	findFilter
+)
+
+type finder struct {
+	ti     textinput.Model
+	prompt finderPrompt
+	regex  bool   // interpret the prompt's pattern as a regular expression
+	err    string // issue with the most recently submitted pattern
+
+	search    *regexp.Regexp // nil if not searching
+	searchStr string         // pattern as entered by the user
+	matches   []int          // displayed records that match the search, in display order
+	cur       int            // index in matches of the focused match
+
+	filter    *regexp.Regexp // nil if not filtering
+	filterStr string         // pattern as entered by the user
+
+	window *timeWindow // time range records are narrowed to (see the histogram tab); nil for all
+	cell   *fieldValue // value table rows are narrowed to (see the fields tab); nil for all
+}
+
+func initFinder() finder {
+	ti := stylesheet.NewTI("", false)
+	ti.Width = 40
+	return finder{ti: ti}
+}
+
+// Returns whether the finder's bar should be drawn.
+func (f *finder) visible() bool {
+	return f.prompt != findNone || f.search != nil || f.filter != nil || f.window != nil ||
+		f.cell != nil
+}
+
+// Compiles the user's pattern.
+// Substrings are quoted; all-lowercase patterns are made case-insensitive.
+func compilePattern(pattern string, regex bool) (*regexp.Regexp, error) {
+	if !regex {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if pattern == strings.ToLower(pattern) {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.Compile(pattern)
+}
+
+// Returns the given string, rendered in the base style with all matches of rgx highlighted.
+func highlight(s string, rgx *regexp.Regexp, base lipgloss.Style) string {
+	if rgx == nil {
+		return base.Render(s)
+	}
+	var (
+		sb   strings.Builder
+		prev int
+	)
+	for _, loc := range rgx.FindAllStringIndex(s, -1) {
+		if loc[0] == loc[1] { // nothing to highlight
+			continue
+		}
+		sb.WriteString(renderLines(base, s[prev:loc[0]]))
+		sb.WriteString(renderLines(matchStyle, s[loc[0]:loc[1]]))
+		prev = loc[1]
+	}
+	sb.WriteString(renderLines(base, s[prev:]))
+	return sb.String()
+}
+
+// Renders each line of s independently, so lipgloss does not pad partial lines to a block.
+func renderLines(sty lipgloss.Style, s string) string {
+	if s == "" {
+		return ""
+	}
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = sty.Render(l)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Returns the position of idx within shown or, if it is not shown, the position of the nearest
+// following record (by index; len(shown) if there is none).
+// shown need not be in ascending order.
+func shownPosition(shown []int, idx int) (pos int, found bool) {
+	pos = len(shown)
+	for i, v := range shown {
+		if v == idx {
+			return i, true
+		}
+		if v > idx && (pos == len(shown) || v < shown[pos]) {
+			pos = i
+		}
+	}
+	return pos, false
+}
+
+// Returns a slice of [0, n).
+func allIndices(n int) []int {
+	idx := make([]int, n)
+	for i := range idx {
+		idx[i] = i
+	}
+	return idx
+}
+
+//#region DS integration
+
+// Returns whether the given record (or any cell of the given row) matches the pattern.
+func (s *DataScope) recordMatches(rgx *regexp.Regexp, idx int) bool {
+	if !s.tableMode {
+		return rgx.Match(s.records.Entries[idx].Data)
+	}
+	for _, c := range s.records.Rows[idx].Row {
+		if rgx.MatchString(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the records currently displayed by the results/table tab (those passing the filter).
+func (s *DataScope) shownRecords() []int {
+	if s.tableMode {
+		return s.table.shown
+	}
+	return s.results.shown
+}
+
+// Returns whether any record is displayed.
+// While the filter hides every record, the cursor rests on a hidden record that must not be acted on.
+func (s *DataScope) anyShown() bool {
+	return len(s.shownRecords()) > 0
+}
+
+// Returns the record under the cursor.
+func (s *DataScope) currentRecord() int {
+	if s.tableMode {
+		return s.table.cursor
+	}
+	return s.results.anchor
+}
+
+func (s *DataScope) jumpToRecord(idx int) {
+	if s.tableMode {
+		s.table.jumpToRow(idx)
+	} else {
+		s.results.jumpToRecord(idx)
+	}
+}
+
+// Opens the search or filter prompt, pre-populated with the active pattern.
+func (s *DataScope) openFinder(p finderPrompt) tea.Cmd {
+	s.find.prompt = p
+	s.find.err = ""
+	if p == findSearch {
+		s.find.ti.SetValue(s.find.searchStr)
+	} else {
+		s.find.ti.SetValue(s.find.filterStr)
+	}
+	s.find.ti.CursorEnd()
+	s.recalculateWindowMargins(s.rawWidth, s.rawHeight)
+	return s.find.ti.Focus()
+}
+
+func (s *DataScope) closeFinder() {
+	s.find.prompt = findNone
+	s.find.ti.Blur()
+	s.recalculateWindowMargins(s.rawWidth, s.rawHeight)
+}
+
+// Handles input while the search or filter prompt is open.
+func updateFinder(s *DataScope, msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, keys.cancel):
+		s.closeFinder()
+		return nil
+	case key.Matches(msg, keys.toggleRegex):
+		s.find.regex = !s.find.regex
+		return nil
+	case msg.Type == tea.KeyEnter:
+		var err error
+		if s.find.prompt == findSearch {
+			err = s.setSearch(s.find.ti.Value())
+		} else {
+			err = s.setFilter(s.find.ti.Value())
+		}
+		if err != nil {
+			s.find.err = err.Error()
+			return nil
+		}
+		s.closeFinder()
+		return nil
+	}
+	s.find.err = ""
+	var cmd tea.Cmd
+	s.find.ti, cmd = s.find.ti.Update(msg)
+	return cmd
+}
+
+// Sets (or, if the pattern is empty, clears) the search and jumps to the first match at or after
+// the current record.
+func (s *DataScope) setSearch(pattern string) error {
+	if pattern == "" {
+		s.find.search, s.find.searchStr = nil, ""
+	} else {
+		rgx, err := compilePattern(pattern, s.find.regex)
+		if err != nil {
+			return err
+		}
+		s.find.search, s.find.searchStr = rgx, pattern
+	}
+	s.refreshMatches()
+	s.refreshHighlight()
+	// focus on the first match at or after the current record, as displayed
+	var (
+		shown     = s.shownRecords()
+		curPos, _ = shownPosition(shown, s.currentRecord())
+		positions = make(map[int]int, len(shown))
+	)
+	for i, r := range shown {
+		positions[r] = i
+	}
+	for i, m := range s.find.matches {
+		if positions[m] >= curPos {
+			s.find.cur = i
+			break
+		}
+	}
+	if len(s.find.matches) > 0 {
+		s.jumpToRecord(s.find.matches[s.find.cur])
+	}
+	return nil
+}
+
+// Sets (or, if the pattern is empty, clears) the filter, hiding all non-matching records.
+func (s *DataScope) setFilter(pattern string) error {
+	if pattern == "" {
+		s.find.filter, s.find.filterStr = nil, ""
+	} else {
+		rgx, err := compilePattern(pattern, s.find.regex)
+		if err != nil {
+			return err
+		}
+		s.find.filter, s.find.filterStr = rgx, pattern
+	}
+	s.applyFilter()
+	return nil
+}
+
+// Displays only the records that match the filter, fall within the time window, and hold the
+// cell value.
+func (s *DataScope) applyFilter() {
+	shown := allIndices(s.records.Len())
+	if s.find.filter != nil || s.find.window != nil || s.find.cell != nil {
+		shown = shown[:0]
+		for i := 0; i < s.records.Len(); i++ {
+			if (s.find.filter == nil || s.recordMatches(s.find.filter, i)) &&
+				(s.find.window == nil || s.find.window.contains(s.recordTime(i))) &&
+				(s.find.cell == nil || s.cellMatches(s.find.cell, i)) {
+				shown = append(shown, i)
+			}
+		}
+	}
+	if s.tableMode {
+		s.table.setShown(shown)
+	} else {
+		s.results.setShown(shown)
+	}
+	s.refreshMatches()
+	s.refreshHighlight()
+}
+
+// Recalculates which displayed records match the search.
+func (s *DataScope) refreshMatches() {
+	s.find.matches, s.find.cur = nil, 0
+	if s.find.search == nil {
+		return
+	}
+	for _, i := range s.shownRecords() {
+		if s.recordMatches(s.find.search, i) {
+			s.find.matches = append(s.find.matches, i)
+		}
+	}
+}
+
+// Highlights the search pattern or, if there is no search, the filter pattern.
+func (s *DataScope) refreshHighlight() {
+	rgx := s.find.search
+	if rgx == nil {
+		rgx = s.find.filter
+	}
+	if s.tableMode {
+		s.table.setHighlight(rgx)
+	} else {
+		s.results.setHighlight(rgx)
+	}
+}
+
+// Focuses the next (or previous) match, wrapping around.
+func (s *DataScope) nextMatch(forward bool) {
+	count := len(s.find.matches)
+	if count == 0 {
+		return
+	}
+	if forward {
+		s.find.cur = (s.find.cur + 1) % count
+	} else {
+		s.find.cur = (s.find.cur - 1 + count) % count
+	}
+	s.jumpToRecord(s.find.matches[s.find.cur])
+}
+
+// Returns the single-line bar describing the prompt or the active search and filter.
+func (s *DataScope) viewFinder() string {
+	var (
+		help = stylesheet.GreyedOutStyle
+		line string
+	)
+	if s.find.prompt != findNone {
+		prefix, mode := "/", "substring"
+		if s.find.prompt == findFilter {
+			prefix = "&"
+		}
+		if s.find.regex {
+			mode = "regex"
+		}
+		line = stylesheet.PromptStyle.Render(prefix) + s.find.ti.View() + " " +
+			help.Render(fmt.Sprintf("(%v) %v: toggle regex • enter: apply (empty clears) • %v: cancel",
+				mode, hint(keys.toggleRegex), hint(keys.cancel)))
+		if s.find.err != "" {
+			line += " " + stylesheet.ErrStyle.Render(s.find.err)
+		}
+	} else {
+		var segments []string
+		if s.find.search != nil {
+			if len(s.find.matches) == 0 {
+				segments = append(segments, fmt.Sprintf("/%v: no matches", s.find.searchStr))
+			} else {
+				segments = append(segments, fmt.Sprintf("/%v: match %d of %d (n/N)",
+					s.find.searchStr, s.find.cur+1, len(s.find.matches)))
+			}
+		}
+		if s.find.filter != nil || s.find.window != nil || s.find.cell != nil {
+			var narrowing []string
+			if s.find.filter != nil {
+				narrowing = append(narrowing, "&"+s.find.filterStr)
+			}
+			if s.find.window != nil {
+				narrowing = append(narrowing, "@"+s.find.window.String())
+			}
+			if c := s.find.cell; c != nil {
+				narrowing = append(narrowing, s.records.Columns[c.col]+"="+c.value)
+			}
+			segments = append(segments, fmt.Sprintf("%v: %d of %d records",
+				strings.Join(narrowing, " "), len(s.shownRecords()), s.records.Len()))
+		}
+		line = lipgloss.NewStyle().Foreground(stylesheet.AccentColor1).
+			Render(strings.Join(segments, " • "))
+	}
+	return lipgloss.NewStyle().MaxWidth(s.rawWidth).Render(line)
+}
+
+//#endregion
@@ -0,0 +1,133 @@
+package datascope
+
+import (
+	"gwcli/clilog"
+	"gwcli/tree/query/transcode"
+	"reflect"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gravwell/gravwell/v3/client/types"
+)
+
+func Test_compilePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		regex   bool
+		matches []string
+		misses  []string
+		wantErr bool
+	}{
+		{"a.c", false, []string{"xa.cx", "A.C"}, []string{"abc"}, false},
+		{"a.c", true, []string{"abc", "A.C"}, []string{"ac"}, false},
+		{"Err", false, []string{"Error"}, []string{"error"}, false},
+		{"^\\d+$", true, []string{"123"}, []string{"12a"}, false},
+		{"(", true, nil, nil, true},
+	}
+	for _, tt := range tests {
+		rgx, err := compilePattern(tt.pattern, tt.regex)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("compilePattern(%q, %v) error = %v, wantErr %v", tt.pattern, tt.regex, err, tt.wantErr)
+			continue
+		}
+		for _, m := range tt.matches {
+			if !rgx.MatchString(m) {
+				t.Errorf("pattern %q (regex: %v) did not match %q", tt.pattern, tt.regex, m)
+			}
+		}
+		for _, m := range tt.misses {
+			if rgx.MatchString(m) {
+				t.Errorf("pattern %q (regex: %v) unexpectedly matched %q", tt.pattern, tt.regex, m)
+			}
+		}
+	}
+}
+
+func Test_highlight(t *testing.T) {
+	rgx, _ := compilePattern("o", false)
+	// an unstyled base with highlighting disabled should pass the string through
+	if got := highlight("foo", nil, lipgloss.NewStyle()); got != "foo" {
+		t.Errorf("highlight without a pattern = %q, want %q", got, "foo")
+	}
+	want := "f" + matchStyle.Render("o") + matchStyle.Render("o") + "\nbar"
+	if got := highlight("foo\nbar", rgx, lipgloss.NewStyle()); got != want {
+		t.Errorf("highlight() = %q, want %q", got, want)
+	}
+}
+
+func Test_rowOffsets(t *testing.T) {
+	if err := clilog.Init(t.TempDir()+"/log.txt", "DEBUG"); err != nil {
+		t.Fatal(err)
+	}
+	layoutsPath = t.TempDir() + "/layouts.json" // do not pick up the user's saved layouts
+	tt := initTableTab([]string{"a", "b"}, []types.TableRow{
+		{Row: []string{"x", "y"}},
+		{Row: []string{"a long value that wraps around many times over", "z"}},
+		{Row: []string{"q", "r"}},
+	})
+	tt.recalculateSize(40, 8) // leave room for only a few lines
+	// 3 lines of header, then the second row wraps across multiple lines
+	offsets := tt.rowOffsets
+	if len(offsets) != 3 || offsets[0] != 3 || offsets[1] != 4 || offsets[2] <= 5 {
+		t.Fatalf("rowOffsets = %v, want [3 4 >5]", offsets)
+	}
+	tt.jumpToRow(2)
+	if tt.topRow() != 2 {
+		t.Errorf("jumping to row 2 left %d at the top", tt.topRow())
+	}
+}
+
+func TestDataScope_filterAndSearch(t *testing.T) {
+	data := []string{"alpha", "beta", "gamma", "delta", "epsilon", "alphabet"}
+	entries := make([]types.SearchEntry, len(data))
+	for i, d := range data {
+		entries[i].Data = []byte(d)
+	}
+	s := DataScope{records: transcode.Results{Entries: entries}, results: initResultsTab(data),
+		find: initFinder()}
+	s.results.vp.Width, s.results.vp.Height = 80, 10
+	s.results.setPageSize(2)
+
+	// search across all pages
+	if err := s.setSearch("ALPHA"); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.find.matches) != 0 {
+		t.Fatalf("case-sensitive search matched %v", s.find.matches)
+	}
+	if err := s.setSearch("alpha"); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s.find.matches, []int{0, 5}) {
+		t.Fatalf("search matches = %v, want [0 5]", s.find.matches)
+	}
+	s.nextMatch(true)
+	if s.results.anchor != 5 || s.results.pager.Page != 2 {
+		t.Errorf("next match anchored on %d (page %d), want 5 (page 2)",
+			s.results.anchor, s.results.pager.Page)
+	}
+
+	// filtering hides records and restricts the search's matches
+	s.find.regex = true
+	if err := s.setFilter("^(alpha|beta|gamma)$"); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s.results.shown, []int{0, 1, 2}) || s.results.pager.TotalPages != 2 {
+		t.Fatalf("filter shows %v over %d pages, want [0 1 2] over 2",
+			s.results.shown, s.results.pager.TotalPages)
+	}
+	if !reflect.DeepEqual(s.find.matches, []int{0}) {
+		t.Errorf("filtered search matches = %v, want [0]", s.find.matches)
+	}
+	if got, want := string(s.pipeInput(pipeScopeAll)), "alpha\nbeta\ngamma\n"; got != want {
+		t.Errorf("filtered pipe input = %q, want %q", got, want)
+	}
+
+	// clearing the filter restores all records
+	if err := s.setFilter(""); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.results.shown) != len(data) {
+		t.Errorf("cleared filter shows %d records, want %d", len(s.results.shown), len(data))
+	}
+}
@@ -0,0 +1,203 @@
+package datascope
+
+/**
+ * Record selection, shared by the results/table tabs and the record-level actions (downloading,
+ * piping) that operate on it.
+ * Records are identified by their index in the full set of results, regardless of any filter.
+ *
+ * Records can also be selected by ranges of record numbers (as displayed, starting at 1):
+ * "1-20,45,60-", where an open-ended range runs to the final record.
+ */
+
+import (
+	"errors"
+	"fmt"
+	"gwcli/stylesheet"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	cursorStyle        = lipgloss.NewStyle().Reverse(true)
+	selectedEntryStyle = func() lipgloss.Style {
+		return lipgloss.NewStyle().Foreground(stylesheet.FocusedColor).Bold(true)
+	}
+	selectedMarker = func() string { return lipgloss.NewStyle().Foreground(stylesheet.FocusedColor).Render("✓") }
+)
+
+// selection is the set of selected records (0-indexed).
+type selection map[int]bool
+
+// Returns the selected records in ascending order.
+func (sel selection) indices() []int {
+	idx := make([]int, 0, len(sel))
+	for i, selected := range sel {
+		if selected {
+			idx = append(idx, i)
+		}
+	}
+	sort.Ints(idx)
+	return idx
+}
+
+// parseRanges parses a comma-separated list of record numbers and ranges of record numbers
+// (ex: "1-20,45,60-") into 0-indexed records, in the order given and without duplicates.
+// count is the total number of records available.
+func parseRanges(s string, count int) ([]int, error) {
+	var (
+		records []int
+		seen    = make(map[int]bool)
+	)
+	for _, segment := range strings.Split(s, ",") {
+		if segment = strings.TrimSpace(segment); segment == "" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(segment, "-")
+		lower, err := parseRecordNumber(lo, 1, count)
+		if err != nil {
+			return nil, err
+		}
+		upper := lower
+		if isRange {
+			if upper, err = parseRecordNumber(hi, count, count); err != nil {
+				return nil, err
+			}
+			if upper < lower {
+				return nil, fmt.Errorf("range '%v' ends before it begins", segment)
+			}
+		}
+		for r := lower; r <= upper; r++ {
+			if !seen[r] {
+				seen[r] = true
+				records = append(records, r-1) // user sees indices from 1, not 0
+			}
+		}
+	}
+	return records, nil
+}
+
+// helper subroutine for parseRanges.
+// Parses a single record number, returning def if the string is empty.
+func parseRecordNumber(s string, def, count int) (int, error) {
+	if s = strings.TrimSpace(s); s == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse record '%v'", s)
+	}
+	if n < 1 || n > count {
+		return 0, fmt.Errorf("record %v is outside the set of available records [1-%v]", n, count)
+	}
+	return n, nil
+}
+
+// formatRanges returns the given, ascending, 0-indexed records as a minimal list of record numbers
+// and ranges of record numbers, such that parseRanges(formatRanges(x)) == x.
+func formatRanges(records []int) string {
+	var segments []string
+	for i := 0; i < len(records); {
+		j := i
+		for j+1 < len(records) && records[j+1] == records[j]+1 {
+			j++
+		}
+		if i == j {
+			segments = append(segments, strconv.Itoa(records[i]+1))
+		} else {
+			segments = append(segments, fmt.Sprintf("%d-%d", records[i]+1, records[j]+1))
+		}
+		i = j + 1
+	}
+	return strings.Join(segments, ",")
+}
+
+// validates that the given string only contains characters legal in a range list.
+func validateRanges(s string) error {
+	for _, r := range s {
+		if r == ',' || r == '-' || r == ' ' || (r >= '0' && r <= '9') {
+			continue
+		}
+		return errors.New("must be record numbers or ranges (ex: 1-20,45,60-)")
+	}
+	return nil
+}
+
+//#region DS integration
+
+// Moves the cursor to the next (or previous) displayed record, if there is one.
+func (s *DataScope) moveCursor(forward bool) {
+	shown, cur := s.shownRecords(), s.currentRecord()
+	pos, found := shownPosition(shown, cur) // position of the cursor or the record after it
+	if forward {
+		if found {
+			pos++
+		}
+	} else {
+		pos--
+	}
+	if pos >= 0 && pos < len(shown) {
+		s.jumpToRecord(shown[pos])
+	}
+}
+
+// Toggles selection of the record under the cursor, then advances the cursor.
+func (s *DataScope) toggleSelected() {
+	if !s.anyShown() {
+		return
+	}
+	cur := s.currentRecord()
+	if s.selected[cur] {
+		delete(s.selected, cur)
+	} else {
+		s.selected[cur] = true
+	}
+	s.moveCursor(true)
+	s.selectionChanged()
+}
+
+// Selects the record under the cursor and the record the cursor moves to.
+func (s *DataScope) extendSelection(forward bool) {
+	if !s.anyShown() {
+		return
+	}
+	s.selected[s.currentRecord()] = true
+	s.moveCursor(forward)
+	s.selected[s.currentRecord()] = true
+	s.selectionChanged()
+}
+
+// Selects every displayed record or, if they are all already selected, clears the selection.
+func (s *DataScope) toggleSelectAll() {
+	shown := s.shownRecords()
+	all := true
+	for _, i := range shown {
+		if !s.selected[i] {
+			all = false
+			break
+		}
+	}
+	if all {
+		clear(s.selected)
+	} else {
+		for _, i := range shown {
+			s.selected[i] = true
+		}
+	}
+	s.selectionChanged()
+}
+
+// Redraws the selection and feeds it to the download tab.
+func (s *DataScope) selectionChanged() {
+	if s.tableMode {
+		s.table.refresh()
+	} else {
+		s.results.refresh()
+	}
+	s.download.recordsTI.SetValue(formatRanges(s.selected.indices()))
+	s.download.format.enabled = len(s.selected) == 0
+}
+
+//#endregion
@@ -0,0 +1,109 @@
+package datascope
+
+import (
+	"gwcli/tree/query/transcode"
+	"reflect"
+	"testing"
+
+	"github.com/gravwell/gravwell/v3/client/types"
+)
+
+func Test_parseRanges(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    []int
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"3", []int{2}, false},
+		{"1-3,5", []int{0, 1, 2, 4}, false},
+		{"8-", []int{7, 8, 9}, false},
+		{"-2", []int{0, 1}, false},
+		{" 5 , 2-3 ,2", []int{4, 1, 2}, false},
+		{"1-20,45,60-", nil, true},
+		{"0", nil, true},
+		{"3-1", nil, true},
+		{"a", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseRanges(tt.s, 10)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseRanges(%q) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseRanges(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func Test_formatRanges(t *testing.T) {
+	tests := []struct {
+		records []int
+		want    string
+	}{
+		{nil, ""},
+		{[]int{0}, "1"},
+		{[]int{0, 1, 2, 4, 6, 7}, "1-3,5,7-8"},
+	}
+	for _, tt := range tests {
+		got := formatRanges(tt.records)
+		if got != tt.want {
+			t.Errorf("formatRanges(%v) = %q, want %q", tt.records, got, tt.want)
+		}
+		if back, err := parseRanges(got, 10); err != nil ||
+			(len(tt.records) > 0 && !reflect.DeepEqual(back, tt.records)) {
+			t.Errorf("parseRanges(%q) = %v, %v; want %v", got, back, err, tt.records)
+		}
+	}
+}
+
+func TestDataScope_selection(t *testing.T) {
+	data := []string{"a", "b", "c", "d", "e"}
+	entries := make([]types.SearchEntry, len(data))
+	for i, d := range data {
+		entries[i].Data = []byte(d)
+	}
+	s := DataScope{records: transcode.Results{Entries: entries}, results: initResultsTab(data),
+		find: initFinder(), selected: selection{},
+		download: initDownloadTab("", false, transcode.Raw, transcode.Projection{})}
+	s.results.selected = s.selected
+	s.results.vp.Width, s.results.vp.Height = 80, 10
+
+	s.toggleSelected() // select a, move to b
+	s.extendSelection(true)
+	s.extendSelection(true) // b through d
+	s.toggleSelected()      // deselect d
+	if got, want := s.selected.indices(), []int{0, 1, 2}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("selection = %v, want %v", got, want)
+	}
+	if got := s.download.recordsTI.Value(); got != "1-3" {
+		t.Errorf("download records = %q, want %q", got, "1-3")
+	}
+	if got, want := string(s.pipeInput(pipeScopeSelection)), "a\nb\nc\n"; got != want {
+		t.Errorf("selection pipe input = %q, want %q", got, want)
+	}
+
+	s.toggleSelectAll()
+	if len(s.selected) != len(data) {
+		t.Errorf("select all selected %d records, want %d", len(s.selected), len(data))
+	}
+	s.toggleSelectAll()
+	if len(s.selected) != 0 || s.download.recordsTI.Value() != "" {
+		t.Errorf("clearing left %v selected (download records %q)",
+			s.selected.indices(), s.download.recordsTI.Value())
+	}
+
+	// records hidden by the filter cannot be acted on
+	if err := s.setFilter("nothing matches"); err != nil {
+		t.Fatal(err)
+	}
+	s.toggleSelected()
+	s.extendSelection(true)
+	if len(s.selected) != 0 {
+		t.Errorf("selected hidden records %v", s.selected.indices())
+	}
+	if s.copyRecord(); s.results.notice == "" {
+		t.Error("copied a hidden record")
+	}
+}
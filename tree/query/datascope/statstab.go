@@ -0,0 +1,98 @@
+package datascope
+
+/**
+ * The stats tab displays the backend's metrics about the search: the effective query, time range,
+ * pipeline throughput, per-module timings, and indexer state.
+ */
+
+import (
+	"fmt"
+	"gwcli/stylesheet"
+	"gwcli/tree/query/searchstats"
+	"gwcli/utilities/killer"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	grav "github.com/gravwell/gravwell/v3/client"
+)
+
+type statsTab struct {
+	vp      viewport.Model
+	content string // rendered stats (or the error that prevented fetching them)
+	fetched bool   // the stats have arrived (or failed to)
+	ready   bool
+}
+
+// statsMsg carries the metrics fetched for a search.
+type statsMsg struct {
+	sid   string // search the stats were fetched for
+	stats searchstats.Stats
+	err   error
+}
+
+// Initializes the stats tab, pending the arrival of the search's stats.
+func initStatsTab() statsTab {
+	return statsTab{vp: NewViewport(), content: "Fetching search stats..."}
+}
+
+// Returns a command that fetches the stats of the given search off of the Update loop, as doing so
+// requires several round trips to the backend.
+func fetchStats(search *grav.Search) tea.Cmd {
+	return func() tea.Msg {
+		st, err := searchstats.Fetch(search)
+		return statsMsg{sid: search.ID, stats: st, err: err}
+	}
+}
+
+// Displays the given stats or the error returned when fetching them.
+func (st *statsTab) setStats(stats searchstats.Stats, err error) {
+	st.fetched = true
+	if err != nil {
+		st.content = stylesheet.ErrStyle.Render("Failed to fetch search stats: " + err.Error())
+	} else {
+		st.content = stats.String()
+	}
+	if st.ready {
+		st.vp.SetContent(wrap(st.vp.Width, st.content))
+	}
+}
+
+func updateStats(s *DataScope, msg tea.Msg) tea.Cmd {
+	if viewportAddtlKeys(msg, &s.stats.vp) {
+		return nil
+	}
+	var cmd tea.Cmd
+	s.stats.vp, cmd = s.stats.vp.Update(msg)
+	return cmd
+}
+
+func viewStats(s *DataScope) string {
+	if !s.stats.ready {
+		return "\nInitializing..."
+	}
+	return fmt.Sprintf("%s\n%s", s.stats.vp.View(), s.stats.renderFooter(s.stats.vp.Width))
+}
+
+func statsShortHelp() string {
+	return stylesheet.GreyedOutStyle.Render(
+		fmt.Sprintf("%v scroll • home: jump top • end: jump bottom • %v: cycle • %v: quit",
+			hint(viewportKeys.Up, viewportKeys.Down), hint(keys.cycleTabs), hint(killer.ChildKill)),
+	)
+}
+
+func (st *statsTab) renderFooter(width int) string {
+	return lipgloss.JoinVertical(lipgloss.Center,
+		scrollPercentLine(width, st.vp.ScrollPercent()),
+		lipgloss.NewStyle().Width(width).AlignHorizontal(lipgloss.Center).Render(statsShortHelp()),
+	)
+}
+
+// recalculate the dimensions of the stats tab, factoring in its footer.
+// The clipped height is the height available to the stats tab (height - tabs height).
+func (st *statsTab) recalculateSize(rawWidth, clippedHeight int) {
+	st.vp.Height = clippedHeight - lipgloss.Height(st.renderFooter(rawWidth))
+	st.vp.Width = rawWidth
+	st.vp.SetContent(wrap(rawWidth, st.content))
+	st.ready = true
+}
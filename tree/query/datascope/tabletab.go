@@ -6,38 +6,58 @@ package datascope
  */
 
 import (
+	"fmt"
 	"gwcli/clilog"
 	"gwcli/stylesheet"
 	"gwcli/stylesheet/colorizer"
+	"gwcli/utilities/killer"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/evertras/bubble-table/table"
+	"github.com/gravwell/gravwell/v3/client/types"
 )
 
 const flexFactor = 5 // target ratio: other column width : index column width (1)
 
 var sep = ","
 
+// row data key flagging a row as selected; not a column
+const selectedKey = "selected"
+
 type tableTab struct {
-	vp      viewport.Model
-	columns []table.Column // once installed, tbl.columns is not externally accessible
-	rows    []string       // save off data minus header for easy access by dl tab
-	tbl     table.Model
-	ready   bool
+	vp         viewport.Model
+	columns    []table.Column // index column, then each data column; see displayColumns()
+	signature  string         // identifies the set of columns, for saving the layout
+	layout     columnLayout   // arrangement of the data columns
+	sortCol    int            // data column rows are sorted by; -1 for their original order
+	sortDesc   bool
+	width      int              // width available to the table
+	data       []types.TableRow // complete set of rows
+	rows       []string         // save off data minus header for easy access by dl tab
+	shown      []int            // rows to display (those that pass the filter), in display order
+	highlight  *regexp.Regexp   // pattern to highlight in displayed cells; nil for none
+	rowOffsets []int            // line offset within the rendered table of each displayed row
+	cursor     int              // row under the cursor
+	column     int              // (0-indexed) data column of the cell under the cursor
+	notice     string           // transient message displayed in place of the help
+	selected   selection        // shared with DS
+	tbl        table.Model
+	ready      bool
 }
 
 // Initializes the table tab, setting up the viewport and tabulating the data.
-//
-// ! Assumes data[0] is the columns headers
-func initTableTab(data []string) tableTab {
+func initTableTab(strcols []string, tblRows []types.TableRow) tableTab {
 	vp := NewViewport() // spawn the vp wrapper of the table
 
 	// build columns list, with the index column prefixed
-	strcols := strings.Split(data[0], sep)
 	colCount := len(strcols) + 1
 	var columns []table.Column = make([]table.Column, colCount)
 	// set index column
@@ -47,44 +67,170 @@ func initTableTab(data []string) tableTab {
 		columns[i+1] = table.NewFlexColumn(strconv.Itoa(i+1), c, flexFactor)
 		clilog.Writer.Debugf("Added column %v (key: %v)", columns[i].Title(), columns[i].Key())
 	}
-	// build rows list
-	var rows []table.Row = make([]table.Row, len(data)-1)
-	for i, r := range data[1:] {
-		cells := strings.Split(r, sep)
-		// map each row cell to its column
-		rd := table.RowData{}
-		// prepend the index column
-		rd["index"] = colorizer.Index(i + 1)
-		for j, c := range cells {
-			rd[strconv.Itoa(j+1)] = c
-		}
-		// add the completed row to the list of rows
-		rows[i] = table.NewRow(rd)
+	// csv-ish form for the download tab
+	var joined []string = make([]string, len(tblRows))
+	for i, r := range tblRows {
+		joined[i] = strings.Join(r.Row, sep)
 	}
 
-	tbl := table.New(columns).
-		WithRows(rows).
+	tt := tableTab{
+		vp:        vp,
+		data:      tblRows,
+		rows:      joined,
+		shown:     allIndices(len(tblRows)),
+		columns:   columns,
+		signature: layoutSignature(strcols),
+		sortCol:   -1,
+	}
+	tt.loadLayout()
+	if vis := tt.layout.visible(); len(vis) > 0 {
+		tt.column = vis[0]
+	}
+
+	tt.tbl = table.New(tt.displayColumns()).
+		WithHorizontalFreezeColumnCount(1 + tt.layout.Frozen).
+		WithRows(tt.buildRows()).
 		Focused(true).
 		WithMultiline(true).
 		WithStaticFooter("END OF DATA").
 		WithRowStyleFunc(func(rsfi table.RowStyleFuncInput) lipgloss.Style {
+			if rsfi.Row.Data[selectedKey] == true {
+				return selectedEntryStyle()
+			}
 			if rsfi.Index%2 == 0 {
-				return evenEntryStyle
+				return evenEntryStyle()
 			}
-			return oddEntryStyle
+			return oddEntryStyle()
 		}).
 		HeaderStyle(stylesheet.Tbl.HeaderCells)
 		// NOTE: As of evertras-table v0.16.1,
 		// the borders cannot be styled (only their runes changed.)
 
 	// display the table within the viewport
-	vp.SetContent(tbl.View())
+	tt.render()
 
-	return tableTab{
-		vp:      vp,
-		rows:    data[1:],
-		columns: columns,
-		tbl:     tbl,
+	return tt
+}
+
+// Builds the table rows to display from the shown data, highlighting matches.
+func (tt *tableTab) buildRows() []table.Row {
+	var rows []table.Row = make([]table.Row, len(tt.shown))
+	for pos, i := range tt.shown {
+		// map each row cell to its column
+		rd := table.RowData{}
+		// prepend the index column
+		rd["index"] = colorizer.Index(i + 1)
+		if i == tt.cursor {
+			rd["index"] = cursorStyle.Render(strconv.Itoa(i + 1))
+		}
+		sty := evenEntryStyle()
+		if pos%2 != 0 {
+			sty = oddEntryStyle()
+		}
+		if tt.selected[i] {
+			rd[selectedKey] = true
+			sty = selectedEntryStyle()
+		}
+		for j, c := range tt.data[i].Row {
+			if i == tt.cursor && j == tt.column {
+				c = renderLines(cursorStyle, c)
+			} else if tt.highlight != nil {
+				c = highlight(c, tt.highlight, sty)
+			}
+			rd[strconv.Itoa(j+1)] = c
+		}
+		// add the completed row to the list of rows
+		rows[pos] = table.NewRow(rd)
+	}
+	return rows
+}
+
+// Renders the table into the viewport, noting where each row begins.
+func (tt *tableTab) render() {
+	view := tt.tbl.View()
+	tt.vp.SetContent(view)
+	tt.rowOffsets = rowOffsets(view)
+}
+
+// Returns the line on which each row of the rendered table begins.
+// Relies on the table's default (heavy) border and the index column only occupying the first line
+// of each row.
+func rowOffsets(view string) []int {
+	var (
+		offsets []int
+		inBody  bool
+	)
+	for i, line := range strings.Split(view, "\n") {
+		line = ansi.Strip(line)
+		if strings.HasPrefix(line, "┣") || strings.HasPrefix(line, "┗") { // header or footer divider
+			if inBody {
+				break
+			}
+			inBody = true
+			continue
+		}
+		if !inBody {
+			continue
+		}
+		if cells := strings.Split(line, "┃"); len(cells) > 1 && strings.TrimSpace(cells[1]) != "" {
+			offsets = append(offsets, i)
+		}
+	}
+	return offsets
+}
+
+// Sets the rows to display, keeping the cursor on its row (or the nearest following row) if able.
+func (tt *tableTab) setShown(shown []int) {
+	tt.shown = shown
+	tt.sortRows()
+	tt.refresh()
+	pos, _ := shownPosition(tt.shown, tt.cursor)
+	pos = min(pos, len(tt.shown)-1)
+	if pos < 0 {
+		tt.vp.GotoTop()
+		return
+	}
+	tt.jumpToRow(tt.shown[pos])
+}
+
+// Sets the pattern to highlight in the displayed cells.
+func (tt *tableTab) setHighlight(rgx *regexp.Regexp) {
+	tt.highlight = rgx
+	tt.refresh()
+}
+
+// Rebuilds and redraws the rows without moving the viewport.
+func (tt *tableTab) refresh() {
+	tt.tbl = tt.tbl.WithRows(tt.buildRows())
+	y := tt.vp.YOffset
+	tt.render()
+	tt.vp.SetYOffset(y)
+}
+
+// Returns the (0-indexed) row at the top of the viewport.
+func (tt *tableTab) topRow() int {
+	if len(tt.shown) == 0 || len(tt.rowOffsets) == 0 {
+		return 0
+	}
+	i := sort.Search(len(tt.rowOffsets), func(i int) bool { return tt.rowOffsets[i] > tt.vp.YOffset })
+	return tt.shown[min(max(i-1, 0), len(tt.shown)-1)]
+}
+
+// Scrolls the viewport to the given (0-indexed) row and places the cursor on it, if it is
+// displayed.
+func (tt *tableTab) jumpToRow(idx int) {
+	if pos, found := shownPosition(tt.shown, idx); found && pos < len(tt.rowOffsets) {
+		tt.cursor = idx
+		tt.refresh()
+		tt.vp.SetYOffset(tt.rowOffsets[pos])
+	}
+}
+
+// Moves the cursor by the given number of displayed rows.
+func (tt *tableTab) stepCursor(delta int) {
+	pos, _ := shownPosition(tt.shown, tt.cursor)
+	if pos += delta; pos >= 0 && pos < len(tt.shown) {
+		tt.cursor = tt.shown[pos]
 	}
 }
 
@@ -97,91 +243,64 @@ func updateTable(s *DataScope, msg tea.Msg) tea.Cmd {
 		return nil
 	}
 
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, keys.prevColumn):
+			s.table.stepColumn(-1)
+			return nil
+		case key.Matches(msg, keys.nextColumn):
+			s.table.stepColumn(1)
+			return nil
+		case key.Matches(msg, keys.sortColumn):
+			s.table.cycleSort()
+			return nil
+		case key.Matches(msg, keys.hideColumn):
+			s.table.hideColumn()
+			return nil
+		case key.Matches(msg, keys.showColumns):
+			s.table.showColumns()
+			return nil
+		case key.Matches(msg, keys.moveColumnLeft):
+			s.table.moveColumn(-1)
+			return nil
+		case key.Matches(msg, keys.moveColumnRight):
+			s.table.moveColumn(1)
+			return nil
+		case key.Matches(msg, keys.freezeColumns):
+			s.table.toggleFreeze()
+			return nil
+		}
+	}
+
 	// check for column resize keys
-	if msg, ok := msg.(tea.KeyMsg); ok { // only care if alt was held
-		switch msg.String() {
-		// size increases
-		case "alt+1":
-			s.table.alterColumnSize(1, true)
-		case "alt+2":
-			s.table.alterColumnSize(2, true)
-		case "alt+3":
-			s.table.alterColumnSize(3, true)
-		case "alt+4":
-			s.table.alterColumnSize(4, true)
-		case "alt+5":
-			s.table.alterColumnSize(5, true)
-		case "alt+6":
-			s.table.alterColumnSize(6, true)
-		case "alt+7":
-			s.table.alterColumnSize(7, true)
-		case "alt+8":
-			s.table.alterColumnSize(8, true)
-		case "alt+9":
-			s.table.alterColumnSize(9, true)
-		case "alt+0":
-			s.table.alterColumnSize(0, true)
-
-		// size decreases
-		case "alt+!":
-			s.table.alterColumnSize(1, false)
-		case "alt+@":
-			s.table.alterColumnSize(2, false)
-		case "alt+#":
-			s.table.alterColumnSize(3, false)
-		case "alt+$":
-			s.table.alterColumnSize(4, false)
-		case "alt+%":
-			s.table.alterColumnSize(5, false)
-		case "alt+^":
-			s.table.alterColumnSize(6, false)
-		case "alt+&":
-			s.table.alterColumnSize(7, false)
-		case "alt+*":
-			s.table.alterColumnSize(8, false)
-		case "alt+(":
-			s.table.alterColumnSize(9, false)
-		case "alt+)":
-			s.table.alterColumnSize(0, false)
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		for i := range keys.growColumn {
+			if key.Matches(msg, keys.growColumn[i]) {
+				s.table.alterColumnSize(uint(i+1), true)
+			} else if key.Matches(msg, keys.shrinkColumn[i]) {
+				s.table.alterColumnSize(uint(i+1), false)
+			}
 		}
 	}
 
+	prevY, prevCursor := s.table.vp.YOffset, s.table.cursor
 	s.table.vp, cmd = s.table.vp.Update(msg)
 
-	return cmd
-}
-
-// alters the flex factor of the columns corresponding to the given number key.
-// Treats a 0 as a ten.
-func (tt *tableTab) alterColumnSize(numKey uint, increase bool) {
-	var colCount uint = uint(len(tt.columns))
-	var col uint = numKey - 1 // actual column index
-	// treat 0 as 10
-	if numKey == 0 {
-		numKey = 10
-	}
-
-	// only increase the size of a column that exists
-	if colCount > col {
-		var newFF int = tt.columns[col].FlexFactor()
-		if increase {
-			newFF += 1
-		} else {
-			newFF -= 1
-			if newFF < 0 {
-				newFF = 0
-			}
+	// move the cursor with the viewport, stepping through the final rows once it cannot scroll
+	if prevY != s.table.vp.YOffset {
+		s.table.cursor = s.table.topRow()
+	} else if msg, ok := msg.(tea.KeyMsg); ok {
+		if key.Matches(msg, s.table.vp.KeyMap.Down) && s.table.vp.AtBottom() {
+			s.table.stepCursor(1)
+		} else if key.Matches(msg, s.table.vp.KeyMap.Up) && s.table.vp.AtTop() {
+			s.table.stepCursor(-1)
 		}
-
-		tt.columns[col] = table.NewFlexColumn(
-			tt.columns[col].Key(),
-			tt.columns[col].Title(),
-			newFF)
-		clilog.Writer.Debugf("targetting column title %v, new flex factor of %v",
-			tt.columns[col].Title(), newFF)
-		tt.tbl = tt.tbl.WithColumns(tt.columns)
-		tt.vp.SetContent(tt.tbl.View())
 	}
+	if prevCursor != s.table.cursor {
+		s.table.refresh()
+	}
+
+	return cmd
 }
 
 func viewTable(s *DataScope) string {
@@ -194,21 +313,37 @@ func viewTable(s *DataScope) string {
 // recalculate and update the size parameters of the table.
 // The clipped height is the height available to the table tab (height - tabs height).
 func (tt *tableTab) recalculateSize(rawWidth, clippedHeight int) {
-	tt.tbl = tt.tbl.WithMaxTotalWidth(rawWidth).WithTargetWidth(rawWidth)
+	tt.width = rawWidth
+	tt.applyColumns()
 	tt.vp.Width = rawWidth
 	tt.vp.Height = clippedHeight - lipgloss.Height(tt.renderFooter())
-	tt.vp.SetContent(tt.tbl.View())
+	tt.render()
 	tt.ready = true
 }
 
 // Draw and return a footer for the viewport
+var tableShortHelp = fmt.Sprintf("%v scroll • %v cell • home: jump top • end: jump bottom • %v: select\n"+
+	"%v…%v: increase column size • %v…%v: decrease column size\n"+
+	"%v: sort • %v: hide/show columns • %v: move column • %v: freeze columns\n"+
+	"%v: copy row as CSV/JSON • %v: copy cell • %v: copy selection • %v: pivot\n"+
+	"%v: details • %v: search • %v: filter • %v: pipe • %v: cycle • %v: quit",
+	hint(viewportKeys.Up, viewportKeys.Down), hint(keys.prevColumn, keys.nextColumn),
+	hint(keys.toggleSelect),
+	hint(keys.growColumn[0]), hint(keys.growColumn[len(keys.growColumn)-1]),
+	hint(keys.shrinkColumn[0]), hint(keys.shrinkColumn[len(keys.shrinkColumn)-1]),
+	hint(keys.sortColumn), hint(keys.hideColumn, keys.showColumns),
+	hint(keys.moveColumnLeft, keys.moveColumnRight), hint(keys.freezeColumns),
+	hint(keys.copyRecord, keys.copyJSON), hint(keys.copyCell), hint(keys.copySelection),
+	hint(keys.pivot), hint(keys.openDetail), hint(keys.search), hint(keys.filter), hint(keys.pipe),
+	hint(keys.cycleTabs), hint(killer.ChildKill))
+
 func (tt *tableTab) renderFooter() string {
 	var helpSty = stylesheet.GreyedOutStyle.Width(tt.vp.Width).AlignHorizontal(lipgloss.Center)
 	return lipgloss.JoinVertical(lipgloss.Center,
 		scrollPercentLine(tt.vp.Width, tt.vp.ScrollPercent()),
 		lipgloss.JoinVertical(lipgloss.Center,
-			helpSty.Render(stylesheet.UpDown+" scroll • home: jump top • end: jump bottom"),
-			helpSty.Render("alt+[1-9]: increase column size • shift+alt+[1-9]: decrease column size"),
-			helpSty.Render("tab: cycle • esc: quit"),
+			helpOrNotice(lipgloss.JoinVertical(lipgloss.Center,
+				helpSty.Render(tableShortHelp),
+			), tt.notice, tt.vp.Width),
 		))
 }
@@ -3,12 +3,14 @@ package datascope
 /**
  * Contains the generalized data and subroutines for propagating DataScope's tabs.
  * Also contains the implementation of the results and help tabs.
- * Results, Download, and Schedule have been split off into their own files.
+ * Results, Histogram, Fields, Stats, Download, and Schedule have been split off into their own files.
  */
 
 import (
 	"fmt"
 	"gwcli/stylesheet"
+	"gwcli/utilities/keymap"
+	"gwcli/utilities/killer"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -28,8 +30,8 @@ var (
 	tabDescStyle = func(width int) lipgloss.Style {
 		return lipgloss.NewStyle().Width(width).PaddingBottom(1).AlignHorizontal(lipgloss.Center)
 	}
-	evenEntryStyle = lipgloss.NewStyle()
-	oddEntryStyle  = lipgloss.NewStyle().Foreground(stylesheet.SecondaryColor)
+	evenEntryStyle = func() lipgloss.Style { return lipgloss.NewStyle() }
+	oddEntryStyle  = func() lipgloss.Style { return lipgloss.NewStyle().Foreground(stylesheet.SecondaryColor) }
 )
 
 // Returns a line, right-suffixed with the given percent*100.
@@ -58,26 +60,7 @@ func NewViewport() viewport.Model {
 	vp.HighPerformanceRendering = false
 	// set up keybinds directly supported by viewport
 	// other keybinds are managed by the results tab()
-	vp.KeyMap = viewport.KeyMap{
-		PageDown: key.NewBinding(
-			key.WithKeys("pgdown", " ", "f"),
-		),
-		PageUp: key.NewBinding(
-			key.WithKeys("pgup", "b"),
-		),
-		HalfPageUp: key.NewBinding(
-			key.WithKeys("u", "ctrl+u"),
-		),
-		HalfPageDown: key.NewBinding(
-			key.WithKeys("d", "ctrl+d"),
-		),
-		Up: key.NewBinding(
-			key.WithKeys("up", "k"),
-		),
-		Down: key.NewBinding(
-			key.WithKeys("down", "j"),
-		),
-	}
+	vp.KeyMap = viewportKeys
 	return vp
 }
 
@@ -111,6 +94,9 @@ type tab struct {
 
 const (
 	results uint = iota
+	histogram
+	fieldStats
+	stats
 	help
 	download
 	schedule
@@ -118,11 +104,23 @@ const (
 
 // results the array of tabs with all requisite data built in
 func (s *DataScope) generateTabs() []tab {
-	t := make([]tab, 4)
+	t := make([]tab, 7)
 	t[results] = tab{
 		name:       "results",
 		updateFunc: updateResults,
 		viewFunc:   viewResults}
+	t[histogram] = tab{
+		name:       "histogram",
+		updateFunc: updateHistogram,
+		viewFunc:   viewHistogram}
+	t[fieldStats] = tab{
+		name:       "fields",
+		updateFunc: updateFields,
+		viewFunc:   viewFields}
+	t[stats] = tab{
+		name:       "stats",
+		updateFunc: updateStats,
+		viewFunc:   viewStats}
 	t[help] = tab{
 		name:       "help",
 		updateFunc: func(*DataScope, tea.Msg) tea.Cmd { return nil },
@@ -141,6 +139,11 @@ func (s *DataScope) generateTabs() []tab {
 
 //#region help tab
 
+// Returns a help row for the given table-only binding.
+func tableRow(b key.Binding) []string {
+	return []string{b.Help().Key, b.Help().Desc + " (table)"}
+}
+
 const cellWidth int = 25
 
 var compiledHelpString string
@@ -163,8 +166,6 @@ func recompileHelp(s *DataScope) {
 		MaxWidth(s.usableWidth() / 2).Width(cellWidth)
 	valueColumnStyle := lipgloss.NewStyle().MaxWidth(s.usableWidth() / 2).Width(cellWidth)
 
-	joinChar := ","
-
 	tbl := table.New().
 		Border(brdr).
 		BorderRow(true).BorderColumn(false).
@@ -177,14 +178,57 @@ func recompileHelp(s *DataScope) {
 			}
 			return valueColumnStyle
 		})
+	// rows are generated from the bindings, so they reflect the user's keymap
+	row := func(b key.Binding) []string { return []string{b.Help().Key, b.Help().Desc} }
 	tbl.Rows(
 		[][]string{
-			{strings.Join(keys.cycleTabs.Keys(), joinChar), "cycle tables"},
-			{strings.Join(keys.reverseCycleTabs.Keys(), joinChar), "reverse cycle tables"},
-			{stylesheet.UpDown, "scroll page"},
-			{stylesheet.LeftRight, "change page"},
-			{strings.Join(keys.showTabs.Keys(), joinChar), "toggle tab visibility"},
-			{"esc", "quit"},
+			row(keys.cycleTabs),
+			row(keys.reverseCycleTabs),
+			row(keys.showTabs),
+			{keymap.Keys(viewportKeys.Up) + "/" + keymap.Keys(viewportKeys.Down), "scroll"},
+			row(viewportKeys.PageUp),
+			row(viewportKeys.PageDown),
+			row(pageKeys.PrevPage),
+			row(pageKeys.NextPage),
+			row(keys.pageSizeUp),
+			row(keys.pageSizeDown),
+			row(keys.pageSizeAuto),
+			row(keys.gotoPage),
+			row(keys.gotoRecord),
+			row(keys.openDetail),
+			row(keys.copyRecord),
+			row(keys.copyJSON),
+			row(keys.copySelection),
+			tableRow(keys.copyCell),
+			tableRow(keys.prevColumn),
+			tableRow(keys.nextColumn),
+			tableRow(keys.sortColumn),
+			tableRow(keys.hideColumn),
+			tableRow(keys.showColumns),
+			tableRow(keys.moveColumnLeft),
+			tableRow(keys.moveColumnRight),
+			tableRow(keys.freezeColumns),
+			{hint(keys.growColumn...) + " (table)", "increase the size of column 1-10"},
+			{hint(keys.shrinkColumn...) + " (table)", "decrease the size of column 1-10"},
+			row(keys.search),
+			row(keys.filter),
+			row(keys.toggleRegex),
+			row(keys.nextMatch),
+			row(keys.prevMatch),
+			row(keys.toggleSelect),
+			row(keys.selectUp),
+			row(keys.selectDown),
+			row(keys.selectAll),
+			row(keys.pipe),
+			row(keys.pivot),
+			row(keys.back),
+			row(keys.submit),
+			{"enter (histogram)", "jump to the bucket's records"},
+			{keymap.Keys(keys.filter) + " (histogram)", "narrow records to the bucket"},
+			{"enter (fields)", "filter the table to the value"},
+			{keymap.Keys(keys.filter) + " (fields)", "narrow rows to the value"},
+			row(keys.cancel),
+			{keymap.Keys(killer.ChildKill), "quit"},
 		}...)
 
 	// 'place' the table in the center of the *viewport*, horizontally and vertically
@@ -206,11 +250,12 @@ func tabBorderWithBottom(left, middle, right string) lipgloss.Border {
 var (
 	inactiveTabBorder = tabBorderWithBottom("┴", "─", "┴")
 	activeTabBorder   = tabBorderWithBottom("┘", " ", "└")
-	highlightColor    = lipgloss.AdaptiveColor{Light: "#874BFD", Dark: "#7D56F4"}
-	inactiveTabStyle  = lipgloss.NewStyle().Border(inactiveTabBorder, true).
-				BorderForeground(highlightColor).
-				Padding(0, 1).AlignHorizontal(lipgloss.Center)
-	activeTabStyle = inactiveTabStyle.Border(activeTabBorder, true)
+	inactiveTabStyle  = func() lipgloss.Style {
+		return lipgloss.NewStyle().Border(inactiveTabBorder, true).
+			BorderForeground(stylesheet.PrimaryColor).
+			Padding(0, 1).AlignHorizontal(lipgloss.Center)
+	}
+	activeTabStyle = func() lipgloss.Style { return inactiveTabStyle().Border(activeTabBorder, true) }
 )
 
 func (s *DataScope) renderTabs(width int) string {
@@ -227,9 +272,9 @@ func (s *DataScope) renderTabs(width int) string {
 		var style lipgloss.Style
 		isFirst, isLast, isActive := i == 0, i == len(s.tabs)-1, i == int(s.activeTab)
 		if isActive {
-			style = activeTabStyle
+			style = activeTabStyle()
 		} else {
-			style = inactiveTabStyle
+			style = inactiveTabStyle()
 		}
 		style = style.Width(tabWidth)
 		border, _, _, _, _ := style.GetBorder()
@@ -32,11 +32,7 @@ func initialEdiorView(height, width uint) editorView {
 	ev.ta.SetHeight(int(height))
 	ev.ta.Focus()
 	// set up the help keys
-	ev.keys = []key.Binding{ // 0: submit
-		key.NewBinding(
-			key.WithKeys("alt+enter"),
-			key.WithHelp("alt+enter", "submit query"),
-		)}
+	ev.keys = []key.Binding{keys.submit} // 0: submit
 
 	return ev
 }
@@ -1,21 +1,35 @@
 package query
 
 import (
+	"errors"
 	ft "gwcli/stylesheet/flagtext"
+	"gwcli/tree/query/transcode"
 	"strings"
 	"time"
 
 	"github.com/spf13/pflag"
 )
 
+// valid values of --stats-format
+const (
+	statsText = "text"
+	statsJSON = "json"
+)
+
 type queryflags struct {
-	duration time.Duration
-	script   bool
-	json     bool
-	csv      bool
-	outfn    string
-	append   bool
-	schedule schedule
+	duration    time.Duration
+	script      bool
+	json        bool
+	csv         bool
+	format      transcode.Format // the final output format, derived from --json, --csv, and --format
+	project     transcode.Projection
+	stats       bool
+	statsFormat string     // statsText or statsJSON
+	pageSize    int        // DataScope records per page; 0 sizes pages to fit the terminal
+	assert      *assertion // nil if no assertion was requested
+	outfn       string
+	append      bool
+	schedule    schedule
 	//referenceID string
 }
 
@@ -43,6 +57,77 @@ func transmogrifyFlags(fs *pflag.FlagSet) (queryflags, error) {
 	if qf.csv, err = fs.GetBool(ft.Name.CSV); err != nil {
 		return qf, err
 	}
+	if f, err := fs.GetString(ft.Name.Format); err != nil {
+		return qf, err
+	} else if qf.format, err = transcode.ParseFormat(f); err != nil {
+		return qf, err
+	}
+	// fold the format shorthands into format
+	if qf.json && qf.csv {
+		return qf, errors.New("output format cannot be both JSON and CSV")
+	} else if qf.json || qf.csv {
+		var short = transcode.JSON
+		if qf.csv {
+			short = transcode.CSV
+		}
+		if qf.format != transcode.Raw && qf.format != short {
+			return qf, errors.New("--" + ft.Name.Format + " conflicts with --json/--csv")
+		}
+		qf.format = short
+	}
+	// set the shorthands in case format was given instead
+	qf.json = qf.format == transcode.JSON
+	qf.csv = qf.format == transcode.CSV
+
+	if f, err := fs.GetString(ft.Name.Fields); err != nil {
+		return qf, err
+	} else {
+		qf.project.Include = transcode.ParseFieldList(f)
+	}
+	if f, err := fs.GetString(ft.Name.Exclude); err != nil {
+		return qf, err
+	} else {
+		qf.project.Exclude = transcode.ParseFieldList(f)
+	}
+
+	if qf.stats, err = fs.GetBool(ft.Name.Stats); err != nil {
+		return qf, err
+	}
+	if qf.statsFormat, err = fs.GetString(ft.Name.StatsFormat); err != nil {
+		return qf, err
+	}
+	switch qf.statsFormat = strings.ToLower(strings.TrimSpace(qf.statsFormat)); qf.statsFormat {
+	case statsText, statsJSON:
+	default:
+		return qf, errors.New("--" + ft.Name.StatsFormat + " must be '" + statsText + "' or '" + statsJSON + "'")
+	}
+	if fs.Changed(ft.Name.StatsFormat) && !qf.stats {
+		return qf, errors.New("--" + ft.Name.StatsFormat + " requires --stats")
+	}
+
+	if qf.pageSize, err = fs.GetInt(ft.Name.PageSize); err != nil {
+		return qf, err
+	} else if qf.pageSize < 0 {
+		return qf, errors.New("--" + ft.Name.PageSize + " cannot be negative")
+	}
+
+	if a, err := fs.GetString(ft.Name.Assert); err != nil {
+		return qf, err
+	} else if expectEmpty, err := fs.GetBool(ft.Name.ExpectEmpty); err != nil {
+		return qf, err
+	} else if a = strings.TrimSpace(a); a != "" && expectEmpty {
+		return qf, errors.New("--" + ft.Name.Assert + " and --" + ft.Name.ExpectEmpty +
+			" are mutually exclusive")
+	} else {
+		if expectEmpty {
+			a = "count == 0"
+		}
+		if a != "" {
+			if qf.assert, err = parseAssertion(a); err != nil {
+				return qf, err
+			}
+		}
+	}
 
 	if qf.outfn, err = fs.GetString(ft.Name.Output); err != nil {
 		return qf, err
@@ -68,6 +153,11 @@ func transmogrifyFlags(fs *pflag.FlagSet) (queryflags, error) {
 	} else {
 		qf.schedule.desc = strings.TrimSpace(qf.schedule.desc)
 	}
+	// scheduling does not run the query now, so there would be no results to assert against
+	if qf.assert != nil && qf.schedule.cronfreq != "" {
+		return qf, errors.New("--" + ft.Name.Assert + " and --" + ft.Name.ExpectEmpty +
+			" cannot be used with --" + ft.Name.Frequency)
+	}
 
 	return qf, nil
 
@@ -0,0 +1,40 @@
+package query
+
+import (
+	"gwcli/utilities/keymap"
+	"gwcli/utilities/killer"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// keymap scopes of the interactive query prompt's bindings (see keymap.Scope).
+// Once results are displayed, DataScope's bindings take over.
+var (
+	queryScope  = keymap.NewScope("query", keymap.Global)
+	editorScope = keymap.NewScope("editor", queryScope)
+	modifScope  = keymap.NewScope("modifiers", queryScope)
+)
+
+var keys = struct {
+	cycleView key.Binding
+	quit      key.Binding // [handled by mother]
+
+	submit key.Binding // editor view
+
+	prevModifier key.Binding // modifiers view
+	nextModifier key.Binding
+}{
+	cycleView: keymap.Bind(queryScope, "cycle-view", "cycle view",
+		tea.KeyTab.String()),
+	quit: key.NewBinding(key.WithKeys(killer.ChildKill.Keys()...),
+		key.WithHelp(killer.ChildKill.Help().Key, "return to navigation")),
+
+	submit: keymap.Bind(editorScope, "submit", "submit query",
+		"alt+enter"),
+
+	prevModifier: keymap.Bind(modifScope, "previous-modifier", "previous modifier",
+		tea.KeyUp.String()),
+	nextModifier: keymap.Bind(modifScope, "next-modifier", "next modifier",
+		tea.KeyDown.String()),
+}
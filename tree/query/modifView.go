@@ -84,13 +84,13 @@ func initialModifView(height, width uint) modifView {
 func (mv *modifView) update(msg tea.Msg) []tea.Cmd {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.Type {
-		case tea.KeyUp:
+		switch {
+		case key.Matches(msg, keys.prevModifier):
 			mv.selected -= 1
 			if mv.selected <= lowBound {
 				mv.selected = highBound - 1
 			}
-		case tea.KeyDown:
+		case key.Matches(msg, keys.nextModifier):
 			mv.selected += 1
 			if mv.selected >= highBound {
 				mv.selected = lowBound + 1
@@ -17,6 +17,7 @@ package query
  */
 
 import (
+	"errors"
 	"fmt"
 	"gwcli/action"
 	"gwcli/busywait"
@@ -25,6 +26,8 @@ import (
 	"gwcli/mother"
 	ft "gwcli/stylesheet/flagtext"
 	"gwcli/tree/query/datascope"
+	"gwcli/tree/query/searchstats"
+	"gwcli/tree/query/transcode"
 	"gwcli/utilities/treeutils"
 	"gwcli/utilities/uniques"
 	"io"
@@ -34,6 +37,7 @@ import (
 
 	grav "github.com/gravwell/gravwell/v3/client"
 	"github.com/gravwell/gravwell/v3/client/types"
+	"github.com/gravwell/gravwell/v3/ingest/entry"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -56,6 +60,8 @@ const (
 		"If --json or --csv is not given when outputting to a file (`-o`), the results will be " +
 		"text (if able) or an archive binary blob (if unable), depending on the query's render " +
 		"module.\n" +
+		"--format additionally supports NDJSON, TSV, markdown, and HTML for text and table " +
+		"results. These are transcoded locally by gwcli.\n" +
 		"gwcli will not dump binary to terminal; you must supply -o if the results are a binary " +
 		"blob (aka: your query uses a chart-style renderer)."
 )
@@ -71,13 +77,21 @@ var localFS pflag.FlagSet
 func NewQueryAction() action.Pair {
 	cmd := treeutils.NewActionCommand("query", "submit a query",
 		helpDesc,
-		[]string{"q", "search"}, run)
+		[]string{"q", "search"}, nil)
+	// RunE, so violated assertions can exit non-zero
+	cmd.RunE = run
+	cmd.SilenceErrors = true // run reports its own errors
+	// query text has its own pipeline; Mother must not mistake it for redirection
+	cmd.Annotations = map[string]string{mother.RawArgsAnnotation: ""}
 
 	localFS = initialLocalFlagSet()
 
 	cmd.Example = "./gwcli query \"tag=gravwell\""
 
 	cmd.Flags().AddFlagSet(&localFS)
+	if err := cmd.MarkFlagFilename(ft.Name.Output); err != nil {
+		panic(err)
+	}
 
 	return treeutils.GenerateAction(cmd, Query)
 }
@@ -92,6 +106,22 @@ func initialLocalFlagSet() pflag.FlagSet {
 	fs.Bool(ft.Name.Append, false, ft.Name.Append)
 	fs.Bool(ft.Name.JSON, false, ft.Usage.JSON)
 	fs.Bool(ft.Name.CSV, false, ft.Usage.CSV)
+	fs.String(ft.Name.Format, "", "output format of the results.\n"+
+		"One of: "+transcode.FlagValues+".\n"+
+		"--json and --csv are shorthands for their respective formats.")
+	fs.String(ft.Name.Fields, "", ft.Usage.Fields)
+	fs.String(ft.Name.Exclude, "", ft.Usage.Exclude)
+	fs.Bool(ft.Name.Stats, false, "display the search's execution statistics.\n"+
+		"In script mode, stats are written to stderr (see --"+ft.Name.StatsFormat+").\n"+
+		"Interactively, DataScope opens on its stats tab.")
+	fs.String(ft.Name.StatsFormat, statsText, "format of the stats written by --stats in script mode.\n"+
+		"One of: "+statsText+", "+statsJSON+".")
+	fs.Int(ft.Name.PageSize, 0, "number of records per page when interactively displaying results.\n"+
+		"0 sizes pages to fit the terminal.")
+	fs.String(ft.Name.Assert, "", "evaluate a condition on the results, exiting non-zero if it is violated.\n"+
+		"Implies --script; results are only output if -o is given.\n"+
+		"Ex: 'count > 0', 'column(hits) < 100', 'column(status) != \"down\"'")
+	fs.Bool(ft.Name.ExpectEmpty, false, "shorthand for --assert 'count == 0'.")
 
 	// scheduled searches
 	fs.StringP(ft.Name.Name, "n", "", "SCHEDULED."+ft.Usage.Name("scheduled search"))
@@ -105,14 +135,21 @@ func initialLocalFlagSet() pflag.FlagSet {
 
 //#region cobra command
 
-func run(cmd *cobra.Command, args []string) {
+// Only returns an error in script mode or if an assertion was requested, so scripts and monitors
+// (CI, cron, etc) do not silently pass when the query fails.
+// All errors are reported to the user prior to being returned.
+func run(cmd *cobra.Command, args []string) error {
 	var err error
 
 	// fetch flags
 	flags, err := transmogrifyFlags(cmd.Flags())
 	if err != nil {
 		clilog.Tee(clilog.ERROR, cmd.ErrOrStderr(), err.Error()+"\n")
-		return
+		if script, _ := cmd.Flags().GetBool(ft.Name.Script); script ||
+			cmd.Flags().Changed(ft.Name.Assert) || cmd.Flags().Changed(ft.Name.ExpectEmpty) {
+			return err
+		}
+		return nil
 	}
 
 	// TODO pull qry from referenceID, if given
@@ -120,9 +157,12 @@ func run(cmd *cobra.Command, args []string) {
 	qry := strings.TrimSpace(strings.Join(args, " "))
 
 	if qry == "" { // superfluous query
-		if flags.script { // fail out
+		if flags.script || flags.assert != nil { // fail out
 			clilog.Tee(clilog.INFO, cmd.OutOrStdout(), "query is empty. Exitting...\n")
-			return
+			if flags.assert != nil {
+				return errors.New("query is empty")
+			}
+			return nil
 		}
 
 		// spawn mother
@@ -130,20 +170,21 @@ func run(cmd *cobra.Command, args []string) {
 			clilog.Tee(clilog.CRITICAL, cmd.ErrOrStderr(),
 				"failed to spawn a mother instance: "+err.Error()+"\n")
 		}
-		return
+		return nil
 	}
 
-	// branch on script mode
-	if flags.script {
-		runNonInteractive(cmd, flags, qry)
-		return
+	// branch on script mode; assertions are always evaluated in script mode
+	if flags.script || flags.assert != nil {
+		return runNonInteractive(cmd, flags, qry)
 	}
 	runInteractive(cmd, flags, qry)
+	return nil
 }
 
 // run function with --script given, making it entirely independent of user input.
 // Results will be output to a file (if given) or dumped into stdout.
-func runNonInteractive(cmd *cobra.Command, flags queryflags, qry string) {
+// Errors are reported to the user prior to being returned.
+func runNonInteractive(cmd *cobra.Command, flags queryflags, qry string) error {
 	var err error
 
 	if flags.schedule.cronfreq != "" { // check if it is a scheduled query
@@ -161,6 +202,15 @@ func runNonInteractive(cmd *cobra.Command, flags queryflags, qry string) {
 			if flags.csv {
 				fmt.Fprint(cmd.ErrOrStderr(), uniques.WarnFlagIgnore("csv", ft.Name.Frequency)+"\n")
 			}
+			if flags.format.Local() {
+				fmt.Fprint(cmd.ErrOrStderr(), uniques.WarnFlagIgnore(ft.Name.Format, ft.Name.Frequency)+"\n")
+			}
+			if !flags.project.Empty() {
+				fmt.Fprint(cmd.ErrOrStderr(), uniques.WarnFlagIgnore(ft.Name.Fields+"/"+ft.Name.Exclude, ft.Name.Frequency)+"\n")
+			}
+			if flags.stats {
+				fmt.Fprint(cmd.ErrOrStderr(), uniques.WarnFlagIgnore(ft.Name.Stats, ft.Name.Frequency)+"\n")
+			}
 		}
 
 		// if a name was not given, populate a default name
@@ -179,20 +229,21 @@ func runNonInteractive(cmd *cobra.Command, flags queryflags, qry string) {
 		)
 		if invalid != "" { // bad parameters
 			clilog.Tee(clilog.INFO, cmd.ErrOrStderr(), invalid)
-			return
+			return errors.New(invalid)
 		} else if err != nil {
 			clilog.Tee(clilog.ERROR, cmd.ErrOrStderr(), err.Error()+"\n")
+			return err
 		}
 		clilog.Tee(clilog.INFO, cmd.OutOrStdout(),
 			fmt.Sprintf("Successfully scheduled query '%v' (ID: %v)\n", flags.schedule.name, id))
-		return
+		return nil
 	}
 
 	// submit the immediate query
 	var search grav.Search
 	if s, err := connection.StartQuery(qry, -flags.duration); err != nil {
 		clilog.Tee(clilog.ERROR, cmd.ErrOrStderr(), err.Error()+"\n")
-		return
+		return err
 	} else {
 		search = s
 	}
@@ -200,7 +251,21 @@ func runNonInteractive(cmd *cobra.Command, flags queryflags, qry string) {
 	// wait for query to complete
 	if err := waitForSearch(search, true); err != nil {
 		clilog.Tee(clilog.ERROR, cmd.ErrOrStderr(), err.Error()+"\n")
-		return
+		return err
+	}
+
+	if flags.stats {
+		outputStats(cmd, flags.statsFormat, &search)
+	}
+
+	if flags.assert != nil {
+		return checkAssertion(cmd, flags, &search)
+	}
+
+	// locally-transcoded formats and projected results are built from the text/table results,
+	// rather than downloaded
+	if flags.format.Local() || !flags.project.Empty() {
+		return outputTranscoded(cmd, flags, &search)
 	}
 
 	// fetch the data from the search
@@ -214,7 +279,7 @@ func runNonInteractive(cmd *cobra.Command, flags queryflags, qry string) {
 		clilog.Tee(clilog.ERROR, cmd.ErrOrStderr(),
 			fmt.Sprintf("failed to retrieve results from search %s (format %v): %v\n",
 				search.ID, format, err.Error()))
-		return
+		return err
 	}
 	defer results.Close()
 
@@ -224,21 +289,21 @@ func runNonInteractive(cmd *cobra.Command, flags queryflags, qry string) {
 		var of *os.File
 		if of, err = openFile(flags.outfn, flags.append); err != nil {
 			clilog.Tee(clilog.ERROR, cmd.ErrOrStderr(), err.Error()+"\n")
-			return
+			return err
 		}
 		defer of.Close()
 
 		// consumes the results and spit them into the open file
 		if b, err := of.ReadFrom(results); err != nil {
 			clilog.Tee(clilog.ERROR, cmd.ErrOrStderr(), err.Error()+"\n")
-			return
+			return err
 		} else {
 			clilog.Writer.Infof("Streamed %d bytes (format %v) into %s", b, format, of.Name())
 		}
 		// stdout output is acceptible as the user is redirecting actual results to a file.
 		fmt.Fprintln(cmd.OutOrStdout(),
 			connection.DownloadQuerySuccessfulString(of.Name(), flags.append, format))
-		return
+		return nil
 	} else if format == types.DownloadArchive { // check for binary output
 		fmt.Fprintf(cmd.OutOrStdout(), "refusing to dump binary blob (format %v) to stdout.\n"+
 			"If this is intentional, re-run with -o <FILENAME>.\n"+
@@ -247,7 +312,7 @@ func runNonInteractive(cmd *cobra.Command, flags queryflags, qry string) {
 	} else { // text results, stdout
 		if r, err := io.ReadAll(results); err != nil {
 			clilog.Tee(clilog.ERROR, cmd.ErrOrStderr(), err.Error()+"\n")
-			return
+			return err
 		} else {
 			if len(r) == 0 {
 				fmt.Fprintln(cmd.OutOrStdout(), "no results to display")
@@ -257,6 +322,114 @@ func runNonInteractive(cmd *cobra.Command, flags queryflags, qry string) {
 		}
 	}
 
+	return nil
+}
+
+// helper subroutine for runNonInteractive.
+// Fetches the structured results of the given, completed search and outputs them via
+// writeTranscoded.
+func outputTranscoded(cmd *cobra.Command, flags queryflags, search *grav.Search) error {
+	res, err := fetchResults(search)
+	if err != nil {
+		clilog.Tee(clilog.ERROR, cmd.ErrOrStderr(),
+			fmt.Sprintf("failed to retrieve results from search %s (format %v): %v\n",
+				search.ID, flags.format, err.Error()))
+		return err
+	}
+	if res.Len() == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "no results to display")
+		return nil
+	}
+	return writeTranscoded(cmd, flags, res, cmd.OutOrStdout())
+}
+
+// Projects the given results and transcodes them into flags.format, writing them to the output
+// file (if given) or to w.
+func writeTranscoded(cmd *cobra.Command, flags queryflags, res transcode.Results, w io.Writer,
+) error {
+	if unmatched := res.Unmatched(flags.project); len(unmatched) > 0 {
+		clilog.Tee(clilog.WARN, cmd.ErrOrStderr(), unmatchedFieldsWarning(unmatched)+"\n")
+	}
+	res = res.Project(flags.project)
+
+	if flags.outfn == "" {
+		if err := transcode.Write(w, flags.format, res); err != nil {
+			clilog.Tee(clilog.ERROR, cmd.ErrOrStderr(), err.Error()+"\n")
+			return err
+		}
+		return nil
+	}
+
+	of, err := openFile(flags.outfn, flags.append)
+	if err != nil {
+		clilog.Tee(clilog.ERROR, cmd.ErrOrStderr(), err.Error()+"\n")
+		return err
+	}
+	defer of.Close()
+	if err := transcode.Write(of, flags.format, res); err != nil {
+		clilog.Tee(clilog.ERROR, cmd.ErrOrStderr(), err.Error()+"\n")
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(),
+		connection.DownloadQuerySuccessfulString(of.Name(), flags.append, flags.format.String()))
+	return nil
+}
+
+// Returns a warning that the given projected fields match nothing in the results.
+func unmatchedFieldsWarning(fields []string) string {
+	return "no column or key matches the field(s) " + strings.Join(fields, ", ")
+}
+
+// helper subroutine for runNonInteractive.
+// Fetches the results of the given, completed search and evaluates flags.assert against them,
+// printing a summary to stdout.
+// Results are only output if an output file was given, as stdout is reserved for the summary.
+// Returns errAssertionFailed if the results violated the assertion.
+func checkAssertion(cmd *cobra.Command, flags queryflags, search *grav.Search) error {
+	res, err := fetchResults(search)
+	if err != nil {
+		clilog.Tee(clilog.ERROR, cmd.ErrOrStderr(),
+			fmt.Sprintf("failed to retrieve results from search %s: %v\n", search.ID, err))
+		return err
+	}
+	if flags.outfn != "" {
+		if err := writeTranscoded(cmd, flags, res, nil); err != nil {
+			return err
+		}
+	}
+
+	passed, summary, err := flags.assert.evaluate(res)
+	if err != nil {
+		clilog.Tee(clilog.ERROR, cmd.ErrOrStderr(), "failed to evaluate assertion: "+err.Error()+"\n")
+		return err
+	}
+	clilog.Writer.Info(summary)
+	fmt.Fprintln(cmd.OutOrStdout(), summary)
+	if !passed {
+		return errAssertionFailed
+	}
+	return nil
+}
+
+// helper subroutine for runNonInteractive.
+// Fetches the stats of the given, completed search and writes them to stderr in the given format.
+func outputStats(cmd *cobra.Command, format string, search *grav.Search) {
+	st, err := searchstats.Fetch(search)
+	if err != nil {
+		clilog.Tee(clilog.ERROR, cmd.ErrOrStderr(),
+			fmt.Sprintf("failed to fetch stats of search %s: %v\n", search.ID, err))
+		return
+	}
+	if format == statsJSON {
+		b, err := st.JSON()
+		if err != nil {
+			clilog.Tee(clilog.ERROR, cmd.ErrOrStderr(), err.Error()+"\n")
+			return
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "%s\n", b)
+		return
+	}
+	fmt.Fprintln(cmd.ErrOrStderr(), st.String())
 }
 
 // run function without --script given, making it acceptable to rely on user input
@@ -278,26 +451,27 @@ func runInteractive(cmd *cobra.Command, flags queryflags, qry string) {
 	}
 
 	// get results to pass to data scope
-	var (
-		results   []string
-		tableMode bool
-	)
-	results, tableMode, err := fetchResults(&search)
+	res, err := fetchResults(&search)
 	if err != nil {
 		clilog.Tee(clilog.ERROR, cmd.ErrOrStderr(), err.Error()+"\n")
 		return
-	} else if results == nil {
+	} else if res.Len() == 0 {
 		fmt.Fprintln(cmd.OutOrStdout(), NoResultsText)
 		return
 	}
 
 	// pass results into datascope
 	// spin up a scrolling pager to display
-	if p, err := datascope.CobraNew(
-		results, &search, tableMode,
-		datascope.WithAutoDownload(flags.outfn, flags.append, flags.json, flags.csv),
+	opts := []datascope.DataScopeOption{
+		datascope.WithAutoDownload(flags.outfn, flags.append, flags.format, flags.project),
 		datascope.WithSchedule(flags.schedule.cronfreq, flags.schedule.name, flags.schedule.desc),
-	); err != nil {
+		datascope.WithPageSize(flags.pageSize),
+		datascope.WithPivot(pivot),
+	}
+	if flags.stats {
+		opts = append(opts, datascope.WithStats())
+	}
+	if p, err := datascope.CobraNew(res, &search, opts...); err != nil {
 		clilog.Tee(clilog.ERROR, cmd.ErrOrStderr(), err.Error())
 		return
 	} else {
@@ -309,6 +483,29 @@ func runInteractive(cmd *cobra.Command, flags queryflags, qry string) {
 
 }
 
+// Runs the given query over the given time range, for DataScope's pivots.
+// A zero time range covers the default duration.
+func pivot(qry string, start, end time.Time) (*grav.Search, transcode.Results, error) {
+	if start.IsZero() || end.IsZero() {
+		end = time.Now()
+		start = end.Add(-defaultDuration)
+	}
+	search, err := connection.StartQueryRange(qry, start, end)
+	if err != nil {
+		return nil, transcode.Results{}, err
+	}
+	if err := connection.Client.WaitForSearch(search); err != nil {
+		return nil, transcode.Results{}, err
+	}
+	res, err := fetchResults(&search)
+	if err != nil {
+		return nil, transcode.Results{}, err
+	} else if res.Len() == 0 {
+		return nil, transcode.Results{}, errors.New(NoResultsText)
+	}
+	return &search, res, nil
+}
+
 // Stops execution and waits for the given search to complete.
 // Adds a spinner if not in script mode.
 func waitForSearch(s grav.Search, scriptMode bool) error {
@@ -371,60 +568,53 @@ func openFile(path string, append bool) (*os.File, error) {
 
 // Given an active search handle associated to a completed search,
 // fetchResults pulls back all available results, using the appropriate Get function based on the
-// search's renderer
-func fetchResults(search *grav.Search) (results []string, tableMode bool, err error) {
+// search's renderer.
+// Returns zero-length results (see Results.Len()) if the search had no results.
+func fetchResults(search *grav.Search) (transcode.Results, error) {
 	clilog.Writer.Infof("fetching results of type %v", search.RenderMod)
 	switch search.RenderMod {
 	case types.RenderNameTable:
-		if columns, rows, err := fetchTableResults(search); err != nil {
-			return nil, false, err
-		} else if len(rows) != 0 {
-			// format the table for datascope
-			// basically a csv
-			results = make([]string, len(rows)+1)
-			results[0] = strings.Join(columns, ",") // first entry is the header
-			for i, row := range rows {
-				results[i+1] = strings.Join(row.Row, ",")
-			}
-			return results, true, nil
+		columns, rows, err := fetchTableResults(search)
+		if err != nil {
+			return transcode.Results{}, err
+		}
+		if columns == nil { // ensure results are recognized as tabular, even if empty
+			columns = []string{}
 		}
-		// no results
-		return nil, true, nil
+		return transcode.Results{Columns: columns, Rows: rows}, nil
 	case types.RenderNameRaw, types.RenderNameText, types.RenderNameHex:
-		if rawResults, err := fetchTextResults(search); err != nil {
-			return nil, false, err
-		} else if len(rawResults) != 0 {
-			// format the data for datascope
-			results = make([]string, len(rawResults))
-			for i, r := range rawResults {
-				results[i] = string(r.Data)
-			}
-			return results, false, nil
+		entries, tags, err := fetchTextResults(search)
+		if err != nil {
+			return transcode.Results{}, err
 		}
-		// no results
-		return nil, false, nil
+		return transcode.Results{Entries: entries, Tags: tags}, nil
 	}
 
 	// did not manage to complete results earlier; fail out
-	return nil, false, fmt.Errorf("unable to display results of type %v", search.RenderMod)
+	return transcode.Results{}, fmt.Errorf("unable to display results of type %v", search.RenderMod)
 }
 
 // Fetches all text results related to the given search by continually re-fetching until no more
-// results remain
-func fetchTextResults(s *grav.Search) ([]types.SearchEntry, error) {
+// results remain.
+// Also returns a map of the tag ids referenced by the entries to their names.
+func fetchTextResults(s *grav.Search) ([]types.SearchEntry, map[entry.EntryTag]string, error) {
 	// return results for output to terminal
 	// batch results until we have the last of them
 	var (
-		results []types.SearchEntry = make([]types.SearchEntry, 0, pageSize)
-		low     uint64              = 0
-		high    uint64              = pageSize
+		results []types.SearchEntry       = make([]types.SearchEntry, 0, pageSize)
+		tags    map[entry.EntryTag]string = make(map[entry.EntryTag]string)
+		low     uint64                    = 0
+		high    uint64                    = pageSize
 	)
 	for { // accumulate the results
 		r, err := connection.Client.GetTextResults(*s, low, high)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		results = append(results, r.Entries...)
+		for name, id := range r.Tags {
+			tags[id] = name
+		}
 		if !r.AdditionalEntries { // all records obtained
 			break
 		}
@@ -435,7 +625,7 @@ func fetchTextResults(s *grav.Search) ([]types.SearchEntry, error) {
 
 	clilog.Writer.Infof("%d results obtained", len(results))
 
-	return results, nil
+	return results, tags, nil
 }
 
 // Sister subroutine to fetchTextResults()
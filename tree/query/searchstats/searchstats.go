@@ -0,0 +1,182 @@
+/*
+Searchstats gathers the backend's metrics about a completed search (effective query, time range,
+pipeline throughput, per-module timings, and indexer state) into a single structure suitable for
+display in DataScope or emission (as text or JSON) in script mode.
+*/
+package searchstats
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gwcli/clilog"
+	"gwcli/connection"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	grav "github.com/gravwell/gravwell/v3/client"
+	"github.com/gravwell/gravwell/v3/client/types"
+)
+
+// Module holds the accumulated metrics of a single module in the search pipeline.
+type Module struct {
+	Name        string
+	Args        string
+	InputCount  uint64
+	OutputCount uint64
+	InputBytes  uint64
+	OutputBytes uint64
+	Duration    time.Duration
+}
+
+// Stats is the collection of metrics associated to a single search.
+type Stats struct {
+	ID             string
+	UserQuery      string
+	EffectiveQuery string // query after macro expansion
+	Start          time.Time
+	End            time.Time
+	Duration       time.Duration // time spent executing the search
+
+	EntriesScanned  uint64 // entries that entered the pipeline
+	EntriesReturned uint64 // entries that exited the pipeline
+	BytesProcessed  uint64 // bytes that entered the pipeline
+	StoreSize       int64  // size of the renderer's storage
+	IndexSize       int64  // size of the renderer's index
+
+	Modules  []Module          // per-module metrics, in pipeline order; nil if unavailable
+	Indexers map[string]string // indexer -> state at the time of collection; nil if unavailable
+}
+
+// Fetch gathers the stats of the given (completed and attached) search.
+// Only failing to fetch the search's info is considered an error; module and indexer metrics are
+// left nil if they could not be fetched.
+func Fetch(s *grav.Search) (Stats, error) {
+	if s == nil {
+		return Stats{}, errors.New("search cannot be nil")
+	}
+	info, err := connection.Client.SearchInfo(s.ID)
+	if err != nil {
+		return Stats{}, err
+	}
+	st := Stats{
+		ID:              s.ID,
+		UserQuery:       info.UserQuery,
+		EffectiveQuery:  info.EffectiveQuery,
+		Start:           info.StartRange,
+		End:             info.EndRange,
+		Duration:        info.Duration,
+		EntriesReturned: uint64(max(info.ItemCount, 0)),
+		StoreSize:       info.StoreSize,
+		IndexSize:       info.IndexSize,
+	}
+
+	// fetch module stats via the search's websocket
+	var resp types.StatsResponse
+	if err := s.Exchange(types.StatsRequest{
+		BaseRequest: types.BaseRequest{ID: types.REQ_STATS_GET_SUMMARY},
+	}, &resp); err != nil {
+		clilog.Writer.Warnf("failed to fetch module stats for search %v: %v", s.ID, err)
+	} else if err := resp.Err(); err != nil {
+		clilog.Writer.Warnf("failed to fetch module stats for search %v: %v", s.ID, err)
+	} else if resp.ID != types.RESP_STATS_GET_SUMMARY || resp.Stats == nil {
+		clilog.Writer.Warnf("unexpected stats response for search %v (ID: %#x)", s.ID, resp.ID)
+	} else {
+		st.applyModules(summarize(resp.Stats.Set))
+	}
+
+	if states, err := connection.Client.GetPingStates(); err != nil {
+		clilog.Writer.Warnf("failed to fetch indexer states: %v", err)
+	} else {
+		st.Indexers = states
+	}
+
+	return st, nil
+}
+
+// summarize accumulates the module stats of each set into a single entry per module.
+// Modules are identified by their position in the pipeline.
+func summarize(sets []types.StatSet) []Module {
+	var mods []Module
+	for _, set := range sets {
+		for i, sms := range set.Stats {
+			if i >= len(mods) {
+				mods = append(mods, Module{Name: sms.Name, Args: sms.Args})
+			}
+			mods[i].InputCount += sms.InputCount
+			mods[i].OutputCount += sms.OutputCount
+			mods[i].InputBytes += sms.InputBytes
+			mods[i].OutputBytes += sms.OutputBytes
+			mods[i].Duration += sms.Duration
+		}
+	}
+	return mods
+}
+
+// applyModules saves the given modules and derives pipeline throughput from them.
+func (st *Stats) applyModules(mods []Module) {
+	if len(mods) == 0 {
+		return
+	}
+	st.Modules = mods
+	st.EntriesScanned = mods[0].InputCount
+	st.BytesProcessed = mods[0].InputBytes
+	st.EntriesReturned = mods[len(mods)-1].OutputCount
+}
+
+// JSON returns the stats as a JSON object.
+func (st Stats) JSON() ([]byte, error) {
+	return json.Marshal(st)
+}
+
+// String returns the stats as human-readable text.
+func (st Stats) String() string {
+	var sb strings.Builder
+	tw := tabwriter.NewWriter(&sb, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Search ID:\t%v\n", st.ID)
+	if st.UserQuery != st.EffectiveQuery {
+		fmt.Fprintf(tw, "Query:\t%v\n", st.UserQuery)
+	}
+	fmt.Fprintf(tw, "Effective Query:\t%v\n", st.EffectiveQuery)
+	fmt.Fprintf(tw, "Time Range:\t%v - %v\n",
+		st.Start.Format(time.RFC3339), st.End.Format(time.RFC3339))
+	fmt.Fprintf(tw, "Duration:\t%v\n", st.Duration)
+	fmt.Fprintf(tw, "Entries Scanned:\t%d\n", st.EntriesScanned)
+	fmt.Fprintf(tw, "Entries Returned:\t%d\n", st.EntriesReturned)
+	fmt.Fprintf(tw, "Bytes Processed:\t%d\n", st.BytesProcessed)
+	fmt.Fprintf(tw, "Storage (store/index):\t%d/%d bytes\n", st.StoreSize, st.IndexSize)
+	tw.Flush()
+
+	sb.WriteString("\nModules:\n")
+	if len(st.Modules) == 0 {
+		sb.WriteString("  unavailable\n")
+	} else {
+		tw = tabwriter.NewWriter(&sb, 0, 4, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(tw, "Module\tIn\tOut\tBytes In\tBytes Out\tDuration\t")
+		for _, m := range st.Modules {
+			fmt.Fprintf(tw, "%v\t%d\t%d\t%d\t%d\t%v\t\n",
+				m.Name, m.InputCount, m.OutputCount, m.InputBytes, m.OutputBytes, m.Duration)
+		}
+		tw.Flush()
+	}
+
+	sb.WriteString("\nIndexers:\n")
+	if len(st.Indexers) == 0 {
+		sb.WriteString("  unavailable\n")
+	} else {
+		names := make([]string, 0, len(st.Indexers))
+		for n := range st.Indexers {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		tw = tabwriter.NewWriter(&sb, 0, 4, 2, ' ', 0)
+		for _, n := range names {
+			fmt.Fprintf(tw, "  %v\t%v\n", n, st.Indexers[n])
+		}
+		tw.Flush()
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
@@ -0,0 +1,59 @@
+package searchstats
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gravwell/gravwell/v3/client/types"
+)
+
+func Test_summarize(t *testing.T) {
+	mod := func(name string, in, out uint64, dur time.Duration) types.SearchModuleStats {
+		return types.SearchModuleStats{
+			Name: name,
+			ModuleStatsUpdate: types.ModuleStatsUpdate{
+				InputCount: in, OutputCount: out, InputBytes: in * 10, OutputBytes: out * 10,
+				Duration: dur,
+			},
+		}
+	}
+	sets := []types.StatSet{
+		{Stats: []types.SearchModuleStats{mod("grep", 100, 10, time.Second), mod("table", 10, 10, 0)}},
+		{Stats: []types.SearchModuleStats{mod("grep", 50, 5, time.Second), mod("table", 5, 5, time.Millisecond)}},
+	}
+	want := []Module{
+		{Name: "grep", InputCount: 150, OutputCount: 15, InputBytes: 1500, OutputBytes: 150,
+			Duration: 2 * time.Second},
+		{Name: "table", InputCount: 15, OutputCount: 15, InputBytes: 150, OutputBytes: 150,
+			Duration: time.Millisecond},
+	}
+	got := summarize(sets)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("summarize() = %+v, want %+v", got, want)
+	}
+
+	var st Stats
+	st.applyModules(got)
+	if st.EntriesScanned != 150 || st.EntriesReturned != 15 || st.BytesProcessed != 1500 {
+		t.Errorf("applyModules() derived scanned %d, returned %d, bytes %d; want 150, 15, 1500",
+			st.EntriesScanned, st.EntriesReturned, st.BytesProcessed)
+	}
+}
+
+func TestStats_String(t *testing.T) {
+	st := Stats{
+		ID:             "123",
+		UserQuery:      "tag=gravwell $mymacro",
+		EffectiveQuery: "tag=gravwell grep foo",
+		Modules:        []Module{{Name: "grep"}},
+	}
+	s := st.String()
+	for _, want := range []string{"tag=gravwell $mymacro", "tag=gravwell grep foo", "grep",
+		"Indexers:\n  unavailable"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("String() missing %q:\n%s", want, s)
+		}
+	}
+}
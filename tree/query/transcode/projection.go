@@ -0,0 +1,187 @@
+package transcode
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/gravwell/gravwell/v3/client/types"
+)
+
+// Projection selects the fields to keep in a set of results.
+// Include (if not empty) whitelists fields, then Exclude blacklists fields.
+// Fields are table columns or, for text entries whose data is a JSON object, the object's top-level
+// keys. Names are matched exactly.
+type Projection struct {
+	Include []string
+	Exclude []string
+}
+
+// Empty returns whether the projection would leave results unaltered.
+func (p Projection) Empty() bool {
+	return len(p.Include) == 0 && len(p.Exclude) == 0
+}
+
+// keep returns whether the given field survives the projection.
+func (p Projection) keep(field string) bool {
+	if len(p.Include) > 0 && !contains(p.Include, field) {
+		return false
+	}
+	return !contains(p.Exclude, field)
+}
+
+func contains(set []string, s string) bool {
+	for _, v := range set {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseFieldList splits a comma-separated list of field names, trimming whitespace and dropping
+// empty names.
+func ParseFieldList(s string) []string {
+	var fields []string
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// Unmatched returns the fields named by p (in the order given, without duplicates) that match no
+// table column or, for text results, no top-level key of any entry whose data is a JSON object.
+// Such fields are likely typos; they do not affect the projection.
+func (r Results) Unmatched(p Projection) []string {
+	if p.Empty() {
+		return nil
+	}
+	known := make(map[string]bool)
+	if r.Table() {
+		for _, c := range r.Columns {
+			known[c] = true
+		}
+	} else {
+		for _, e := range r.Entries {
+			var obj map[string]json.RawMessage
+			if json.Unmarshal(e.Data, &obj) != nil {
+				continue
+			}
+			for k := range obj {
+				known[k] = true
+			}
+		}
+	}
+
+	var unmatched []string
+	for _, f := range append(append([]string{}, p.Include...), p.Exclude...) {
+		if !known[f] && !contains(unmatched, f) {
+			unmatched = append(unmatched, f)
+		}
+	}
+	return unmatched
+}
+
+// Project returns a copy of the results with only the fields selected by p.
+// Text entries whose data is not a JSON object are returned unaltered.
+// The original results are not modified.
+func (r Results) Project(p Projection) Results {
+	if p.Empty() {
+		return r
+	}
+
+	if r.Table() {
+		var keptIdx []int
+		for i, c := range r.Columns {
+			if p.keep(c) {
+				keptIdx = append(keptIdx, i)
+			}
+		}
+		return r.Arrange(keptIdx)
+	}
+
+	entries := make([]types.SearchEntry, len(r.Entries))
+	for i, e := range r.Entries {
+		entries[i] = e
+		if data, ok := projectJSON(e.Data, p); ok {
+			entries[i].Data = data
+		}
+	}
+	return Results{Tags: r.Tags, Entries: entries}
+}
+
+// projectJSON drops the top-level keys of the given JSON object that are not kept by p.
+// Key order is retained.
+// Returns false if data is not a JSON object.
+func projectJSON(data []byte, p Projection) ([]byte, bool) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil, false
+	}
+
+	var buf bytes.Buffer
+	buf.WriteRune('{')
+	first := true
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, false
+		}
+		key, ok := t.(string)
+		if !ok {
+			return nil, false
+		}
+		var val json.RawMessage
+		if err := dec.Decode(&val); err != nil {
+			return nil, false
+		}
+		if !p.keep(key) {
+			continue
+		}
+		if !first {
+			buf.WriteRune(',')
+		}
+		first = false
+		k, _ := json.Marshal(key)
+		buf.Write(k)
+		buf.WriteRune(':')
+		buf.Write(val)
+	}
+	// consume the closing brace and ensure nothing trails the object
+	if t, err := dec.Token(); err != nil || t != json.Delim('}') {
+		return nil, false
+	}
+	if _, err := dec.Token(); err == nil {
+		return nil, false
+	}
+	buf.WriteRune('}')
+	return buf.Bytes(), true
+}
+
+// Arrange returns table results consisting of only the given columns (by index), in the given
+// order.
+// Text results are returned unaltered.
+// The original results are not modified.
+func (r Results) Arrange(columns []int) Results {
+	if !r.Table() {
+		return r
+	}
+	cols := make([]string, 0, len(columns))
+	for _, idx := range columns {
+		cols = append(cols, r.Columns[idx])
+	}
+	rows := make([]types.TableRow, len(r.Rows))
+	for i, row := range r.Rows {
+		rows[i] = types.TableRow{TS: row.TS, Row: make([]string, 0, len(columns))}
+		for _, idx := range columns {
+			var val string
+			if idx < len(row.Row) {
+				val = row.Row[idx]
+			}
+			rows[i].Row = append(rows[i].Row, val)
+		}
+	}
+	return Results{Tags: r.Tags, Columns: cols, Rows: rows}
+}
@@ -0,0 +1,373 @@
+/*
+Transcode converts the structured results of a search into output formats the Gravwell backend does
+not provide natively.
+
+The backend's DownloadSearch only serves raw (renderer-determined), JSON, and CSV data. All other
+formats are generated locally from the text or table results query already fetches via
+Get*Results, so script mode and DataScope's download tab produce identical output.
+*/
+package transcode
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gravwell/gravwell/v3/client/types"
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+)
+
+//#region enumeration
+
+type Format uint
+
+const (
+	Raw      Format = iota // server-side; determined by the search's renderer
+	JSON                   // server-side
+	CSV                    // server-side
+	NDJSON                 // local; one JSON object per line
+	TSV                    // local
+	Markdown               // local; GitHub-flavored table
+	HTML                   // local; self-contained document
+	unknown
+)
+
+func (f Format) String() string {
+	switch f {
+	case Raw:
+		return "raw"
+	case JSON:
+		return "JSON"
+	case CSV:
+		return "CSV"
+	case NDJSON:
+		return "NDJSON"
+	case TSV:
+		return "TSV"
+	case Markdown:
+		return "markdown"
+	case HTML:
+		return "HTML"
+	}
+	return fmt.Sprintf("unknown format (%d)", f)
+}
+
+// Local returns whether the format is transcoded by gwcli, rather than downloaded from the backend.
+// Write can produce server-side formats as well, for when results must be altered prior to output
+// (see Projection).
+func (f Format) Local() bool {
+	return f >= NDJSON && f < unknown
+}
+
+// FlagValues is the list of strings accepted by ParseFormat, for use in flag usage text.
+const FlagValues = "raw, json, csv, ndjson, tsv, markdown (md), html"
+
+// ParseFormat returns the Format associated to the given string, case-insensitively.
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "raw":
+		return Raw, nil
+	case "json":
+		return JSON, nil
+	case "csv":
+		return CSV, nil
+	case "ndjson", "jsonl":
+		return NDJSON, nil
+	case "tsv":
+		return TSV, nil
+	case "markdown", "md":
+		return Markdown, nil
+	case "html":
+		return HTML, nil
+	}
+	return unknown, fmt.Errorf("unknown format '%v'. Expected one of: %v", s, FlagValues)
+}
+
+//#endregion enumeration
+
+// Results is the structured form of a completed search's results.
+// Text-style renderers populate Entries (and Tags); the table renderer populates Columns and Rows.
+type Results struct {
+	Tags    map[entry.EntryTag]string // tag id -> tag name, for resolving Entries[].Tag
+	Entries []types.SearchEntry
+
+	Columns []string
+	Rows    []types.TableRow
+}
+
+// Table returns whether the results are tabular (from the table renderer).
+func (r Results) Table() bool {
+	return r.Columns != nil
+}
+
+// Len returns the number of records (entries or rows) in the results.
+func (r Results) Len() int {
+	if r.Table() {
+		return len(r.Rows)
+	}
+	return len(r.Entries)
+}
+
+// TagName returns the name of the given tag, or its numeric id if the name is unknown.
+func (r Results) TagName(t entry.EntryTag) string {
+	if name, ok := r.Tags[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("%d", t)
+}
+
+// textColumns are the synthetic columns used when tabulating text entries.
+var textColumns = []string{"TS", "Tag", "Source", "Data"}
+
+// Tabulate returns the results as a header and a series of rows, regardless of renderer.
+// Text entries are split into their timestamp, tag, source, and data.
+func (r Results) Tabulate() (header []string, rows [][]string) {
+	if r.Table() {
+		rows = make([][]string, len(r.Rows))
+		for i, row := range r.Rows {
+			rows[i] = row.Row
+		}
+		return r.Columns, rows
+	}
+
+	rows = make([][]string, len(r.Entries))
+	for i, e := range r.Entries {
+		rows[i] = []string{formatTS(e.TS), r.TagName(e.Tag), e.SRC.String(), string(e.Data)}
+	}
+	return textColumns, rows
+}
+
+// Write transcodes the results into the given format, writing them to w.
+// Raw writes text entries' data one per line, and table rows as CSV.
+func Write(w io.Writer, f Format, r Results) error {
+	if w == nil {
+		return errors.New("writer cannot be nil")
+	}
+
+	switch f {
+	case NDJSON:
+		return writeNDJSON(w, r)
+	case JSON:
+		return writeJSON(w, r)
+	case Raw:
+		if !r.Table() {
+			for _, e := range r.Entries {
+				if _, err := io.WriteString(w, string(e.Data)+"\n"); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	header, rows := r.Tabulate()
+	switch f {
+	case Raw, CSV:
+		return writeCSV(w, header, rows)
+	case TSV:
+		return writeTSV(w, header, rows)
+	case Markdown:
+		return writeMarkdown(w, header, rows)
+	case HTML:
+		return writeHTML(w, header, rows)
+	}
+	return fmt.Errorf("no transcoder for format %v", f)
+}
+
+// Timestamps are output in a consistent, sortable format.
+func formatTS(ts entry.Timestamp) string {
+	return ts.StandardTime().Format(time.RFC3339Nano)
+}
+
+//#region transcoders
+
+// Each text entry becomes an object with its metadata; each table row becomes an object mapping
+// column -> value, in column order.
+func writeNDJSON(w io.Writer, r Results) error {
+	if !r.Table() {
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		for _, e := range r.Entries {
+			if err := enc.Encode(struct {
+				TS     string
+				Tag    string
+				Source string
+				Data   string
+			}{formatTS(e.TS), r.TagName(e.Tag), e.SRC.String(), string(e.Data)}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// build each object by hand to retain column ordering
+	for _, row := range r.Rows {
+		var sb strings.Builder
+		sb.WriteRune('{')
+		for i, col := range r.Columns {
+			var val string
+			if i < len(row.Row) {
+				val = row.Row[i]
+			}
+			if i > 0 {
+				sb.WriteRune(',')
+			}
+			if err := writeJSONString(&sb, col); err != nil {
+				return err
+			}
+			sb.WriteRune(':')
+			if err := writeJSONString(&sb, val); err != nil {
+				return err
+			}
+		}
+		sb.WriteString("}\n")
+		if _, err := io.WriteString(w, sb.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeJSONString writes s as a JSON string, without the trailing newline or HTML escaping
+// json.Encoder would otherwise add.
+func writeJSONString(sb *strings.Builder, s string) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		return err
+	}
+	sb.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return nil
+}
+
+// JSON output is an array of the objects NDJSON would produce.
+func writeJSON(w io.Writer, r Results) error {
+	var buf bytes.Buffer
+	if err := writeNDJSON(&buf, r); err != nil {
+		return err
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if buf.Len() == 0 {
+		lines = nil
+	}
+	_, err := io.WriteString(w, "["+strings.Join(lines, ",")+"]\n")
+	return err
+}
+
+func writeCSV(w io.Writer, header []string, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil { // WriteAll flushes
+		return err
+	}
+	return cw.Error()
+}
+
+// tabs, newlines, and backslashes within fields are escaped so each record is exactly one line.
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+func writeTSV(w io.Writer, header []string, rows [][]string) error {
+	writeLine := func(fields []string) error {
+		escaped := make([]string, len(fields))
+		for i, f := range fields {
+			escaped[i] = tsvEscaper.Replace(f)
+		}
+		_, err := io.WriteString(w, strings.Join(escaped, "\t")+"\n")
+		return err
+	}
+
+	if err := writeLine(header); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		if err := writeLine(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var mdEscaper = strings.NewReplacer(`\`, `\\`, "|", `\|`, "\r\n", "<br>", "\n", "<br>")
+
+func writeMarkdown(w io.Writer, header []string, rows [][]string) error {
+	writeLine := func(fields []string) error {
+		escaped := make([]string, len(header))
+		for i := range header {
+			if i < len(fields) {
+				escaped[i] = mdEscaper.Replace(fields[i])
+			}
+		}
+		_, err := io.WriteString(w, "| "+strings.Join(escaped, " | ")+" |\n")
+		return err
+	}
+
+	if err := writeLine(header); err != nil {
+		return err
+	}
+	// delimiter row
+	if _, err := io.WriteString(w,
+		"|"+strings.Repeat(" --- |", len(header))+"\n"); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		if err := writeLine(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const htmlHead = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>gwcli results</title>
+<style>
+table { border-collapse: collapse; font-family: monospace; }
+th, td { border: 1px solid #9c7af7; padding: 2px 6px; text-align: left; vertical-align: top; white-space: pre-wrap; }
+th { background-color: #9c7af7; color: #ffffff; }
+tr:nth-child(even) { background-color: #f4effe; }
+</style>
+</head>
+<body>
+<table>
+`
+
+const htmlTail = `</table>
+</body>
+</html>
+`
+
+func writeHTML(w io.Writer, header []string, rows [][]string) error {
+	var sb strings.Builder
+	sb.WriteString(htmlHead)
+
+	sb.WriteString("<thead><tr>")
+	for _, h := range header {
+		sb.WriteString("<th>" + html.EscapeString(h) + "</th>")
+	}
+	sb.WriteString("</tr></thead>\n<tbody>\n")
+	for _, r := range rows {
+		sb.WriteString("<tr>")
+		for _, c := range r {
+			sb.WriteString("<td>" + html.EscapeString(c) + "</td>")
+		}
+		sb.WriteString("</tr>\n")
+	}
+	sb.WriteString("</tbody>\n")
+
+	sb.WriteString(htmlTail)
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+//#endregion transcoders
@@ -0,0 +1,221 @@
+package transcode
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gravwell/gravwell/v3/client/types"
+	"github.com/gravwell/gravwell/v3/ingest/entry"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    Format
+		wantErr bool
+	}{
+		{"empty", "", Raw, false},
+		{"raw", "raw", Raw, false},
+		{"JSON", "JSON", JSON, false},
+		{"csv", " csv ", CSV, false},
+		{"ndjson", "ndjson", NDJSON, false},
+		{"jsonl alias", "jsonl", NDJSON, false},
+		{"tsv", "tsv", TSV, false},
+		{"md alias", "md", Markdown, false},
+		{"html", "Html", HTML, false},
+		{"unknown", "xml", unknown, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFormat(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseFormat() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	ts := entry.FromStandard(time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC))
+	text := Results{
+		Tags: map[entry.EntryTag]string{1: "gravwell"},
+		Entries: []types.SearchEntry{
+			{TS: ts, SRC: net.ParseIP("10.0.0.1"), Tag: 1, Data: []byte("hello\tworld")},
+			{TS: ts, SRC: net.ParseIP("10.0.0.2"), Tag: 2, Data: []byte("a|b\nc")},
+		},
+	}
+	tbl := Results{
+		Columns: []string{"host", "count"},
+		Rows: []types.TableRow{
+			{Row: []string{"web<1>", "5"}},
+			{Row: []string{"db", "7"}},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		f       Format
+		r       Results
+		want    string
+		wantErr bool
+	}{
+		{"table JSON", JSON, tbl, "[{\"host\":\"web<1>\",\"count\":\"5\"},{\"host\":\"db\",\"count\":\"7\"}]\n", false},
+		{"table CSV", CSV, tbl, "host,count\nweb<1>,5\ndb,7\n", false},
+		{"text raw", Raw, text, "hello\tworld\na|b\nc\n", false},
+		{"unknown format", unknown, tbl, "", true},
+		{"table NDJSON", NDJSON, tbl,
+			"{\"host\":\"web<1>\",\"count\":\"5\"}\n{\"host\":\"db\",\"count\":\"7\"}\n", false},
+		{"text NDJSON", NDJSON, text,
+			`{"TS":"2024-07-01T12:00:00Z","Tag":"gravwell","Source":"10.0.0.1","Data":"hello\tworld"}` + "\n" +
+				`{"TS":"2024-07-01T12:00:00Z","Tag":"2","Source":"10.0.0.2","Data":"a|b\nc"}` + "\n", false},
+		{"table TSV", TSV, tbl, "host\tcount\nweb<1>\t5\ndb\t7\n", false},
+		{"text TSV", TSV, text,
+			"TS\tTag\tSource\tData\n" +
+				"2024-07-01T12:00:00Z\tgravwell\t10.0.0.1\thello\\tworld\n" +
+				"2024-07-01T12:00:00Z\t2\t10.0.0.2\ta|b\\nc\n", false},
+		{"text markdown", Markdown, text,
+			"| TS | Tag | Source | Data |\n" +
+				"| --- | --- | --- | --- |\n" +
+				"| 2024-07-01T12:00:00Z | gravwell | 10.0.0.1 | hello\tworld |\n" +
+				"| 2024-07-01T12:00:00Z | 2 | 10.0.0.2 | a\\|b<br>c |\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := Write(&buf, tt.f, tt.r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Write() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Write() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("table HTML", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Write(&buf, HTML, tbl); err != nil {
+			t.Fatal(err)
+		}
+		got := buf.String()
+		for _, want := range []string{"<th>host</th><th>count</th>", "<td>web&lt;1&gt;</td><td>5</td>"} {
+			if !strings.Contains(got, want) {
+				t.Errorf("Write() = %q, missing %q", got, want)
+			}
+		}
+	})
+}
+
+func TestResults_Project(t *testing.T) {
+	tbl := Results{
+		Columns: []string{"host", "user", "count"},
+		Rows:    []types.TableRow{{Row: []string{"web", "alice", "5"}}},
+	}
+	text := Results{Entries: []types.SearchEntry{
+		{Data: []byte(`{"b":1,"user":"alice","a":{"x":[1,2]}}`)},
+		{Data: []byte("not json")},
+	}}
+
+	tests := []struct {
+		name     string
+		r        Results
+		p        Projection
+		wantCols []string
+		wantRow  []string // first table row or each text entry's data
+	}{
+		{"empty projection", tbl, Projection{},
+			[]string{"host", "user", "count"}, []string{"web", "alice", "5"}},
+		{"include columns", tbl, Projection{Include: []string{"count", "host", "missing"}},
+			[]string{"host", "count"}, []string{"web", "5"}},
+		{"exclude columns", tbl, Projection{Exclude: []string{"user"}},
+			[]string{"host", "count"}, []string{"web", "5"}},
+		{"include and exclude columns", tbl,
+			Projection{Include: []string{"host", "user"}, Exclude: []string{"user"}},
+			[]string{"host"}, []string{"web"}},
+		{"exclude keys", text, Projection{Exclude: []string{"user"}},
+			nil, []string{`{"b":1,"a":{"x":[1,2]}}`, "not json"}},
+		{"include keys", text, Projection{Include: []string{"a"}},
+			nil, []string{`{"a":{"x":[1,2]}}`, "not json"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.r.Project(tt.p)
+			if tt.r.Table() {
+				if strings.Join(got.Columns, ",") != strings.Join(tt.wantCols, ",") {
+					t.Errorf("Project() columns = %v, want %v", got.Columns, tt.wantCols)
+				}
+				if strings.Join(got.Rows[0].Row, ",") != strings.Join(tt.wantRow, ",") {
+					t.Errorf("Project() row = %v, want %v", got.Rows[0].Row, tt.wantRow)
+				}
+				return
+			}
+			for i, e := range got.Entries {
+				if string(e.Data) != tt.wantRow[i] {
+					t.Errorf("Project() entry %d = %s, want %s", i, e.Data, tt.wantRow[i])
+				}
+			}
+		})
+	}
+	// ensure the original was not altered
+	if len(tbl.Columns) != 3 || string(text.Entries[0].Data) != `{"b":1,"user":"alice","a":{"x":[1,2]}}` {
+		t.Error("Project() modified the original results")
+	}
+}
+
+func TestResults_Unmatched(t *testing.T) {
+	tbl := Results{Columns: []string{"host", "user"}}
+	text := Results{Entries: []types.SearchEntry{
+		{Data: []byte(`{"user":"alice"}`)},
+		{Data: []byte(`{"host":"web"}`)},
+		{Data: []byte("not json")},
+	}}
+	tests := []struct {
+		name string
+		r    Results
+		p    Projection
+		want []string
+	}{
+		{"empty projection", tbl, Projection{}, nil},
+		{"all columns match", tbl, Projection{Include: []string{"host"}, Exclude: []string{"user"}}, nil},
+		{"unknown columns", tbl,
+			Projection{Include: []string{"hots", "host", "hots"}, Exclude: []string{"usr"}},
+			[]string{"hots", "usr"}},
+		{"keys across entries", text, Projection{Include: []string{"user", "host", "nope"}},
+			[]string{"nope"}},
+		{"no JSON entries", Results{Entries: []types.SearchEntry{{Data: []byte("x")}}},
+			Projection{Exclude: []string{"x"}}, []string{"x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.r.Unmatched(tt.p); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Unmatched() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResults_Arrange(t *testing.T) {
+	tbl := Results{
+		Columns: []string{"host", "user", "count"},
+		Rows:    []types.TableRow{{Row: []string{"web", "alice", "5"}}, {Row: []string{"db"}}},
+	}
+	got := tbl.Arrange([]int{2, 0})
+	if want := "count,host"; strings.Join(got.Columns, ",") != want {
+		t.Errorf("Arrange() columns = %v, want %v", got.Columns, want)
+	}
+	for i, want := range []string{"5,web", ",db"} {
+		if row := strings.Join(got.Rows[i].Row, ","); row != want {
+			t.Errorf("Arrange() row %d = %v, want %v", i, row, want)
+		}
+	}
+	if strings.Join(tbl.Rows[0].Row, ",") != "web,alice,5" {
+		t.Error("Arrange() modified the original results")
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"gwcli/clilog"
 	"gwcli/connection"
 	"gwcli/group"
+	"gwcli/mother"
+	"gwcli/stylesheet"
 	"gwcli/tree/dashboards"
 	"gwcli/tree/extractors"
 	"gwcli/tree/kits"
@@ -18,15 +20,20 @@ import (
 	"gwcli/tree/queries"
 	"gwcli/tree/query"
 	"gwcli/tree/resources"
+	"gwcli/tree/run"
 	"gwcli/tree/tree"
 	"gwcli/tree/user"
+	"gwcli/utilities/alias"
 	"gwcli/utilities/cfgdir"
 	"gwcli/utilities/treeutils"
 	"gwcli/utilities/usage"
+	"os"
 	"strings"
 	"time"
 
+	"github.com/google/shlex"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // global PersistenPreRunE.
@@ -52,6 +59,11 @@ func ppre(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// style all subsequent output per the user's theme and color preference
+	if err := applyTheme(cmd); err != nil {
+		return err
+	}
+
 	// if this is a 'help' action, do not enforce login
 	if cmd.Name() == "help" {
 		return nil
@@ -60,6 +72,25 @@ func ppre(cmd *cobra.Command, args []string) error {
 	return EnforceLogin(cmd, args)
 }
 
+// Applies the theme selected by --theme, disabling color entirely if --no-color was given.
+func applyTheme(cmd *cobra.Command) error {
+	if noColor, err := cmd.Flags().GetBool("no-color"); err != nil {
+		return err
+	} else if noColor {
+		stylesheet.DisableColor()
+	}
+	name, err := cmd.Flags().GetString("theme")
+	if err != nil {
+		return err
+	}
+	t, err := stylesheet.LoadTheme(name)
+	if err != nil {
+		return err
+	}
+	stylesheet.Apply(t)
+	return nil
+}
+
 // Logs the client into the Gravwell instance dictated by the --server flag.
 // Safe (ineffectual) to call if already logged in.
 func EnforceLogin(cmd *cobra.Command, args []string) error {
@@ -123,12 +154,19 @@ func GenerateFlags(root *cobra.Command) {
 	root.PersistentFlags().StringP("username", "u", "", "login credential.")
 	root.PersistentFlags().String("password", "", "login credential.")
 	root.PersistentFlags().StringP("passfile", "p", "", "the path to a file containing your password")
-	root.PersistentFlags().Bool("no-color", false, "disables colourized output.")
+	root.PersistentFlags().Bool("no-color", false, "disables colourized output.\n"+
+		"Also disabled by setting the NO_COLOR environment variable.")
+	root.PersistentFlags().String("theme", stylesheet.DefaultTheme, "color theme.\n"+
+		"Built-in themes: "+strings.Join(stylesheet.ThemeNames(), ", ")+".\n"+
+		"Custom themes are read from "+cfgdir.DefaultThemesPath+".\n")
 	root.PersistentFlags().String("server", "localhost:80", "<host>:<port> of instance to connect to.\n")
 	root.PersistentFlags().StringP("log", "l", cfgdir.DefaultStdLogPath, "log location for developer logs.\n")
 	root.PersistentFlags().String("loglevel", "DEBUG", "log level for developer logs (-l).\n"+
 		"Possible values: 'OFF', 'DEBUG', 'INFO', 'WARN', 'ERROR', 'CRITICAL', 'FATAL'.\n")
 	root.PersistentFlags().Bool("insecure", false, "do not use HTTPS and do not enforce certs.")
+	root.PersistentFlags().Uint16("history-size", 1000,
+		"number of interactive commands to retain in the history file.\n"+
+			"0 disables the history file.\n")
 }
 
 const ( // usage
@@ -149,6 +187,60 @@ const ( // mousetrap
 	mousetrapDuration time.Duration = (0 * time.Second)
 )
 
+// If the first positional argument is an alias, returns the arguments with the alias expanded in
+// place, so that aliases behave the same as they do in Mother. An alias that expands to a chain of
+// commands is instead run as a line of a script (which expands and roots the alias in each command
+// of the chain, as Mother does).
+// Otherwise, returns the arguments unaltered.
+func expandAlias(root *cobra.Command, args []string) []string {
+	aliases, err := alias.Load()
+	if err != nil || len(aliases) == 0 {
+		return args // the logger is not yet available; Mother will report the failure
+	}
+	for i := 0; i < len(args); i++ {
+		if !strings.HasPrefix(args[i], "-") { // first positional argument
+			expanded, ok := alias.Expand(aliases, args[i])
+			if !ok {
+				return args
+			}
+			quoted := make([]string, len(args)-i)
+			for j, a := range args[i:] {
+				quoted[j] = quoteArg(a)
+			}
+			if mother.IsChain(root, strings.Join(append([]string{expanded}, quoted[1:]...), " "), aliases) {
+				return append(append([]string{}, args[:i]...),
+					"run", "--"+run.CommandFlag, strings.Join(quoted, " "))
+			}
+			tokens, err := shlex.Split(expanded)
+			if err != nil {
+				return args
+			}
+			return append(append(append([]string{}, args[:i]...), tokens...), args[i+1:]...)
+		}
+		// skip the value of a flag given as `--flag value`
+		if !strings.Contains(args[i], "=") {
+			var f *pflag.Flag
+			if name, long := strings.CutPrefix(args[i], "--"); long {
+				f = root.PersistentFlags().Lookup(name)
+			} else if sh := strings.TrimPrefix(args[i], "-"); len(sh) == 1 {
+				f = root.PersistentFlags().ShorthandLookup(sh)
+			}
+			if f != nil && f.NoOptDefVal == "" {
+				i++
+			}
+		}
+	}
+	return args
+}
+
+// Quotes the argument, if need be, so it is a single, literal token of a line of Mother's.
+func quoteArg(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\n'\"\\;&|<>$") {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'"'"'`) + "'"
+}
+
 // Execute adds all child commands to the root command, sets flags appropriately, and launches the
 // program according to the given parameters
 // (via cobra.Command.Execute()).
@@ -166,6 +258,7 @@ func Execute(args []string) int {
 		[]action.Pair{
 			query.NewQueryAction(),
 			tree.NewTreeAction(),
+			run.NewRunAction(),
 		})
 	rootCmd.SilenceUsage = true
 	rootCmd.PersistentPreRunE = ppre
@@ -191,9 +284,10 @@ func Execute(args []string) int {
 
 	// if args were given (ex: we are in testing mode)
 	// use those instead of os.Args
-	if args != nil {
-		rootCmd.SetArgs(args)
+	if args == nil {
+		args = os.Args[1:]
 	}
+	rootCmd.SetArgs(expandAlias(rootCmd, args))
 
 	rootCmd.SetUsageFunc(usage.Usage)
 
@@ -0,0 +1,149 @@
+/**
+ * Run executes a script: a file of gwcli commands, one per line, as they would be entered at
+ * Mother's prompt, or a single such line given by --command (as aliases expanding to a chain are
+ * run). See mother.RunScript for the script syntax.
+ * Mother's `source` builtin is its interactive sibling, executing from Mother's current location
+ * rather than from root.
+ */
+package run
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"gwcli/action"
+	"gwcli/mother"
+	"gwcli/stylesheet"
+	"gwcli/utilities/scaffold"
+	"io"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	use   string = "run"
+	short string = "execute a script of commands"
+	long  string = "Executes a file of gwcli commands, one per line, as they would be entered " +
+		"interactively, starting from root.\n" +
+		"Lines beginning with # are comments. `set VAR=value` defines a variable, which " +
+		"subsequent lines can reference as ${VAR}; environment variables can be referenced the " +
+		"same way.\n" +
+		"Each action is run in script mode. The status of each line is reported as it completes " +
+		"and execution stops at the first failure, unless --continue-on-error is given.\n" +
+		"Alternatively, --command executes a single line, which may chain commands.\n" +
+		"Exits non-zero if any command failed."
+
+	continueFlag = "continue-on-error"
+	// CommandFlag executes the given line in place of a script file.
+	CommandFlag = "command"
+)
+
+var aliases []string = []string{}
+
+func NewRunAction() action.Pair {
+	p := scaffold.NewBasicAction(use, short, long, aliases,
+		func(c *cobra.Command, fs *pflag.FlagSet) (string, tea.Cmd) {
+			inv, err := parse(fs)
+			if err != nil {
+				return stylesheet.ErrStyle.Render(err.Error()), nil
+			}
+			// execute off of Mother's Update loop, so she remains responsive for the script's duration
+			root := c.Root()
+			return "", func() tea.Msg {
+				var out bytes.Buffer // interleave output and statuses
+				if err := inv.execute(root, &out, &out); err != nil {
+					out.WriteString(stylesheet.ErrStyle.Render(err.Error()))
+				}
+				return tea.Println(strings.TrimSuffix(out.String(), "\n"))()
+			}
+		}, flags)
+
+	// supplant the basic action's Run, so failures are reflected in the exit code
+	p.Action.Run = nil
+	p.Action.RunE = func(c *cobra.Command, _ []string) error {
+		inv, err := parse(c.Flags())
+		if err != nil {
+			return err
+		}
+		return inv.execute(c.Root(), c.OutOrStdout(), c.ErrOrStderr())
+	}
+	p.Action.Example = "gwcli run setup.gw --" + continueFlag
+	// scripts cannot run scripts
+	p.Action.Annotations = map[string]string{mother.NonScriptableAnnotation: ""}
+
+	return p
+}
+
+func flags() pflag.FlagSet {
+	fs := pflag.FlagSet{}
+	fs.Bool(continueFlag, false, "execute the remaining lines after a line fails")
+	fs.StringP(CommandFlag, "c", "", "execute the given line rather than a script file.\n"+
+		"Ex: --"+CommandFlag+" 'macros list --json > macros.json && kits list'")
+	return fs
+}
+
+// invocation is a validated request to execute a script file or a --command line.
+type invocation struct {
+	continueOnError bool
+	line            string // the --command line; only used if path is empty
+	path            string // the script file
+}
+
+// Reads the script named by the sole argument in fs or, if given, the --command line.
+// The result does not reference fs, so it can be executed after fs is reset.
+func parse(fs *pflag.FlagSet) (invocation, error) {
+	var (
+		inv invocation
+		err error
+	)
+	if inv.continueOnError, err = fs.GetBool(continueFlag); err != nil {
+		return inv, err
+	}
+	if inv.line, err = fs.GetString(CommandFlag); err != nil {
+		return inv, err
+	}
+
+	if fs.Changed(CommandFlag) {
+		if fs.NArg() != 0 {
+			return inv, errors.New("expected a script file or --" + CommandFlag + ", not both")
+		}
+		return inv, nil
+	}
+	if fs.NArg() != 1 {
+		return inv, errors.New("expected exactly one script file")
+	}
+	inv.path = fs.Arg(0)
+	return inv, nil
+}
+
+// Executes the script or line from root.
+func (inv invocation) execute(root *cobra.Command, stdout, stderr io.Writer) error {
+	opts := mother.ScriptOptions{Stdout: stdout, Stderr: stderr, ContinueOnError: inv.continueOnError}
+
+	if inv.path == "" {
+		failed, err := mother.RunScript(root, "--"+CommandFlag, strings.NewReader(inv.line), opts)
+		if err != nil {
+			return err
+		} else if failed > 0 {
+			return errors.New("the command failed")
+		}
+		return nil
+	}
+
+	f, err := os.Open(inv.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	failed, err := mother.RunScript(root, inv.path, f, opts)
+	if err != nil {
+		return fmt.Errorf("failed to read %v: %w", inv.path, err)
+	} else if failed > 0 {
+		return fmt.Errorf("%d command(s) failed", failed)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+/*
+Alias manages user-defined aliases: names that expand to longer command lines, such as
+`sq` -> `queries scheduled list --all`.
+
+Aliases are persisted to the aliases file in the config directory (see cfgdir.DefaultAliasesPath),
+one per line in the form name=expansion. Blank lines and lines beginning with # are ignored, so the
+file can be hand-edited and shared.
+
+Expansions are rooted: they are resolved from the root of the command tree, no matter where the
+alias is invoked, so an alias behaves the same in Mother and on the command line.
+*/
+package alias
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"gwcli/utilities/cfgdir"
+	"os"
+	"slices"
+	"strings"
+)
+
+const aliasFilePerm = 0600
+
+// file aliases are persisted to; a variable so tests can redirect it
+var path = cfgdir.DefaultAliasesPath
+
+// names that cannot be aliased, lest the user be unable to undo the alias
+var reserved = []string{"alias", "unalias"}
+
+// Load reads every alias from the aliases file, keyed by name.
+// A missing file is not an error.
+func Load() (map[string]string, error) {
+	aliases := make(map[string]string)
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return aliases, nil
+		}
+		return aliases, err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if name, expansion, err := Parse(line); err == nil {
+			aliases[name] = expansion
+		}
+	}
+	return aliases, sc.Err()
+}
+
+// Parse splits a definition of the form name=expansion, stripping quotes that enclose the
+// expansion.
+func Parse(def string) (name, expansion string, err error) {
+	name, expansion, found := strings.Cut(def, "=")
+	if !found {
+		return "", "", fmt.Errorf("'%v' is not of the form name=expansion", def)
+	}
+	name, expansion = strings.TrimSpace(name), strings.TrimSpace(expansion)
+	if len(expansion) >= 2 && (expansion[0] == '"' || expansion[0] == '\'') &&
+		expansion[len(expansion)-1] == expansion[0] {
+		expansion = expansion[1 : len(expansion)-1]
+	}
+	if err := validate(name); err != nil {
+		return "", "", err
+	}
+	if strings.TrimSpace(expansion) == "" {
+		return "", "", fmt.Errorf("alias '%v' must expand to a command", name)
+	}
+	return name, expansion, nil
+}
+
+// Returns an error if the name cannot be used as an alias.
+func validate(name string) error {
+	switch {
+	case name == "":
+		return errors.New("alias name cannot be empty")
+	case strings.ContainsAny(name, " \t=\"'"):
+		return fmt.Errorf("alias name '%v' cannot contain whitespace, quotes, or '='", name)
+	case strings.HasPrefix(name, "-"):
+		return fmt.Errorf("alias name '%v' cannot begin with '-'", name)
+	case slices.Contains(reserved, name):
+		return fmt.Errorf("'%v' cannot be aliased", name)
+	}
+	return nil
+}
+
+// Set defines (or redefines) an alias and persists it.
+func Set(name, expansion string) error {
+	if err := validate(name); err != nil {
+		return err
+	}
+	aliases, err := Load()
+	if err != nil {
+		return err
+	}
+	aliases[name] = expansion
+	return save(aliases)
+}
+
+// Unset removes an alias, returning whether it existed.
+func Unset(name string) (bool, error) {
+	aliases, err := Load()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := aliases[name]; !ok {
+		return false, nil
+	}
+	delete(aliases, name)
+	return true, save(aliases)
+}
+
+// Rewrites the aliases file to contain exactly the given aliases, sorted by name.
+func save(aliases map[string]string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, aliasFilePerm)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	for _, name := range Names(aliases) {
+		if _, err := fmt.Fprintf(w, "%v=%v\n", name, aliases[name]); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// Names returns the names of the given aliases, sorted.
+func Names(aliases map[string]string) []string {
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Expand replaces the first word of the given input if it is an alias, returning whether it was.
+// An expansion that begins with another alias is expanded in turn; an alias is never expanded
+// twice, so aliases may refer to the command they shadow (ex: list=list --all).
+func Expand(aliases map[string]string, input string) (expanded string, ok bool) {
+	seen := make(map[string]bool)
+	for {
+		trimmed := strings.TrimLeft(input, " ")
+		first, rest, _ := strings.Cut(trimmed, " ")
+		expansion, isAlias := aliases[first]
+		if !isAlias || seen[first] {
+			return input, ok
+		}
+		seen[first], ok = true, true
+		input = expansion
+		if rest != "" {
+			input += " " + rest
+		}
+	}
+}
+
+// Path returns the path of the aliases file.
+func Path() string {
+	return path
+}
@@ -0,0 +1,103 @@
+package alias
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		def           string
+		wantName      string
+		wantExpansion string
+		wantErr       bool
+	}{
+		{`sq="queries scheduled list --all"`, "sq", "queries scheduled list --all", false},
+		{`sq='queries scheduled list'`, "sq", "queries scheduled list", false},
+		{`ql=query "tag=gravwell" -o out.txt`, "ql", `query "tag=gravwell" -o out.txt`, false},
+		{` k = kits list `, "k", "kits list", false},
+		{`sq`, "", "", true},
+		{`=kits list`, "", "", true},
+		{`s q=kits list`, "", "", true},
+		{`-k=kits list`, "", "", true},
+		{`alias=kits list`, "", "", true},
+		{`k=""`, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.def, func(t *testing.T) {
+			name, expansion, err := Parse(tt.def)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if name != tt.wantName || expansion != tt.wantExpansion {
+				t.Errorf("Parse() = (%q, %q), want (%q, %q)",
+					name, expansion, tt.wantName, tt.wantExpansion)
+			}
+		})
+	}
+}
+
+func TestExpand(t *testing.T) {
+	aliases := map[string]string{
+		"sq":   "queries scheduled list --all",
+		"sqj":  "sq --json",
+		"list": "list --all",
+		"a":    "b",
+		"b":    "a",
+	}
+	tests := []struct {
+		input  string
+		want   string
+		wantOk bool
+	}{
+		{"sq", "queries scheduled list --all", true},
+		{"sq --csv", "queries scheduled list --all --csv", true},
+		{"sqj -o out.json", "queries scheduled list --all --json -o out.json", true},
+		{"list", "list --all", true},
+		{"a", "a", true},
+		{"queries sq", "queries sq", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := Expand(aliases, tt.input)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("Expand() = (%q, %v), want (%q, %v)", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestSetUnset(t *testing.T) {
+	path = filepath.Join(t.TempDir(), "aliases")
+	if err := os.WriteFile(path, []byte("# team aliases\nk=kits list\n\nbogus\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Set("sq", "queries scheduled list --all"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Set("unalias", "kits list"); err == nil {
+		t.Error("expected reserved name to be rejected")
+	}
+	got, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"k": "kits list", "sq": "queries scheduled list --all"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load() = %v, want %v", got, want)
+	}
+
+	if found, err := Unset("k"); err != nil || !found {
+		t.Errorf("Unset(k) = (%v, %v), want (true, nil)", found, err)
+	}
+	if found, err := Unset("k"); err != nil || found {
+		t.Errorf("second Unset(k) = (%v, %v), want (false, nil)", found, err)
+	}
+	if got, _ := Load(); !reflect.DeepEqual(got, map[string]string{"sq": "queries scheduled list --all"}) {
+		t.Errorf("Load() after Unset = %v", got)
+	}
+}
@@ -11,6 +11,13 @@ const (
 	tokenName   string = "token"
 	restLogName string = "rest.log"
 	stdLogName  string = "dev.log"
+	historyName string = "history"
+	layoutsName string = "table_layouts.json"
+	pivotsName  string = "pivots"
+	keymapName  string = "keymap.json"
+	themesName  string = "themes"
+	aliasesName string = "aliases"
+	statusName  string = "statusline.json"
 )
 
 // all persistent data is stored in $os.UserConfigDir/gwcli/
@@ -20,6 +27,13 @@ var ( // set by init
 	DefaultRestLogPath string
 	DefaultStdLogPath  string
 	DefaultTokenPath   string
+	DefaultHistoryPath string
+	DefaultLayoutsPath string // DataScope table column layouts
+	DefaultPivotsPath  string // DataScope pivot templates
+	DefaultKeymapPath  string // key binding overrides
+	DefaultThemesPath  string // directory of custom themes
+	DefaultAliasesPath string // user-defined command aliases
+	DefaultStatusPath  string // Mother's status line configuration
 )
 
 // on startup, identify and cache the config directory
@@ -44,4 +58,11 @@ func init() {
 	DefaultRestLogPath = path.Join(cfgDir, restLogName)
 	DefaultStdLogPath = path.Join(cfgDir, stdLogName)
 	DefaultTokenPath = path.Join(cfgDir, tokenName)
+	DefaultHistoryPath = path.Join(cfgDir, historyName)
+	DefaultLayoutsPath = path.Join(cfgDir, layoutsName)
+	DefaultPivotsPath = path.Join(cfgDir, pivotsName)
+	DefaultKeymapPath = path.Join(cfgDir, keymapName)
+	DefaultThemesPath = path.Join(cfgDir, themesName)
+	DefaultAliasesPath = path.Join(cfgDir, aliasesName)
+	DefaultStatusPath = path.Join(cfgDir, statusName)
 }
@@ -0,0 +1,199 @@
+/*
+Keymap holds the key bindings of gwcli's interactive components (Mother, DataScope, the query
+editor, the scaffolds, ...), allowing the user to override any of them by action name.
+
+Components declare each binding via Bind, supplying its default keys.
+If the user's keymap file (see cfgdir.DefaultKeymapPath) overrides the action, the user's keys are
+used instead. Because overrides are read when this package is initialized, package-level bindings
+in other packages observe them.
+
+The keymap file is a JSON object of action names to arrays of keys, in Bubble Tea's notation:
+
+	{
+		"query.editor.submit": ["ctrl+s"],
+		"datascope.records.search": ["/", "ctrl+f"]
+	}
+
+Every action, and its keys, is listed by Mother's `keys` builtin.
+
+Validate should be called once all bindings have been declared, to report unknown actions and keys
+bound to multiple actions that could be active at the same time.
+*/
+package keymap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gwcli/utilities/cfgdir"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// Scope is a set of bindings that are active at the same time, such as those of a single view.
+// A scope's bindings are also active alongside those of its ancestors; two bindings conflict if
+// they share a key and one's scope is the other's or an ancestor of it.
+type Scope struct {
+	name   string
+	parent *Scope
+}
+
+// Global contains the bindings that are always active (such as the kill keys).
+// It is the ancestor of every other scope.
+var Global = &Scope{name: "global"}
+
+// Returns a new scope, active alongside its parent.
+// The scope's name prefixes the names of all actions bound within it.
+func NewScope(name string, parent *Scope) *Scope {
+	if parent != nil && parent != Global {
+		name = parent.name + "." + name
+	}
+	return &Scope{name: name, parent: parent}
+}
+
+// Returns whether the scope is a or an ancestor of a.
+func (sc *Scope) encloses(a *Scope) bool {
+	for ; a != nil; a = a.parent {
+		if a == sc {
+			return true
+		}
+	}
+	return false
+}
+
+// action is a declared binding.
+type action struct {
+	name     string
+	scope    *Scope
+	help     string
+	defaults []string
+	keys     []string // the keys in use (defaults, unless overridden)
+}
+
+var (
+	path      = cfgdir.DefaultKeymapPath
+	overrides map[string][]string // action name -> keys, as read from the keymap file
+	loadErr   error               // error encountered reading the keymap file
+	actions   []*action           // declared bindings, in declaration order
+)
+
+func init() {
+	overrides, loadErr = load(path)
+}
+
+// Reads the keymap file at the given path. A nonexistent file is not an error.
+func load(path string) (map[string][]string, error) {
+	m := make(map[string][]string)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return m, nil
+		}
+		return m, err
+	}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return make(map[string][]string), fmt.Errorf("failed to parse %v: %w", path, err)
+	}
+	for name, keys := range m {
+		for i, k := range keys {
+			if k == "space" { // Bubble Tea represents the space bar as a literal space
+				keys[i] = " "
+			}
+		}
+		m[name] = keys
+	}
+	return m, nil
+}
+
+// Bind declares an action within the given scope and returns its binding, using the user's keys if
+// they overrode the action and the given keys otherwise.
+// The binding's help displays its keys and the given description.
+func Bind(scope *Scope, name, help string, defaults ...string) key.Binding {
+	a := &action{name: scope.name + "." + name, scope: scope, help: help, defaults: defaults,
+		keys: defaults}
+	if keys, ok := overrides[a.name]; ok {
+		a.keys = keys
+	}
+	actions = append(actions, a)
+
+	return key.NewBinding(key.WithKeys(a.keys...), key.WithHelp(Display(a.keys...), help))
+}
+
+// Returns the given keys joined for display in help text.
+func Display(keys ...string) string {
+	display := make([]string, len(keys))
+	for i, k := range keys {
+		if k == " " {
+			k = "space"
+		}
+		display[i] = k
+	}
+	return strings.Join(display, "/")
+}
+
+// Returns the binding's keys joined for display in help text.
+func Keys(b key.Binding) string {
+	return Display(b.Keys()...)
+}
+
+// Validate checks the keymap file against the declared bindings, returning an error describing
+// each unknown action, action without keys, and key bound to conflicting actions.
+func Validate() error {
+	var errs []error
+	if loadErr != nil {
+		errs = append(errs, loadErr)
+	}
+
+	declared := make(map[string]bool, len(actions))
+	for _, a := range actions {
+		declared[a.name] = true
+	}
+	var names []string
+	for name := range overrides {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if !declared[name] {
+			errs = append(errs, fmt.Errorf("unknown action %q", name))
+		} else if len(overrides[name]) == 0 {
+			errs = append(errs, fmt.Errorf("action %q must have at least one key", name))
+		}
+	}
+
+	for i, a := range actions {
+		for _, b := range actions[i+1:] {
+			if a.name == b.name || !(a.scope.encloses(b.scope) || b.scope.encloses(a.scope)) {
+				continue
+			}
+			for _, k := range a.keys {
+				if slices.Contains(b.keys, k) {
+					errs = append(errs, fmt.Errorf("%q is bound to both %q and %q",
+						Display(k), a.name, b.name))
+				}
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid keymap (%v):\n%w", path, errors.Join(errs...))
+	}
+	return nil
+}
+
+// Returns the name, keys, and description of every declared action, in declaration order.
+func Actions() [][3]string {
+	out := make([][3]string, len(actions))
+	for i, a := range actions {
+		out[i] = [3]string{a.name, Display(a.keys...), a.help}
+	}
+	return out
+}
+
+// Returns the path of the keymap file.
+func Path() string {
+	return path
+}
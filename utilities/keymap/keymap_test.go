@@ -0,0 +1,116 @@
+package keymap
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func Test_load(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string // if empty, the file is not created
+		want    map[string][]string
+		wantErr bool
+	}{
+		{"nonexistent", "", map[string][]string{}, false},
+		{"overrides", `{"query.editor.submit":["ctrl+s"],"datascope.records.select":["space","x"]}`,
+			map[string][]string{"query.editor.submit": {"ctrl+s"}, "datascope.records.select": {" ", "x"}},
+			false},
+		{"malformed", `{"query.editor.submit":"ctrl+s"}`, map[string][]string{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := filepath.Join(dir, tt.name+".json")
+			if tt.content != "" {
+				if err := os.WriteFile(p, []byte(tt.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := load(p)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("load() = %v, want %v", got, tt.want)
+			}
+			for name, keys := range tt.want {
+				if !slices.Equal(got[name], keys) {
+					t.Errorf("load()[%q] = %q, want %q", name, got[name], keys)
+				}
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	priorActions, priorOverrides := actions, overrides
+	t.Cleanup(func() { actions, overrides = priorActions, priorOverrides })
+
+	tests := []struct {
+		name      string
+		overrides map[string][]string
+		wantErrs  []string // substrings of the expected error; none if nil
+	}{
+		{"defaults", nil, nil},
+		{"rebound", map[string][]string{"a.b.two": {"y"}}, nil},
+		{"sibling scopes may share keys", map[string][]string{"a.b.two": {"z"}}, nil},
+		{"conflict with ancestor", map[string][]string{"a.b.two": {"x"}},
+			[]string{`"x" is bound to both "a.one" and "a.b.two"`}},
+		{"conflict with global", map[string][]string{"a.one": {"esc"}},
+			[]string{`"esc" is bound to both "global.quit" and "a.one"`}},
+		{"unknown action", map[string][]string{"a.three": {"y"}}, []string{`unknown action "a.three"`}},
+		{"no keys", map[string][]string{"a.one": {}}, []string{`"a.one" must have at least one key`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actions, overrides = nil, tt.overrides
+			var (
+				a = NewScope("a", Global)
+				b = NewScope("b", a)
+				c = NewScope("c", a)
+			)
+			Bind(Global, "quit", "quit", "esc")
+			Bind(a, "one", "one", "x")
+			Bind(b, "two", "two", "enter")
+			Bind(c, "three", "three", "z")
+
+			err := Validate()
+			if tt.wantErrs == nil {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want none", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() returned no error, want %v", tt.wantErrs)
+			}
+			for _, want := range tt.wantErrs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate() error = %v, want it to contain %v", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBind(t *testing.T) {
+	priorActions, priorOverrides := actions, overrides
+	t.Cleanup(func() { actions, overrides = priorActions, priorOverrides })
+	actions, overrides = nil, map[string][]string{"s.toggle": {" ", "t"}}
+
+	sc := NewScope("s", Global)
+	toggle := Bind(sc, "toggle", "toggle", "enter")
+	if got := toggle.Keys(); !slices.Equal(got, []string{" ", "t"}) {
+		t.Errorf("overridden keys = %q", got)
+	}
+	if got := toggle.Help().Key; got != "space/t" {
+		t.Errorf("overridden help key = %q, want %q", got, "space/t")
+	}
+	if got := Keys(Bind(sc, "other", "other", "o", "O")); got != "o/O" {
+		t.Errorf("default keys = %q, want %q", got, "o/O")
+	}
+}
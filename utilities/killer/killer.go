@@ -2,7 +2,12 @@
 // Used by Mother and interactive models Cobra spins up outside of Mother.
 package killer
 
-import tea "github.com/charmbracelet/bubbletea"
+import (
+	"gwcli/utilities/keymap"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
 
 type Kill = uint
 
@@ -12,11 +17,13 @@ const (
 	Child
 )
 
-// keys kill the program in Update no matter its other states
-var globalKillKeys = [...]tea.KeyType{tea.KeyCtrlC}
+// GlobalKill kills the program in Update no matter its other states
+var GlobalKill = keymap.Bind(keymap.Global, "kill", "quit",
+	tea.KeyCtrlC.String())
 
-// keys that kill the child if it exists, otherwise do nothing
-var childOnlykillKeys = [...]tea.KeyType{tea.KeyEscape}
+// ChildKill kills the child if it exists, otherwise does nothing
+var ChildKill = keymap.Bind(keymap.Global, "kill-child", "quit the current action",
+	tea.KeyEscape.String())
 
 // given a message, returns if it is a global kill, a child kill, or not a kill
 func CheckKillKeys(msg tea.Msg) Kill {
@@ -25,17 +32,11 @@ func CheckKillKeys(msg tea.Msg) Kill {
 		return None
 	}
 
-	// check global keys
-	for _, kKey := range globalKillKeys {
-		if keyMsg.Type == kKey {
-			return Global
-		}
+	if key.Matches(keyMsg, GlobalKill) {
+		return Global
 	}
-
-	for _, kKey := range childOnlykillKeys {
-		if keyMsg.Type == kKey {
-			return Child
-		}
+	if key.Matches(keyMsg, ChildKill) {
+		return Child
 	}
 
 	return None
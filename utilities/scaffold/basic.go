@@ -35,6 +35,8 @@ import (
 // Creates a new Basic action fully featured for Cobra and Mother usage.
 // The given act func will be executed when the action is triggered and its result printed to the
 // screen.
+// If act returns an empty string, nothing is printed; long-running work can instead be returned
+// as a tea.Cmd that prints its own result, keeping Mother responsive.
 //
 // NOTE: The tea.Cmd returned by act will be thrown away if run in a Cobra context.
 func NewBasicAction(use, short, long string, aliases []string,
@@ -83,6 +85,9 @@ var _ action.Model = &BasicAction{}
 func (ba *BasicAction) Update(msg tea.Msg) tea.Cmd {
 	ba.done = true
 	s, cmd := ba.fn(ba.cmd, &ba.fs)
+	if s == "" { // nothing to print (yet); cmd may print once it completes
+		return cmd
+	}
 	return tea.Sequence(tea.Println(s), cmd)
 }
 
@@ -0,0 +1,89 @@
+package scaffold
+
+/**
+ * Helpers for the flag completion functions scaffolds register on their commands.
+ * Completion functions are called by Mother's prompt completion and by Cobra's shell completion
+ * (the hidden __complete command). The latter occurs prior to, and in lieu of, the usual login.
+ */
+
+import (
+	"fmt"
+	"gwcli/clilog"
+	"gwcli/connection"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// CompletionLogin ensures the client is logged in, so completion functions can fetch live data.
+// As shell completion can neither prompt for credentials nor report errors, only the user's login
+// token is tried.
+// Returns false if the client could not be logged in.
+func CompletionLogin(cmd *cobra.Command) bool {
+	if connection.Client == nil {
+		server, err := cmd.Flags().GetString("server")
+		if err != nil {
+			clilog.Writer.Warnf("completion: %v", err)
+			return false
+		}
+		insecure, err := cmd.Flags().GetBool("insecure")
+		if err != nil {
+			clilog.Writer.Warnf("completion: %v", err)
+			return false
+		}
+		if err := connection.Initialize(server, !insecure, insecure, ""); err != nil {
+			clilog.Writer.Warnf("completion: failed to initialize connection: %v", err)
+			return false
+		}
+	}
+	if connection.Client.LoggedIn() {
+		return true
+	}
+	if err := connection.LoginViaToken(); err != nil {
+		clilog.Writer.Infof("completion: failed to login via token: %v", err)
+		return false
+	}
+	var err error
+	if connection.MyInfo, err = connection.Client.MyInfo(); err != nil {
+		clilog.Writer.Warnf("completion: failed to cache user info: %v", err)
+		return false
+	}
+	return true
+}
+
+// IDCompletion returns a completion function offering the id of each fetched item, described by
+// its title. Singular names the items in logs.
+func IDCompletion[T any](singular string, fetch func() ([]T, error), id, title func(T) string,
+) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(c *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		if !CompletionLogin(c) {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		items, err := fetch()
+		if err != nil {
+			clilog.Writer.Warnf("failed to complete %v ids: %v", singular, err)
+			return nil, cobra.ShellCompDirectiveError
+		}
+		ids := make([]string, len(items))
+		for i, itm := range items {
+			ids[i] = fmt.Sprintf("%v\t%v", id(itm), title(itm))
+		}
+		return ids, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// CompleteList returns completions for the final element of a comma-separated list of values.
+// Each completion is the entire list, such that it begins with toComplete.
+// Values already in the list are not offered again.
+func CompleteList(toComplete string, values []string) []string {
+	i := strings.LastIndex(toComplete, ",") + 1
+	prefix, given := toComplete[:i], strings.Split(toComplete[:i], ",")
+	var c []string
+	for _, v := range values {
+		if !slices.Contains(given, v) {
+			c = append(c, prefix+v)
+		}
+	}
+	return c
+}
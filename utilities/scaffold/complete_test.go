@@ -0,0 +1,26 @@
+package scaffold
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompleteList(t *testing.T) {
+	values := []string{"ID", "Name", "Description"}
+	tests := []struct {
+		toComplete string
+		want       []string
+	}{
+		{"", []string{"ID", "Name", "Description"}},
+		{"Na", []string{"ID", "Name", "Description"}},
+		{"ID,", []string{"ID,Name", "ID,Description"}},
+		{"ID,Name,De", []string{"ID,Name,Description"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.toComplete, func(t *testing.T) {
+			if got := CompleteList(tt.toComplete, values); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CompleteList() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
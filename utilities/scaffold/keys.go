@@ -0,0 +1,39 @@
+package scaffold
+
+import (
+	"gwcli/utilities/keymap"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// keymap scopes of the scaffolds' bindings (see keymap.Scope).
+// Create and edit share the form bindings; each scaffold's other bindings are only active in it.
+var (
+	scaffoldScope = keymap.NewScope("scaffold", keymap.Global)
+	formScope     = keymap.NewScope("form", scaffoldScope)
+	editScope     = keymap.NewScope("edit", scaffoldScope)
+	deleteScope   = keymap.NewScope("delete", scaffoldScope)
+)
+
+// Keys are the bindings used by the interactive scaffolds.
+var Keys = struct {
+	Submit        key.Binding // submit the form
+	PreviousField key.Binding
+	NextField     key.Binding
+
+	Select  key.Binding // select the item to edit
+	Confirm key.Binding // delete the item under the cursor
+}{
+	Submit: keymap.Bind(formScope, "submit", "submit",
+		"alt+enter"),
+	PreviousField: keymap.Bind(formScope, "previous-field", "previous field",
+		tea.KeyUp.String(), tea.KeyShiftTab.String()),
+	NextField: keymap.Bind(formScope, "next-field", "next field",
+		tea.KeyDown.String()),
+
+	Select: keymap.Bind(editScope, "select", "edit item",
+		" ", tea.KeyEnter.String()),
+	Confirm: keymap.Bind(deleteScope, "confirm", "delete item",
+		tea.KeyEnter.String()),
+}
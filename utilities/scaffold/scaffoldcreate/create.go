@@ -60,12 +60,15 @@ import (
 	"gwcli/mother"
 	"gwcli/stylesheet"
 	"gwcli/stylesheet/colorizer"
+	"gwcli/utilities/keymap"
+	"gwcli/utilities/scaffold"
 	"gwcli/utilities/treeutils"
 	"gwcli/utilities/uniques"
 	"slices"
 	"strings"
 	"sync"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -279,36 +282,34 @@ func (c *createModel) Update(msg tea.Msg) tea.Cmd {
 	}
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
 		c.inputErr = "" // clear last input error
-		switch keyMsg.Type {
-		case tea.KeyUp, tea.KeyShiftTab:
+		switch {
+		case key.Matches(keyMsg, scaffold.Keys.PreviousField):
 			c.focusPrevious()
 			return textinput.Blink
-		case tea.KeyDown:
+		case key.Matches(keyMsg, scaffold.Keys.NextField):
 			c.focusNext()
 			return textinput.Blink
-		case tea.KeyEnter:
-			if keyMsg.Alt { // only submit on alt+enter
-				c.createErr = "" // clear last error
-				// extract values from TIs
-				values, mr := c.extractValuesFromTIs()
-				if mr != nil {
-					c.inputErr = fmt.Sprintf("%v are required", mr)
-					return nil
-				}
-				id, invalid, err := c.cf(c.fields, values, &c.fs)
-				if err != nil {
-					c.createErr = err.Error()
-					return nil
-				} else if invalid != "" {
-					c.inputErr = invalid
-					return nil
-				}
-				// done, die
-				c.mode = quitting
-				return tea.Println(fmt.Sprintf(createdSuccessfully, c.singular, id))
-			} else {
-				c.focusNext()
+		case key.Matches(keyMsg, scaffold.Keys.Submit):
+			c.createErr = "" // clear last error
+			// extract values from TIs
+			values, mr := c.extractValuesFromTIs()
+			if mr != nil {
+				c.inputErr = fmt.Sprintf("%v are required", mr)
+				return nil
 			}
+			id, invalid, err := c.cf(c.fields, values, &c.fs)
+			if err != nil {
+				c.createErr = err.Error()
+				return nil
+			} else if invalid != "" {
+				c.inputErr = invalid
+				return nil
+			}
+			// done, die
+			c.mode = quitting
+			return tea.Println(fmt.Sprintf(createdSuccessfully, c.singular, id))
+		case keyMsg.Type == tea.KeyEnter:
+			c.focusNext()
 		}
 	} else if sizeMsg, ok := msg.(tea.WindowSizeMsg); ok {
 		c.width = sizeMsg.Width
@@ -401,7 +402,7 @@ func (c *createModel) View() string {
 	// conjoin fields and TIs
 	composed := lipgloss.JoinHorizontal(lipgloss.Center, f, t)
 
-	return composed + "\n" + colorizer.SubmitString("alt+enter", c.inputErr, c.createErr, c.width)
+	return composed + "\n" + colorizer.SubmitString(keymap.Keys(scaffold.Keys.Submit), c.inputErr, c.createErr, c.width)
 }
 
 func (c *createModel) Done() bool {
@@ -48,6 +48,7 @@ import (
 	"gwcli/utilities/scaffold"
 	"gwcli/utilities/treeutils"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/gravwell/gravwell/v3/client"
@@ -136,6 +137,12 @@ func NewDeleteAction[I scaffold.Id_t](
 		})
 	fs := flags()
 	cmd.Flags().AddFlagSet(&fs)
+	// complete --id from the delete-able items
+	if err := cmd.RegisterFlagCompletionFunc(ft.Name.ID, scaffold.IDCompletion(singular, fch,
+		func(itm Item[I]) string { return fmt.Sprint(itm.id) },
+		func(itm Item[I]) string { return itm.title })); err != nil {
+		panic(err) // developer error
+	}
 	d := newDeleteModel(del, fch)
 	d.itemSingular = singular
 	d.itemPlural = plural
@@ -215,7 +222,7 @@ func (d *deleteModel[I]) Update(msg tea.Msg) tea.Cmd {
 		d.list.SetSize(msg.Width, msg.Height)
 		return nil
 	case tea.KeyMsg:
-		if msg.Type == tea.KeyEnter {
+		if key.Matches(msg, scaffold.Keys.Confirm) {
 			var (
 				baseitm list.Item // item stored in the list
 				itm     Item[I]   // baseitm cast to our expanded item type
@@ -50,6 +50,7 @@ import (
 	"gwcli/stylesheet"
 	"gwcli/stylesheet/colorizer"
 	ft "gwcli/stylesheet/flagtext"
+	"gwcli/utilities/keymap"
 	"gwcli/utilities/listsupport"
 	"gwcli/utilities/scaffold"
 	"gwcli/utilities/treeutils"
@@ -57,6 +58,7 @@ import (
 	"slices"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -83,9 +85,9 @@ type id_t interface {
 
 var (
 	// TI field marked as required
-	tiFieldRequiredSty = stylesheet.Header1Style
+	tiFieldRequiredSty = func() lipgloss.Style { return stylesheet.Header1Style }
 	// TI field marked as optional
-	tiFieldOptionalSty = stylesheet.Header2Style
+	tiFieldOptionalSty = func() lipgloss.Style { return stylesheet.Header2Style }
 )
 
 // #endregion
@@ -128,6 +130,14 @@ func NewEditAction[I id_t, S any](singular, plural string, cfg Config, funcs Sub
 
 	// attach flags to cmd
 	cmd.Flags().AddFlagSet(&fs)
+	if funcs.GetIDSub != nil {
+		if err := cmd.RegisterFlagCompletionFunc(ft.Name.ID, scaffold.IDCompletion(singular,
+			funcs.FetchSub,
+			func(itm S) string { return fmt.Sprint(funcs.GetIDSub(itm)) },
+			funcs.GetTitleSub)); err != nil {
+			panic(err)
+		}
+	}
 
 	return treeutils.GenerateAction(cmd,
 		newEditModel(cfg, singular, plural, funcs, fs),
@@ -396,7 +406,7 @@ func (em *editModel[I, S]) Update(msg tea.Msg) tea.Cmd {
 func (em *editModel[I, S]) updateSelecting(msg tea.Msg) tea.Cmd {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		if msg.Type == tea.KeySpace || msg.Type == tea.KeyEnter {
+		if key.Matches(msg, scaffold.Keys.Select) {
 			em.selectedData = em.data[em.list.Index()]
 			if err := em.enterEditMode(); err != nil {
 				em.mode = quitting
@@ -416,55 +426,51 @@ func (em *editModel[I, S]) updateSelecting(msg tea.Msg) tea.Cmd {
 func (em *editModel[I, S]) updateEditting(msg tea.Msg) tea.Cmd {
 	if keymsg, ok := msg.(tea.KeyMsg); ok {
 		em.inputErr = "" // clear input errors on new key input
-		switch keymsg.Type {
-		case tea.KeyEnter:
-			if keymsg.Alt { // check for a submission via alt+enter
-				em.updateErr = "" // clear existing updateErr
-
-				var missing []string
-				for _, kti := range em.orderedKTIs { // check all required fields are populated
-					if em.cfg[kti.key].Required && strings.TrimSpace(kti.ti.Value()) == "" {
-						missing = append(missing, kti.key)
-					}
-				}
-
-				// if fields are missing, warn and do not submit
-				if len(missing) > 0 {
-					imploded := strings.Join(missing, ", ")
-					copula := "is"
-					if len(missing) > 1 {
-						copula = "are"
-					}
-					em.inputErr = fmt.Sprintf("%v %v required", imploded, copula)
-					return textinput.Blink
+		switch {
+		case key.Matches(keymsg, scaffold.Keys.Submit):
+			em.updateErr = "" // clear existing updateErr
+
+			var missing []string
+			for _, kti := range em.orderedKTIs { // check all required fields are populated
+				if em.cfg[kti.key].Required && strings.TrimSpace(kti.ti.Value()) == "" {
+					missing = append(missing, kti.key)
 				}
+			}
 
-				// yank the TI values and reinstall them into a data structure to update against
-				for _, kti := range em.orderedKTIs {
-					if inv, err := em.funcs.SetFieldSub(&em.selectedData, kti.key, kti.ti.Value()); err != nil {
-						em.mode = quitting
-						return tea.Println(err, "\n", "no changes made")
-					} else if inv != "" {
-						em.inputErr = inv
-						return textinput.Blink
-					}
+			// if fields are missing, warn and do not submit
+			if len(missing) > 0 {
+				imploded := strings.Join(missing, ", ")
+				copula := "is"
+				if len(missing) > 1 {
+					copula = "are"
 				}
+				em.inputErr = fmt.Sprintf("%v %v required", imploded, copula)
+				return textinput.Blink
+			}
 
-				// perform the update
-				identifier, err := em.funcs.UpdateSub(&em.selectedData)
-				if err != nil {
-					em.updateErr = err.Error()
+			// yank the TI values and reinstall them into a data structure to update against
+			for _, kti := range em.orderedKTIs {
+				if inv, err := em.funcs.SetFieldSub(&em.selectedData, kti.key, kti.ti.Value()); err != nil {
+					em.mode = quitting
+					return tea.Println(err, "\n", "no changes made")
+				} else if inv != "" {
+					em.inputErr = inv
 					return textinput.Blink
 				}
-				// success
-				em.mode = quitting
-				return tea.Printf(successStringF, em.singular, identifier)
-			} else {
-				em.nextTI()
 			}
-		case tea.KeyUp:
+
+			// perform the update
+			identifier, err := em.funcs.UpdateSub(&em.selectedData)
+			if err != nil {
+				em.updateErr = err.Error()
+				return textinput.Blink
+			}
+			// success
+			em.mode = quitting
+			return tea.Printf(successStringF, em.singular, identifier)
+		case key.Matches(keymsg, scaffold.Keys.PreviousField):
 			em.previousTI()
-		case tea.KeyDown:
+		case key.Matches(keymsg, scaffold.Keys.NextField), keymsg.Type == tea.KeyEnter:
 			em.nextTI()
 		}
 	}
@@ -515,13 +521,13 @@ func (em *editModel[I, S]) View() string {
 		for _, kti := range em.orderedKTIs {
 			// color the title appropriately
 			if em.cfg[kti.key].Required {
-				sb.WriteString(tiFieldRequiredSty.Render(kti.key + ": "))
+				sb.WriteString(tiFieldRequiredSty().Render(kti.key + ": "))
 			} else {
-				sb.WriteString(tiFieldOptionalSty.Render(kti.key + ": "))
+				sb.WriteString(tiFieldOptionalSty().Render(kti.key + ": "))
 			}
 			sb.WriteString(kti.ti.View() + "\n")
 		}
-		sb.WriteString(colorizer.SubmitString("alt+enter", em.inputErr, em.updateErr, em.width))
+		sb.WriteString(colorizer.SubmitString(keymap.Keys(scaffold.Keys.Submit), em.inputErr, em.updateErr, em.width))
 		str = sb.String()
 	}
 	return str
@@ -35,6 +35,10 @@ type SetFieldSubroutine[S any] func(item *S, fieldKey, val string) (
 	invalid string, err error,
 )
 
+// Subroutine to fetch the id of the item, as would be given to --id.
+// Optional; if given, --id completes to the ids of the items returned by FetchAllSubroutine.
+type GetIDSubroutine[I id_t, S any] func(item S) I
+
 // Performs the actual update of the data on the GW instance
 type UpdateStructSubroutine[S any] func(data *S) (
 	identifier string, err error,
@@ -54,6 +58,7 @@ type SubroutineSet[I id_t, S any] struct {
 	// special function to retrieve a description for the list entry
 	GetDescriptionSub GetDescriptionSubroutine[S]
 	UpdateSub         UpdateStructSubroutine[S] // submit the struct as updated
+	GetIDSub          GetIDSubroutine[I, S]     // optional; enables completion of --id
 }
 
 // Validates that all functions were set.
@@ -55,6 +55,7 @@ import (
 	"gwcli/connection"
 	"gwcli/stylesheet"
 	ft "gwcli/stylesheet/flagtext"
+	"gwcli/utilities/scaffold"
 	"gwcli/utilities/treeutils"
 	"os"
 	"reflect"
@@ -156,12 +157,6 @@ func NewListAction[Any any](short, long string, defaultColumns []string,
 			columns = defaultColumns
 		}
 
-		// check for --no-color
-		noColor, err := cmd.Flags().GetBool("no-color")
-		if err != nil {
-			clilog.LogFlagFailedGet("no-color", err)
-		}
-
 		// check for output file
 		outFile, err := initOutFile(cmd.Flags())
 		if err != nil {
@@ -171,7 +166,7 @@ func NewListAction[Any any](short, long string, defaultColumns []string,
 			defer outFile.Close()
 		}
 
-		s, err := listOutput(cmd.Flags(), columns, !noColor, dataFn)
+		s, err := listOutput(cmd.Flags(), columns, !stylesheet.NoColor(), dataFn)
 		if err != nil {
 			clilog.Tee(clilog.ERROR, cmd.ErrOrStderr(), err.Error())
 			return
@@ -208,6 +203,23 @@ func NewListAction[Any any](short, long string, defaultColumns []string,
 	cmd.Flags().SortFlags = false // does not seem to be respected
 	cmd.MarkFlagsMutuallyExclusive("csv", "json", "table")
 
+	// completions, for Mother's prompt and shells
+	if err := cmd.RegisterFlagCompletionFunc("columns",
+		func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			cols, err := weave.StructFields(dataStruct, true)
+			if err != nil {
+				clilog.Writer.Warnf("failed to complete columns: %v", err)
+				return nil, cobra.ShellCompDirectiveError
+			}
+			return scaffold.CompleteList(toComplete, cols),
+				cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+		}); err != nil {
+		panic(err) // developer error
+	}
+	if err := cmd.MarkFlagFilename(ft.Name.Output); err != nil {
+		panic(err)
+	}
+
 	// spin up a list action for interactive use
 	la := newListAction(defaultColumns, dataStruct, dataFn, addtlFlagsFunc)
 
@@ -320,7 +332,7 @@ type ListAction[Any any] struct {
 	DefaultFormat  outputFormat
 	DefaultColumns []string          // columns to output if unspecified
 	afsFunc        addtlFlagFunction // the additional flagset to add to the starter when restoring
-	color          bool              // inferred from the global "--no-color" flag
+	color          bool              // inferred from the global "--no-color" flag (or NO_COLOR)
 
 	// individualized for each user of list_generic
 	dataStruct Any
@@ -447,12 +459,7 @@ func (la *ListAction[T]) SetArgs(
 		} // else: defaults to DefaultColumns
 	}
 
-	nc, err := inherited.GetBool("no-color")
-	if err != nil {
-		la.color = false
-		clilog.Writer.Warnf("Failed to fetch no-color from inherited: %v", err)
-	}
-	la.color = !nc
+	la.color = !stylesheet.NoColor()
 
 	if f, err := initOutFile(&fs); err != nil {
 		return "", nil, err